@@ -0,0 +1,289 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/requests"
+)
+
+// admin.go walks every page of the ingest and query jobs of an org and cleans up those which were
+// abandoned. Jobs left Open or UploadComplete count against the limits of the org until they are
+// aborted, and finished jobs keep their data and results until they are deleted.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/get_all_jobs.htm
+
+const (
+	// DefaultAbortAfter is the age at which Cleanup aborts jobs which are still Open or
+	// UploadComplete
+	DefaultAbortAfter = 24 * time.Hour
+	// DefaultRetention is the age at which Cleanup deletes finished jobs
+	DefaultRetention = 7 * 24 * time.Hour
+)
+
+// JobFilter selects jobs by their properties. The zero value matches every job.
+type JobFilter struct {
+	// JobType, if not empty, is given to Salesforce to list only jobs of this type
+	JobType jobType
+	// States, if not empty, are the states of the jobs matched, such as "Open" or "JobComplete"
+	States []string
+	// Object, if not empty, is the object of the jobs matched
+	Object string
+	// CreatedByID, if not empty, is the ID of the user who created the jobs matched
+	CreatedByID string
+	// OlderThan, if positive, matches jobs created more than this long ago
+	OlderThan time.Duration
+}
+
+// Match reports whether job is selected by f at the time now
+func (f *JobFilter) Match(job *JobInfo, now time.Time) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Object != "" && f.Object != job.Object {
+		return false
+	}
+
+	if f.CreatedByID != "" && f.CreatedByID != job.CreatedByID {
+		return false
+	}
+
+	if f.OlderThan > 0 && (job.CreatedDate.IsNull || now.Sub(job.CreatedDate.Value) <= f.OlderThan) {
+		return false
+	}
+
+	if len(f.States) == 0 {
+		return true
+	}
+
+	for _, state := range f.States {
+		if job.State == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jobsPage returns a page of jobs, such as GetJobs or GetQueries
+type jobsPage func(builder requests.Builder, isPkChunkingEnabled bool, jobType jobType, queryLocator string) (*GetJobsResponse, error)
+
+// EachJob calls fn with every ingest job matched by filter, requesting each page of jobs in turn
+// until fn returns an error:
+//
+//	err := bulk.EachJob(requests.Sender(salesforce.DefaultClient), &bulk.JobFilter{States: []string{"Open"}}, func(job *bulk.JobInfo) error {
+//		fmt.Println(job.ID, job.Object)
+//		return nil
+//	})
+func EachJob(builder requests.Builder, filter *JobFilter, fn func(job *JobInfo) error) error {
+	return eachJob(builder, GetJobs, filter, fn)
+}
+
+// EachQuery is EachJob for query jobs
+func EachQuery(builder requests.Builder, filter *JobFilter, fn func(job *JobInfo) error) error {
+	return eachJob(builder, GetQueries, filter, fn)
+}
+
+// ListJobs returns every ingest job matched by filter
+func ListJobs(builder requests.Builder, filter *JobFilter) ([]*JobInfo, error) {
+	return listJobs(builder, GetJobs, filter)
+}
+
+// ListQueries returns every query job matched by filter
+func ListQueries(builder requests.Builder, filter *JobFilter) ([]*JobInfo, error) {
+	return listJobs(builder, GetQueries, filter)
+}
+
+func listJobs(builder requests.Builder, get jobsPage, filter *JobFilter) (jobs []*JobInfo, err error) {
+	return jobs, eachJob(builder, get, filter, func(job *JobInfo) error {
+		jobs = append(jobs, job)
+		return nil
+	})
+}
+
+// eachJob follows the query locator of each page returned by get until the last page
+func eachJob(builder requests.Builder, get jobsPage, filter *JobFilter, fn func(job *JobInfo) error) error {
+	var jt jobType
+	if filter != nil {
+		jt = filter.JobType
+	}
+
+	now := time.Now()
+	var locator string
+	for {
+		page, err := get(builder, false, jt, locator)
+		if err != nil {
+			return fmt.Errorf("listing jobs: %w", err)
+		}
+
+		for _, job := range page.Records {
+			if !filter.Match(job, now) {
+				continue
+			}
+
+			if err := fn(job); err != nil {
+				return err
+			}
+		}
+
+		next := queryLocator(page.NextRecordsURL)
+		if page.Done || next == "" || next == locator {
+			return nil
+		}
+		locator = next
+	}
+}
+
+// queryLocator returns the queryLocator parameter of the nextRecordsUrl of a page of jobs
+func queryLocator(nextRecordsURL string) string {
+	u, err := url.Parse(nextRecordsURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("queryLocator")
+}
+
+// CleanupOptions configures CleanupJobs and CleanupQueries. The zero value aborts every job left
+// Open or UploadComplete for DefaultAbortAfter and deletes every finished job older than
+// DefaultRetention.
+type CleanupOptions struct {
+	// Object, if not empty, limits the cleanup to the jobs of this object
+	Object string
+	// CreatedByID, if not empty, limits the cleanup to the jobs created by this user, such as the
+	// integration user running it
+	CreatedByID string
+	// AbortAfter is the age at which a job which is still Open or UploadComplete is aborted
+	AbortAfter time.Duration
+	// Retention is the age at which a JobComplete, Failed or Aborted job is deleted
+	Retention time.Duration
+	// DryRun reports the jobs which would be aborted or deleted without changing them
+	DryRun bool
+}
+
+// withDefaults returns a copy of options with unset values defaulted
+func (o *CleanupOptions) withDefaults() CleanupOptions {
+	var options CleanupOptions
+	if o != nil {
+		options = *o
+	}
+
+	if options.AbortAfter <= 0 {
+		options.AbortAfter = DefaultAbortAfter
+	}
+
+	if options.Retention <= 0 {
+		options.Retention = DefaultRetention
+	}
+
+	return options
+}
+
+// CleanupResult lists the jobs acted upon by a cleanup
+type CleanupResult struct {
+	// Aborted are the jobs which were aborted, or would have been by a dry run
+	Aborted []*JobInfo
+	// Deleted are the jobs which were deleted, or would have been by a dry run
+	Deleted []*JobInfo
+	// Failures are the errors of the jobs which could not be aborted or deleted by their IDs
+	Failures map[string]error
+}
+
+// cleanupAPI are the requests which act upon ingest or query jobs
+type cleanupAPI struct {
+	list   jobsPage
+	abort  func(builder requests.Builder, jobID string) error
+	delete func(builder requests.Builder, jobID string) error
+}
+
+var (
+	ingestCleanup = cleanupAPI{
+		list: GetJobs,
+		abort: func(builder requests.Builder, jobID string) error {
+			_, err := UpdateJob(builder, jobID, &UpdateJobRequest{State: JobStateAborted})
+			return err
+		},
+		delete: DeleteJob,
+	}
+
+	queryCleanup = cleanupAPI{
+		list: GetQueries,
+		abort: func(builder requests.Builder, jobID string) error {
+			_, err := UpdateQuery(builder, jobID, &UpdateJobRequest{State: JobStateAborted})
+			return err
+		},
+		delete: DeleteQuery,
+	}
+)
+
+// CleanupJobs aborts the ingest jobs stuck in Open or UploadComplete and deletes the finished
+// ingest jobs past their retention:
+//
+//	result, err := bulk.CleanupJobs(ctx, requests.Sender(salesforce.DefaultClient), &bulk.CleanupOptions{
+//		Retention: 48 * time.Hour,
+//		DryRun:    true,
+//	})
+//
+// A job which cannot be aborted or deleted is recorded in CleanupResult.Failures and the cleanup
+// continues. Bulk API 1.0 jobs are listed by Salesforce but are left alone, as they can only be
+// closed through Bulk API 1.0.
+func CleanupJobs(ctx context.Context, builder requests.Builder, options *CleanupOptions) (*CleanupResult, error) {
+	return cleanup(ctx, builder, ingestCleanup, options)
+}
+
+// CleanupQueries is CleanupJobs for query jobs
+func CleanupQueries(ctx context.Context, builder requests.Builder, options *CleanupOptions) (*CleanupResult, error) {
+	return cleanup(ctx, builder, queryCleanup, options)
+}
+
+func cleanup(ctx context.Context, builder requests.Builder, api cleanupAPI, options *CleanupOptions) (*CleanupResult, error) {
+	opts := options.withDefaults()
+	builder = builder.Context(ctx)
+	filter := &JobFilter{Object: opts.Object, CreatedByID: opts.CreatedByID}
+
+	// jobs are collected before acting upon them so that deletions do not shift the pages
+	jobs, err := listJobs(builder, api.list, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CleanupResult{Failures: make(map[string]error)}
+	now := time.Now()
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if jobType(job.JobType) == JobTypeClassic || job.CreatedDate.IsNull {
+			continue
+		}
+
+		age := now.Sub(job.CreatedDate.Value)
+		switch jobState(job.State) {
+		case JobStateOpen, JobStateUploadComplete:
+			if age > opts.AbortAfter {
+				result.act(builder, job, api.abort, &result.Aborted, opts.DryRun)
+			}
+		case JobStateComplete, JobStateFailed, JobStateAborted:
+			if age > opts.Retention {
+				result.act(builder, job, api.delete, &result.Deleted, opts.DryRun)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// act calls fn for job unless dryRun is true, recording job in acted if it succeeds
+func (r *CleanupResult) act(builder requests.Builder, job *JobInfo, fn func(requests.Builder, string) error, acted *[]*JobInfo, dryRun bool) {
+	if !dryRun {
+		if err := fn(builder, job.ID); err != nil {
+			r.Failures[job.ID] = err
+			return
+		}
+	}
+
+	*acted = append(*acted, job)
+}
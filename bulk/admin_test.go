@@ -0,0 +1,99 @@
+package bulk_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/stretchr/testify/require"
+)
+
+// createJobs creates an ingest job upon each object, leaving it in the given state
+func createJobs(t *testing.T, server *fakeBulk, state bulk.UpdateJobRequest, objects ...string) []string {
+	var ids []string
+	for _, object := range objects {
+		job, err := bulk.CreateJob(server.builder(), &bulk.CreateJobRequest{Object: object, Operation: bulk.OperationInsert})
+		require.Nil(t, err)
+
+		if state.State != "" {
+			_, err = bulk.UploadJob(server.builder(), job.ID, strings.NewReader("Name\nAcme\n"))
+			require.Nil(t, err)
+			_, err = bulk.UpdateJob(server.builder(), job.ID, &state)
+			require.Nil(t, err)
+		}
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+func TestListJobs(t *testing.T) {
+	server := newFakeBulk(t)
+	server.listSize = 2
+	open := createJobs(t, server, bulk.UpdateJobRequest{}, "Account", "Contact", "Account")
+	closed := createJobs(t, server, bulk.UpdateJobRequest{State: bulk.JobStateUploadComplete}, "Account", "Lead")
+	server.age(open[2], 48*time.Hour)
+
+	jobs, err := bulk.ListJobs(server.builder(), nil)
+	require.Nil(t, err)
+	require.Len(t, jobs, 5, "every page is listed")
+	require.Equal(t, open[0], jobs[0].ID)
+	require.Equal(t, closed[1], jobs[4].ID)
+
+	jobs, err = bulk.ListJobs(server.builder(), &bulk.JobFilter{States: []string{"Open"}, Object: "Account"})
+	require.Nil(t, err)
+	require.Len(t, jobs, 2)
+
+	jobs, err = bulk.ListJobs(server.builder(), &bulk.JobFilter{OlderThan: 24 * time.Hour, CreatedByID: "005000000000001AAA"})
+	require.Nil(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, open[2], jobs[0].ID)
+
+	jobs, err = bulk.ListJobs(server.builder(), &bulk.JobFilter{CreatedByID: "005000000000002AAA"})
+	require.Nil(t, err)
+	require.Empty(t, jobs)
+
+	queries, err := bulk.ListQueries(server.builder(), nil)
+	require.Nil(t, err)
+	require.Empty(t, queries)
+}
+
+func TestCleanupJobs(t *testing.T) {
+	server := newFakeBulk(t)
+	server.listSize = 1
+	stale := createJobs(t, server, bulk.UpdateJobRequest{}, "Account", "Contact")
+	fresh := createJobs(t, server, bulk.UpdateJobRequest{}, "Account")
+	done := createJobs(t, server, bulk.UpdateJobRequest{State: bulk.JobStateUploadComplete}, "Account", "Account")
+	for _, id := range append(stale, done[0]) {
+		server.age(id, 72*time.Hour)
+	}
+
+	// UploadComplete jobs complete once polled
+	for _, id := range done {
+		_, err := bulk.WaitForJob(context.Background(), server.builder(), id, &bulk.IngestOptions{PollInterval: time.Millisecond})
+		require.Nil(t, err)
+	}
+
+	options := &bulk.CleanupOptions{Object: "Account", AbortAfter: time.Hour, Retention: 48 * time.Hour, DryRun: true}
+	result, err := bulk.CleanupJobs(context.Background(), server.builder(), options)
+	require.Nil(t, err)
+	require.Len(t, result.Aborted, 1)
+	require.Equal(t, stale[0], result.Aborted[0].ID)
+	require.Len(t, result.Deleted, 1)
+	require.Equal(t, done[0], result.Deleted[0].ID)
+	require.Equal(t, "Open", server.job(stale[0]).info.State, "a dry run changes nothing")
+	require.NotNil(t, server.job(done[0]))
+
+	options.DryRun = false
+	result, err = bulk.CleanupJobs(context.Background(), server.builder(), options)
+	require.Nil(t, err)
+	require.Empty(t, result.Failures)
+	require.Len(t, result.Aborted, 1)
+	require.Len(t, result.Deleted, 1)
+	require.Equal(t, "Aborted", server.job(stale[0]).info.State)
+	require.Equal(t, "Open", server.job(stale[1]).info.State, "other objects are left alone")
+	require.Equal(t, "Open", server.job(fresh[0]).info.State)
+	require.Nil(t, server.job(done[0]))
+	require.NotNil(t, server.job(done[1]))
+}
@@ -0,0 +1,282 @@
+package bulk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpoint.go records the progress of Ingest and Export so that a process which crashes can
+// resume polling or downloading the jobs it created rather than submitting them again, which for
+// an ingest would duplicate records.
+
+// ErrCheckpointMismatch is returned when a checkpoint was written for a different object,
+// operation, or input data than that being resumed
+var ErrCheckpointMismatch = errors.New("bulk checkpoint does not match the job being resumed")
+
+// Checkpoint is the progress of an Ingest or Export
+type Checkpoint struct {
+	// Object of an ingest
+	Object string `json:"object,omitempty"`
+	// Operation of the jobs
+	Operation string `json:"operation"`
+	// Jobs are the jobs created so far
+	Jobs []CheckpointJob `json:"jobs"`
+	// Locator of the next page of the results of an export, which is empty once every page has
+	// been written
+	Locator string `json:"locator,omitempty"`
+	// Pages of the results of an export written so far
+	Pages int `json:"pages,omitempty"`
+	// NumberOfRecords of an export written so far
+	NumberOfRecords int `json:"numberOfRecords,omitempty"`
+}
+
+// CheckpointJob is a job created by an Ingest or Export
+type CheckpointJob struct {
+	// Split is the index of the split of the input uploaded by an ingest job
+	Split int `json:"split"`
+	// ID of the job
+	ID string `json:"id"`
+	// State of the job when it was last seen
+	State string `json:"state"`
+	// Digest is the SHA-256 of the data uploaded by an ingest job
+	Digest string `json:"digest,omitempty"`
+	// Attempt is the retry of failed rows which created an ingest job, or 0 for the jobs of the
+	// input
+	Attempt int `json:"attempt,omitempty"`
+}
+
+// CheckpointStore persists checkpoints by key
+type CheckpointStore interface {
+	// Load returns the checkpoint for key, or nil if there is none
+	Load(key string) (*Checkpoint, error)
+	// Save replaces the checkpoint for key
+	Save(key string, checkpoint *Checkpoint) error
+	// Delete removes the checkpoint for key, if any
+	Delete(key string) error
+}
+
+// FileCheckpointStore stores each checkpoint as a JSON file within Dir, or within os.TempDir()
+// if Dir is empty
+type FileCheckpointStore struct {
+	Dir string
+}
+
+// path returns the file of the checkpoint for key
+func (s *FileCheckpointStore) path(key string) string {
+	dir := s.Dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, url.PathEscape(key)+".json")
+}
+
+// Load ...
+func (s *FileCheckpointStore) Load(key string) (*Checkpoint, error) {
+	contents, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(contents, &checkpoint); err != nil {
+		return nil, fmt.Errorf("reading checkpoint %s: %w", key, err)
+	}
+
+	return &checkpoint, nil
+}
+
+// Save writes the checkpoint to a temporary file before renaming it, so that a crash never
+// leaves a partially written checkpoint
+func (s *FileCheckpointStore) Save(key string, checkpoint *Checkpoint) error {
+	contents, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(contents); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// Delete ...
+func (s *FileCheckpointStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// checkpointer guards the checkpoint of a single Ingest or Export. A nil checkpointer records
+// nothing, so that checkpoints are optional.
+type checkpointer struct {
+	mu         *sync.Mutex
+	store      CheckpointStore
+	key        string
+	checkpoint *Checkpoint
+	// attempt is the retry whose jobs are recorded, see CheckpointJob.Attempt
+	attempt int
+}
+
+// loadCheckpoint returns the checkpointer for key, or nil if key is empty. The checkpoint is
+// created if store has none for key, and otherwise must match object and operation.
+func loadCheckpoint(store CheckpointStore, key string, object string, operation string) (*checkpointer, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	if store == nil {
+		store = &FileCheckpointStore{}
+	}
+
+	checkpoint, err := store.Load(key)
+	if err != nil {
+		return nil, fmt.Errorf("loading checkpoint %s: %w", key, err)
+	}
+
+	if checkpoint == nil {
+		checkpoint = &Checkpoint{Object: object, Operation: operation}
+	}
+
+	if checkpoint.Object != object || checkpoint.Operation != operation {
+		return nil, fmt.Errorf("checkpoint %s is for %s %s: %w", key, checkpoint.Operation, checkpoint.Object, ErrCheckpointMismatch)
+	}
+
+	return &checkpointer{mu: &sync.Mutex{}, store: store, key: key, checkpoint: checkpoint}, nil
+}
+
+// retry returns a checkpointer recording the jobs of the given retry in the same checkpoint
+func (c *checkpointer) retry(attempt int) *checkpointer {
+	if c == nil {
+		return nil
+	}
+
+	return &checkpointer{mu: c.mu, store: c.store, key: c.key, checkpoint: c.checkpoint, attempt: attempt}
+}
+
+// job returns the job recorded for split, if any
+func (c *checkpointer) job(split int) (CheckpointJob, bool) {
+	if c == nil {
+		return CheckpointJob{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, job := range c.checkpoint.Jobs {
+		if job.Split == split && job.Attempt == c.attempt {
+			return job, true
+		}
+	}
+
+	return CheckpointJob{}, false
+}
+
+// started reports whether any job was recorded for the attempt of c
+func (c *checkpointer) started() bool {
+	_, ok := c.job(0)
+	return ok
+}
+
+// snapshot returns a copy of the checkpoint, or the zero value for a nil checkpointer
+func (c *checkpointer) snapshot() Checkpoint {
+	if c == nil {
+		return Checkpoint{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return *c.checkpoint
+}
+
+// update applies fn to the checkpoint and saves it
+func (c *checkpointer) update(fn func(checkpoint *Checkpoint)) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(c.checkpoint)
+	if err := c.store.Save(c.key, c.checkpoint); err != nil {
+		return fmt.Errorf("saving checkpoint %s: %w", c.key, err)
+	}
+
+	return nil
+}
+
+// setJob records the job of split, replacing any earlier job
+func (c *checkpointer) setJob(job CheckpointJob) error {
+	if c == nil {
+		return nil
+	}
+
+	job.Attempt = c.attempt
+	return c.update(func(checkpoint *Checkpoint) {
+		for i := range checkpoint.Jobs {
+			if checkpoint.Jobs[i].Split == job.Split && checkpoint.Jobs[i].Attempt == job.Attempt {
+				checkpoint.Jobs[i] = job
+				return
+			}
+		}
+		checkpoint.Jobs = append(checkpoint.Jobs, job)
+	})
+}
+
+// setState records the state of the job of split
+func (c *checkpointer) setState(split int, state string) error {
+	return c.update(func(checkpoint *Checkpoint) {
+		for i := range checkpoint.Jobs {
+			if checkpoint.Jobs[i].Split == split && checkpoint.Jobs[i].Attempt == c.attempt {
+				checkpoint.Jobs[i].State = state
+			}
+		}
+	})
+}
+
+// remove deletes the checkpoint once the work it records is complete
+func (c *checkpointer) remove() error {
+	if c == nil {
+		return nil
+	}
+
+	if err := c.store.Delete(c.key); err != nil {
+		return fmt.Errorf("deleting checkpoint %s: %w", c.key, err)
+	}
+
+	return nil
+}
+
+// digest identifies the data of a split
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,200 @@
+package bulk_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+// checkpointJob creates a job holding accountsCSV as an earlier ingest would have, closing it if
+// closed is true, and returns its checkpoint
+func checkpointJob(t *testing.T, server *fakeBulk, closed bool) *bulk.Checkpoint {
+	builder := server.builder()
+	job, err := bulk.CreateJob(builder, &bulk.CreateJobRequest{
+		ColumnDelimiter: bulk.DelimiterPipe,
+		ContentType:     bulk.ContentTypeCSV,
+		LineEnding:      bulk.LineEndingLF,
+		Object:          "Account",
+		Operation:       bulk.OperationInsert,
+	})
+	require.Nil(t, err)
+
+	_, err = bulk.UploadJob(builder, job.ID, strings.NewReader(accountsCSV))
+	require.Nil(t, err)
+
+	if closed {
+		_, err = bulk.UpdateJob(builder, job.ID, &bulk.UpdateJobRequest{State: bulk.JobStateUploadComplete})
+		require.Nil(t, err)
+	}
+
+	sum := sha256.Sum256([]byte(accountsCSV))
+	return &bulk.Checkpoint{
+		Object:    "Account",
+		Operation: string(bulk.OperationInsert),
+		Jobs:      []bulk.CheckpointJob{{Split: 0, ID: job.ID, State: "UploadComplete", Digest: hex.EncodeToString(sum[:])}},
+	}
+}
+
+func resumeIngest(server *fakeBulk, store bulk.CheckpointStore) (*bulk.IngestResult, error) {
+	return bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader(accountsCSV), &bulk.IngestOptions{
+		ColumnDelimiter: bulk.DelimiterPipe,
+		PollInterval:    time.Millisecond,
+		CheckpointKey:   "accounts",
+		CheckpointStore: store,
+	})
+}
+
+func TestIngestResume(t *testing.T) {
+	server := newFakeBulk(t)
+	store := &bulk.FileCheckpointStore{Dir: t.TempDir()}
+	checkpoint := checkpointJob(t, server, true)
+	require.Nil(t, store.Save("accounts", checkpoint))
+
+	result, err := resumeIngest(server, store)
+	require.Nil(t, err)
+	require.Len(t, server.order, 1, "the data is not submitted again")
+	require.Equal(t, checkpoint.Jobs[0].ID, result.Jobs[0].ID)
+	require.Equal(t, 3, result.NumRecordsProcessed)
+
+	saved, err := store.Load("accounts")
+	require.Nil(t, err)
+	require.Nil(t, saved, "the checkpoint is deleted")
+}
+
+func TestIngestResumeOpenJob(t *testing.T) {
+	server := newFakeBulk(t)
+	store := &bulk.FileCheckpointStore{Dir: t.TempDir()}
+	checkpoint := checkpointJob(t, server, false)
+	require.Nil(t, store.Save("accounts", checkpoint))
+
+	result, err := resumeIngest(server, store)
+	require.Nil(t, err)
+	require.Len(t, server.order, 2)
+	require.Equal(t, "Aborted", server.job(checkpoint.Jobs[0].ID).info.State)
+	require.NotEqual(t, checkpoint.Jobs[0].ID, result.Jobs[0].ID)
+}
+
+func TestIngestResumeMismatch(t *testing.T) {
+	server := newFakeBulk(t)
+	store := &bulk.FileCheckpointStore{Dir: t.TempDir()}
+	checkpoint := checkpointJob(t, server, true)
+	checkpoint.Jobs[0].Digest = "different"
+	require.Nil(t, store.Save("accounts", checkpoint))
+
+	_, err := resumeIngest(server, store)
+	require.True(t, errors.Is(err, bulk.ErrCheckpointMismatch))
+
+	checkpoint.Operation = string(bulk.OperationDelete)
+	require.Nil(t, store.Save("accounts", checkpoint))
+
+	_, err = resumeIngest(server, store)
+	require.True(t, errors.Is(err, bulk.ErrCheckpointMismatch))
+}
+
+func TestExportResume(t *testing.T) {
+	server := newExportServer(t, 7)
+	store := &bulk.FileCheckpointStore{Dir: t.TempDir()}
+	query := soql.Select("Id", "Name").From("Account")
+
+	var buf bytes.Buffer
+	errCrash := errors.New("crash")
+	write := func(page int, body io.Reader) error {
+		if page == 1 && errCrash != nil {
+			return errCrash
+		}
+
+		if page > 0 {
+			// skip the header repeated by each page
+			contents, _ := ioutil.ReadAll(body)
+			body = bytes.NewReader(contents[bytes.IndexByte(contents, '\n')+1:])
+		}
+
+		_, err := io.Copy(&buf, body)
+		return err
+	}
+
+	options := &bulk.ExportOptions{
+		PollInterval:    time.Millisecond,
+		MaxRecords:      3,
+		CheckpointKey:   "export",
+		CheckpointStore: store,
+	}
+
+	result, err := bulk.ExportPages(context.Background(), server.builder(), query, write, options)
+	require.True(t, errors.Is(err, errCrash))
+	require.NotNil(t, server.job(result.Job.ID), "the job is kept to resume")
+
+	checkpoint, err := store.Load("export")
+	require.Nil(t, err)
+	require.Equal(t, 1, checkpoint.Pages)
+	require.Equal(t, "3", checkpoint.Locator)
+
+	errCrash = nil
+	resumed, err := bulk.ExportPages(context.Background(), server.builder(), query, write, options)
+	require.Nil(t, err)
+	require.Equal(t, result.Job.ID, resumed.Job.ID)
+	require.Equal(t, 3, resumed.Pages)
+	require.Equal(t, 7, resumed.NumberOfRecords)
+	require.Equal(t, string(encodeRows(server.queryRows, "COMMA")), buf.String())
+	require.Len(t, server.order, 1)
+	require.Nil(t, server.job(result.Job.ID), "the job is deleted")
+
+	checkpoint, err = store.Load("export")
+	require.Nil(t, err)
+	require.Nil(t, checkpoint)
+}
+
+// failingStore fails the save numbered fail, counting from 1, and stores every other checkpoint
+type failingStore struct {
+	bulk.FileCheckpointStore
+	fail  int
+	saves int
+}
+
+var errStore = errors.New("store unavailable")
+
+func (s *failingStore) Save(key string, checkpoint *bulk.Checkpoint) error {
+	s.saves++
+	if s.saves == s.fail {
+		return errStore
+	}
+	return s.FileCheckpointStore.Save(key, checkpoint)
+}
+
+func TestIngestCheckpointStateError(t *testing.T) {
+	server := newFakeBulk(t)
+
+	// the first save records the created job and the second its state
+	store := &failingStore{FileCheckpointStore: bulk.FileCheckpointStore{Dir: t.TempDir()}, fail: 2}
+	_, err := resumeIngest(server, store)
+	require.True(t, errors.Is(err, errStore))
+}
+
+func TestExportCheckpointStateError(t *testing.T) {
+	server := newExportServer(t, 7)
+	store := &failingStore{FileCheckpointStore: bulk.FileCheckpointStore{Dir: t.TempDir()}, fail: 2}
+
+	pages := 0
+	_, err := bulk.ExportPages(context.Background(), server.builder(), soql.Select("Id", "Name").From("Account"), func(page int, body io.Reader) error {
+		pages++
+		return nil
+	}, &bulk.ExportOptions{
+		PollInterval:    time.Millisecond,
+		CheckpointKey:   "export",
+		CheckpointStore: store,
+	})
+
+	require.True(t, errors.Is(err, errStore))
+	require.Equal(t, 0, pages, "no page is written after the checkpoint cannot be stored")
+}
@@ -0,0 +1,389 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/requests"
+)
+
+// classic.go speaks Bulk API 1.0, whose jobs hold batches of records which Salesforce processes
+// in parallel or serially, and which can split queries of very large objects by primary key.
+// Its endpoints are beneath /services/async rather than /services/data and its messages are XML
+// unless a job's content type is JSON.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_intro.htm
+
+const classicNamespace = "http://www.force.com/2009/06/asyncapi/dataload"
+
+const (
+	// MaxBatchRecords is the greatest number of records in a Bulk API 1.0 batch
+	MaxBatchRecords = 10000
+	// MaxBatchBytes is the greatest size of the data of a Bulk API 1.0 batch
+	MaxBatchBytes = 10 * 1000 * 1000
+	// MaxBatchCharacters is the greatest number of characters in the data of a Bulk API 1.0 batch
+	MaxBatchCharacters = 10 * 1000 * 1000
+)
+
+const (
+	// OperationQuery is the operation of a Bulk API 1.0 query job
+	OperationQuery = operation(QueryOperation)
+	// OperationQueryAll is the operation of a Bulk API 1.0 query job which includes deleted and
+	// archived records
+	OperationQueryAll = operation(QueryOperationAll)
+)
+
+type concurrencyMode string
+
+const (
+	// ConcurrencyModeParallel processes the batches of a job in parallel, the default
+	ConcurrencyModeParallel concurrencyMode = "Parallel"
+	// ConcurrencyModeSerial processes the batches of a job one at a time, avoiding lock
+	// contention between batches updating records with the same parent
+	ConcurrencyModeSerial concurrencyMode = "Serial"
+)
+
+type batchState string
+
+const (
+	// BatchStateQueued — Processing of the batch has not started yet.
+	BatchStateQueued batchState = "Queued"
+	// BatchStateInProgress — The batch is being processed.
+	BatchStateInProgress batchState = "InProgress"
+	// BatchStateCompleted — The batch has been processed, although individual records may have failed.
+	BatchStateCompleted batchState = "Completed"
+	// BatchStateFailed — The batch could not be processed, see its StateMessage.
+	BatchStateFailed batchState = "Failed"
+	// BatchStateNotProcessed — The batch won't be processed, as is the original batch of a query
+	// split by PK chunking.
+	BatchStateNotProcessed batchState = "NotProcessed"
+)
+
+// ErrBatchFailed is returned when a batch finishes in the Failed state
+var ErrBatchFailed = errors.New("bulk batch failed")
+
+// ClassicJobRequest creates a Bulk API 1.0 job
+type ClassicJobRequest struct {
+	XMLName xml.Name `json:"-" xml:"http://www.force.com/2009/06/asyncapi/dataload jobInfo"`
+	// Operation, including query and queryAll
+	Operation operation `json:"operation" xml:"operation"`
+	// Object the job operates upon
+	Object string `json:"object" xml:"object"`
+	// ExternalIDFieldName is required for upserts
+	ExternalIDFieldName string `json:"externalIdFieldName,omitempty" xml:"externalIdFieldName,omitempty"`
+	// ConcurrencyMode is Parallel by default
+	ConcurrencyMode concurrencyMode `json:"concurrencyMode,omitempty" xml:"concurrencyMode,omitempty"`
+	// ContentType of the batches, which also determines whether messages are JSON or XML
+	ContentType contentType `json:"contentType" xml:"contentType"`
+	// AssignmentRuleID is the assignment rule to run for a Case or Lead
+	AssignmentRuleID string `json:"assignmentRuleId,omitempty" xml:"assignmentRuleId,omitempty"`
+}
+
+// classicStateRequest closes or aborts a Bulk API 1.0 job
+type classicStateRequest struct {
+	XMLName xml.Name `json:"-" xml:"http://www.force.com/2009/06/asyncapi/dataload jobInfo"`
+	State   jobState `json:"state" xml:"state"`
+}
+
+// ClassicJobInfo ...
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_reference_jobinfo.htm
+type ClassicJobInfo struct {
+	XMLName                 xml.Name        `json:"-" xml:"jobInfo"`
+	ID                      string          `json:"id" xml:"id"`
+	Operation               operation       `json:"operation" xml:"operation"`
+	Object                  string          `json:"object" xml:"object"`
+	CreatedByID             string          `json:"createdById" xml:"createdById"`
+	CreatedDate             string          `json:"createdDate" xml:"createdDate"`
+	SystemModstamp          string          `json:"systemModstamp" xml:"systemModstamp"`
+	State                   string          `json:"state" xml:"state"`
+	ExternalIDFieldName     string          `json:"externalIdFieldName" xml:"externalIdFieldName"`
+	ConcurrencyMode         concurrencyMode `json:"concurrencyMode" xml:"concurrencyMode"`
+	ContentType             contentType     `json:"contentType" xml:"contentType"`
+	NumberBatchesQueued     int             `json:"numberBatchesQueued" xml:"numberBatchesQueued"`
+	NumberBatchesInProgress int             `json:"numberBatchesInProgress" xml:"numberBatchesInProgress"`
+	NumberBatchesCompleted  int             `json:"numberBatchesCompleted" xml:"numberBatchesCompleted"`
+	NumberBatchesFailed     int             `json:"numberBatchesFailed" xml:"numberBatchesFailed"`
+	NumberBatchesTotal      int             `json:"numberBatchesTotal" xml:"numberBatchesTotal"`
+	NumberRecordsProcessed  int             `json:"numberRecordsProcessed" xml:"numberRecordsProcessed"`
+	NumberRetries           int             `json:"numberRetries" xml:"numberRetries"`
+	APIVersion              float64         `json:"apiVersion" xml:"apiVersion"`
+	NumberRecordsFailed     int             `json:"numberRecordsFailed" xml:"numberRecordsFailed"`
+	TotalProcessingTime     int64           `json:"totalProcessingTime" xml:"totalProcessingTime"`
+	APIActiveProcessingTime int64           `json:"apiActiveProcessingTime" xml:"apiActiveProcessingTime"`
+	ApexProcessingTime      int64           `json:"apexProcessingTime" xml:"apexProcessingTime"`
+}
+
+// BatchInfo ...
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_reference_batchinfo.htm
+type BatchInfo struct {
+	XMLName                 xml.Name `json:"-" xml:"batchInfo"`
+	ID                      string   `json:"id" xml:"id"`
+	JobID                   string   `json:"jobId" xml:"jobId"`
+	State                   string   `json:"state" xml:"state"`
+	StateMessage            string   `json:"stateMessage" xml:"stateMessage"`
+	CreatedDate             string   `json:"createdDate" xml:"createdDate"`
+	SystemModstamp          string   `json:"systemModstamp" xml:"systemModstamp"`
+	NumberRecordsProcessed  int      `json:"numberRecordsProcessed" xml:"numberRecordsProcessed"`
+	NumberRecordsFailed     int      `json:"numberRecordsFailed" xml:"numberRecordsFailed"`
+	TotalProcessingTime     int64    `json:"totalProcessingTime" xml:"totalProcessingTime"`
+	APIActiveProcessingTime int64    `json:"apiActiveProcessingTime" xml:"apiActiveProcessingTime"`
+	ApexProcessingTime      int64    `json:"apexProcessingTime" xml:"apexProcessingTime"`
+}
+
+// batchInfoList ...
+type batchInfoList struct {
+	XMLName   xml.Name     `json:"-" xml:"batchInfoList"`
+	BatchInfo []*BatchInfo `json:"batchInfo" xml:"batchInfo"`
+}
+
+// resultList is the IDs of the results of a query batch
+type resultList struct {
+	XMLName xml.Name `xml:"result-list"`
+	Result  []string `xml:"result"`
+}
+
+// PKChunking splits a query of a large object into batches by ranges of record IDs
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/async_api_headers_enable_pk_chunking.htm
+type PKChunking struct {
+	// ChunkSize is the number of records in each batch, 100,000 by default and at most 250,000
+	ChunkSize int
+	// Parent is the parent object of a shared object such as AccountShare, i.e. Account
+	Parent string
+	// StartRow is the ID of the record from which the first batch begins
+	StartRow string
+}
+
+// header returns the value of the Sforce-Enable-PKChunking header
+func (p *PKChunking) header() string {
+	var fields []string
+	if p.ChunkSize > 0 {
+		fields = append(fields, "chunkSize="+strconv.Itoa(p.ChunkSize))
+	}
+
+	if p.Parent != "" {
+		fields = append(fields, "parent="+p.Parent)
+	}
+
+	if p.StartRow != "" {
+		fields = append(fields, "startRow="+p.StartRow)
+	}
+
+	if len(fields) == 0 {
+		return "true"
+	}
+
+	return strings.Join(fields, "; ")
+}
+
+// Classic sends requests to Bulk API 1.0
+type Classic struct {
+	builder requests.Builder
+	url     string
+}
+
+// NewClassic returns a Classic sending requests with builder to the Bulk API 1.0 endpoints of
+// instanceURL for the given API version, such as "50.0":
+//
+//	client := salesforce.DefaultClient
+//	classic := bulk.NewClassic(requests.Sender(client), client.InstanceURL(), client.Version()).Session(accessToken)
+func NewClassic(builder requests.Builder, instanceURL string, version string) *Classic {
+	return &Classic{
+		builder: builder,
+		url:     fmt.Sprintf("%s/services/async/%s", strings.TrimSuffix(instanceURL, "/"), strings.TrimPrefix(version, "v")),
+	}
+}
+
+// Session returns a copy of c which authenticates with the X-SFDC-Session header, which Bulk
+// API 1.0 requires in place of an Authorization header. The access token of an OAuth login
+// is a valid session ID.
+func (c *Classic) Session(sessionID string) *Classic {
+	return &Classic{builder: c.builder.Header("X-SFDC-Session", sessionID), url: c.url}
+}
+
+// Context returns a copy of c whose requests use ctx
+func (c *Classic) Context(ctx context.Context) *Classic {
+	return &Classic{builder: c.builder.Context(ctx), url: c.url}
+}
+
+// endpoint returns the URL of a path beneath job
+func (c *Classic) endpoint(path ...string) string {
+	return strings.Join(append([]string{c.url, "job"}, path...), "/")
+}
+
+// CreateJob ...
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_jobs_create.htm
+func (c *Classic) CreateJob(req *ClassicJobRequest, pkChunking *PKChunking) (job *ClassicJobInfo, err error) {
+	builder, err := message(c.builder.Method(http.MethodPost).URL(c.endpoint()), req.ContentType, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkChunking != nil {
+		builder = builder.Header("Sforce-Enable-PKChunking", pkChunking.header())
+	}
+
+	return job, decodeMessage(builder, &job)
+}
+
+// GetJob ...
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_jobs_get_details.htm
+func (c *Classic) GetJob(jobID string) (job *ClassicJobInfo, err error) {
+	return job, decodeMessage(c.builder.Method(http.MethodGet).URL(c.endpoint(jobID)), &job)
+}
+
+// CloseJob closes a job to further batches, which is required before Salesforce considers it
+// complete
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_jobs_close.htm
+func (c *Classic) CloseJob(job *ClassicJobInfo) (*ClassicJobInfo, error) {
+	return c.setJobState(job, JobStateClosed)
+}
+
+// AbortJob aborts a job, so that batches which have not started are not processed
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_jobs_abort.htm
+func (c *Classic) AbortJob(job *ClassicJobInfo) (*ClassicJobInfo, error) {
+	return c.setJobState(job, JobStateAborted)
+}
+
+func (c *Classic) setJobState(job *ClassicJobInfo, state jobState) (updated *ClassicJobInfo, err error) {
+	builder, err := message(c.builder.Method(http.MethodPost).URL(c.endpoint(job.ID)), job.ContentType, &classicStateRequest{State: state})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, decodeMessage(builder, &updated)
+}
+
+// AddBatch adds a batch of records in the content type of job, or a SOQL query for a query job
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_batches_create.htm
+func (c *Classic) AddBatch(job *ClassicJobInfo, body io.Reader) (batch *BatchInfo, err error) {
+	builder := c.builder.
+		Method(http.MethodPost).
+		URL(c.endpoint(job.ID, "batch")).
+		Header("Content-Type", batchContentType(job.ContentType)).
+		Body(body)
+
+	return batch, decodeMessage(builder, &batch)
+}
+
+// GetBatch ...
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_batches_get_info.htm
+func (c *Classic) GetBatch(jobID string, batchID string) (batch *BatchInfo, err error) {
+	return batch, decodeMessage(c.builder.Method(http.MethodGet).URL(c.endpoint(jobID, "batch", batchID)), &batch)
+}
+
+// GetBatches returns every batch of a job, including those created by PK chunking
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_batches_get_info_all.htm
+func (c *Classic) GetBatches(jobID string) ([]*BatchInfo, error) {
+	var list batchInfoList
+	if err := decodeMessage(c.builder.Method(http.MethodGet).URL(c.endpoint(jobID, "batch")), &list); err != nil {
+		return nil, err
+	}
+
+	return list.BatchInfo, nil
+}
+
+// GetBatchResults returns the results of an ingest batch in the content type of its job. The
+// results of a CSV batch have the columns Id, Success, Created, and Error, and are in the order
+// of the records of the batch.
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_batches_get_results.htm
+func (c *Classic) GetBatchResults(jobID string, batchID string) ([]byte, error) {
+	response, err := c.builder.Method(http.MethodGet).URL(c.endpoint(jobID, "batch", batchID, "result")).Response()
+	if err != nil {
+		return nil, err
+	}
+
+	return requests.ReadAndCloseResponse(response)
+}
+
+// GetQueryResultIDs returns the IDs of the results of a query batch, each of which is retrieved
+// by GetQueryResult
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_bulk_query_processing.htm
+func (c *Classic) GetQueryResultIDs(jobID string, batchID string) ([]string, error) {
+	response, err := c.builder.Method(http.MethodGet).URL(c.endpoint(jobID, "batch", batchID, "result")).Response()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := requests.ReadAndCloseResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if isJSON(response) {
+		var ids []string
+		return ids, json.Unmarshal(contents, &ids)
+	}
+
+	var list resultList
+	return list.Result, xml.Unmarshal(contents, &list)
+}
+
+// GetQueryResult returns the body of a result of a query batch, which the caller must close
+func (c *Classic) GetQueryResult(jobID string, batchID string, resultID string) (io.ReadCloser, error) {
+	page := getPage(c.builder.Method(http.MethodGet).URL(c.endpoint(jobID, "batch", batchID, "result", resultID)))
+	if page.err != nil {
+		return nil, page.err
+	}
+
+	return readCloser{page.body, page.response.Body}, nil
+}
+
+// readCloser reads from a decompressing reader while closing the underlying body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// message sets the body of builder to v encoded as JSON for JSON jobs, or as XML otherwise
+func message(builder requests.Builder, ct contentType, v interface{}) (requests.Builder, error) {
+	if ct == ContentTypeJSON {
+		contents, err := json.Marshal(v)
+		return builder.Header("Content-Type", "application/json").Body(bytes.NewReader(contents)), err
+	}
+
+	contents, err := xml.Marshal(v)
+	return builder.Header("Content-Type", "application/xml; charset=UTF-8").Body(bytes.NewReader(append([]byte(xml.Header), contents...))), err
+}
+
+// decodeMessage sends the request of builder and decodes its JSON or XML response into dst
+func decodeMessage(builder requests.Builder, dst interface{}) error {
+	response, err := builder.Response()
+	if err != nil {
+		return err
+	}
+
+	contents, err := requests.ReadAndCloseResponse(response)
+	if err != nil {
+		return err
+	}
+
+	if isJSON(response) {
+		return json.Unmarshal(contents, dst)
+	}
+
+	return xml.Unmarshal(contents, dst)
+}
+
+// isJSON reports whether the body of response is JSON
+func isJSON(response *http.Response) bool {
+	return strings.Contains(response.Header.Get("Content-Type"), "json")
+}
+
+// batchContentType returns the Content-Type header of the batches of a job
+func batchContentType(ct contentType) string {
+	switch ct {
+	case ContentTypeJSON:
+		return "application/json"
+	case ContentTypeXML:
+		return "application/xml; charset=UTF-8"
+	}
+	return "text/csv; charset=UTF-8"
+}
@@ -0,0 +1,439 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+)
+
+// classic_orchestrate.go runs Bulk API 1.0 jobs with the lifecycle of Ingest and Export: adding
+// the data of a job as batches, closing it, polling its batches until Salesforce finishes them,
+// and collecting their results in the order of the input.
+
+// ClassicOptions configures Classic.Ingest and Classic.Export. The zero value creates parallel
+// jobs whose batches hold up to MaxBatchRecords rows and are polled as IngestOptions are.
+type ClassicOptions struct {
+	// ExternalIDFieldName is required for upserts
+	ExternalIDFieldName string
+	// AssignmentRuleID is the assignment rule to run for a Case or Lead
+	AssignmentRuleID string
+	// ConcurrencyMode is Parallel by default. Serial processes one batch at a time, which avoids
+	// lock contention between batches updating children of the same parent record.
+	ConcurrencyMode concurrencyMode
+	// BatchSize is the greatest number of rows in each batch, MaxBatchRecords by default
+	BatchSize int
+	// PollInterval is the initial delay between requests for the state of the batches
+	PollInterval time.Duration
+	// MaxPollInterval is the greatest delay between requests for the state of the batches
+	MaxPollInterval time.Duration
+	// Progress, if not nil, is called with the batches of the job after each request for them
+	Progress func(batches []*BatchInfo)
+	// PKChunking, if not nil, splits the query of an export into a batch for each range of
+	// record IDs
+	PKChunking *PKChunking
+	// QueryAll includes deleted and archived records in an export
+	QueryAll bool
+}
+
+// withDefaults returns a copy of options with unset values defaulted
+func (o *ClassicOptions) withDefaults() ClassicOptions {
+	var options ClassicOptions
+	if o != nil {
+		options = *o
+	}
+
+	if options.BatchSize <= 0 || options.BatchSize > MaxBatchRecords {
+		options.BatchSize = MaxBatchRecords
+	}
+
+	polling := (&IngestOptions{PollInterval: options.PollInterval, MaxPollInterval: options.MaxPollInterval}).withDefaults()
+	options.PollInterval, options.MaxPollInterval = polling.PollInterval, polling.MaxPollInterval
+	return options
+}
+
+// ClassicResult summarizes a completed Bulk API 1.0 ingest. Its results have the columns of
+// those of IngestResult, so they may be read by a Decoder in the same way.
+type ClassicResult struct {
+	// Job is the final state of the job
+	Job *ClassicJobInfo
+	// Batches are the final states of the batches of the job, in the order of the input
+	Batches []*BatchInfo
+	// NumRecordsProcessed is the number of records of the input processed
+	NumRecordsProcessed int
+	// NumRecordsFailed is the number of records which failed, including those of failed batches
+	NumRecordsFailed int
+
+	successful []byte
+	failed     []byte
+}
+
+// Successful returns the successfully processed rows prefixed by the sf__Id and sf__Created
+// columns
+func (r *ClassicResult) Successful() *csv.Reader {
+	return newCSVReader(bytes.NewReader(r.successful), DelimiterComma)
+}
+
+// Failed returns the rows which failed prefixed by the sf__Id and sf__Error columns. The rows of
+// a batch which failed as a whole carry the StateMessage of the batch as their error.
+func (r *ClassicResult) Failed() *csv.Reader {
+	return newCSVReader(bytes.NewReader(r.failed), DelimiterComma)
+}
+
+// classicBatch is a batch of a job along with the split of the input it holds
+type classicBatch struct {
+	info  *BatchInfo
+	split *split
+}
+
+// Ingest creates a CSV job to perform operation upon object, adds the comma delimited data of body
+// as batches of options.BatchSize rows, and waits for Salesforce to process them before
+// collecting their results:
+//
+//	result, err := classic.Ingest(ctx, "Contact", bulk.OperationUpdate, file, &bulk.ClassicOptions{
+//		ConcurrencyMode: bulk.ConcurrencyModeSerial,
+//	})
+//
+// If ctx is canceled the job is aborted. If a batch fails as a whole its rows are returned by
+// ClassicResult.Failed along with an error wrapping ErrBatchFailed.
+func (c *Classic) Ingest(ctx context.Context, object string, operation operation, body io.Reader, options *ClassicOptions) (*ClassicResult, error) {
+	opts := options.withDefaults()
+	c = c.Context(ctx)
+
+	splits, err := newSplitter(body, IngestOptions{
+		ColumnDelimiter:     DelimiterComma,
+		LineEnding:          LineEndingLF,
+		MaxUploadBytes:      MaxBatchBytes,
+		MaxUploadCharacters: MaxBatchCharacters,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	splits.maxRows = opts.BatchSize
+
+	job, err := c.CreateJob(&ClassicJobRequest{
+		Operation:           operation,
+		Object:              object,
+		ExternalIDFieldName: opts.ExternalIDFieldName,
+		ConcurrencyMode:     opts.ConcurrencyMode,
+		ContentType:         ContentTypeCSV,
+		AssignmentRuleID:    opts.AssignmentRuleID,
+	}, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("creating %s job: %w", operation, err)
+	}
+
+	var batches []*classicBatch
+	for {
+		sp, err := splits.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			c.abortJob(job)
+			return nil, err
+		}
+
+		info, err := c.AddBatch(job, bytes.NewReader(sp.data))
+		if err != nil {
+			c.abortJob(job)
+			return nil, fmt.Errorf("adding batch %d to job %s: %w", sp.index, job.ID, err)
+		}
+
+		batches = append(batches, &classicBatch{info: info, split: sp})
+	}
+
+	if _, err := c.CloseJob(job); err != nil {
+		c.abortJob(job)
+		return nil, fmt.Errorf("closing job %s: %w", job.ID, err)
+	}
+
+	states, err := c.waitForBatches(ctx, job, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.collectResults(job, batches, states, splits.header)
+	if result == nil {
+		return nil, err
+	}
+
+	if final, jobErr := c.GetJob(job.ID); jobErr == nil {
+		result.Job = final
+	} else if err == nil {
+		err = fmt.Errorf("getting job %s: %w", job.ID, jobErr)
+	}
+
+	return result, err
+}
+
+// collectResults joins the results of each batch with the rows of its split
+func (c *Classic) collectResults(job *ClassicJobInfo, batches []*classicBatch, states []*BatchInfo, header []string) (*ClassicResult, error) {
+	byID := make(map[string]*BatchInfo, len(states))
+	for _, state := range states {
+		byID[state.ID] = state
+	}
+
+	result := &ClassicResult{Job: job}
+	var successful, failed bytes.Buffer
+	successWriter, failWriter := csv.NewWriter(&successful), csv.NewWriter(&failed)
+	successWriter.Write(append([]string{"sf__Id", "sf__Created"}, header...))
+	failWriter.Write(append([]string{"sf__Id", "sf__Error"}, header...))
+
+	var batchErr error
+	for _, batch := range batches {
+		info := batch.info
+		if state, ok := byID[info.ID]; ok {
+			info = state
+		}
+		result.Batches = append(result.Batches, info)
+
+		rows, err := newCSVReader(bytes.NewReader(batch.split.data), DelimiterComma).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		rows = rows[1:]
+
+		if batchState(info.State) != BatchStateCompleted {
+			for _, row := range rows {
+				failWriter.Write(append([]string{"", info.StateMessage}, row...))
+			}
+
+			result.NumRecordsFailed += len(rows)
+			if batchErr == nil {
+				batchErr = fmt.Errorf("%w %s: %s", ErrBatchFailed, info.ID, info.StateMessage)
+			}
+			continue
+		}
+
+		contents, err := c.GetBatchResults(job.ID, info.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting results of batch %s: %w", info.ID, err)
+		}
+
+		// the results of a batch are Id, Success, Created and Error, in the order of its rows
+		results, err := newCSVReader(bytes.NewReader(contents), DelimiterComma).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("parsing results of batch %s: %w", info.ID, err)
+		}
+
+		if len(results) > 0 {
+			results = results[1:]
+		}
+
+		if len(results) != len(rows) {
+			return nil, fmt.Errorf("batch %s returned %d results for %d rows", info.ID, len(results), len(rows))
+		}
+
+		for i, row := range rows {
+			id, success, created, sfError := resultColumns(results[i])
+			result.NumRecordsProcessed++
+			if success {
+				successWriter.Write(append([]string{id, created}, row...))
+				continue
+			}
+
+			failWriter.Write(append([]string{id, sfError}, row...))
+			result.NumRecordsFailed++
+		}
+	}
+
+	successWriter.Flush()
+	failWriter.Flush()
+	result.successful, result.failed = successful.Bytes(), failed.Bytes()
+	if err := successWriter.Error(); err != nil {
+		return nil, err
+	}
+
+	if err := failWriter.Error(); err != nil {
+		return nil, err
+	}
+
+	return result, batchErr
+}
+
+// resultColumns returns the values of a row of the results of an ingest batch
+func resultColumns(row []string) (id string, success bool, created string, sfError string) {
+	values := make([]string, 4)
+	copy(values, row)
+	return values[0], strings.EqualFold(values[1], "true"), values[2], values[3]
+}
+
+// waitForBatches polls the batches of job until every batch is finished, returning their final
+// states. If ctx is canceled first the job is aborted.
+func (c *Classic) waitForBatches(ctx context.Context, job *ClassicJobInfo, opts ClassicOptions) ([]*BatchInfo, error) {
+	var batches []*BatchInfo
+	err := poll(ctx, opts.PollInterval, opts.MaxPollInterval, func() (bool, error) {
+		var err error
+		if batches, err = c.GetBatches(job.ID); err != nil {
+			return false, fmt.Errorf("getting batches of job %s: %w", job.ID, err)
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(batches)
+		}
+
+		for _, batch := range batches {
+			if !isBatchFinished(batch.State) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+
+	if ctx.Err() != nil {
+		c.abortJob(job)
+		return nil, ctx.Err()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+// isBatchFinished reports whether a batch has reached a terminal state
+func isBatchFinished(state string) bool {
+	switch batchState(state) {
+	case BatchStateCompleted, BatchStateFailed, BatchStateNotProcessed:
+		return true
+	}
+	return false
+}
+
+// abortJob makes a best effort attempt to abort a job, regardless of whether the context of c was
+// canceled
+func (c *Classic) abortJob(job *ClassicJobInfo) {
+	c.Context(context.Background()).AbortJob(job)
+}
+
+// ClassicExportResult summarizes a completed Bulk API 1.0 export
+type ClassicExportResult struct {
+	// Job is the state of the query job once its batches finished
+	Job *ClassicJobInfo
+	// Batches are the final states of the batches of the job. When PK chunking is enabled the
+	// batch holding the query is NotProcessed and each chunk is a batch of its own.
+	Batches []*BatchInfo
+	// Pages is the number of results written
+	Pages int
+	// NumberOfRecords is the number of records processed by the batches of the job
+	NumberOfRecords int
+}
+
+// Export creates a CSV query job upon object for q, waits for its batches to complete, and writes
+// their results to w as a single CSV with one header row:
+//
+//	result, err := classic.Export(ctx, "Account", soql.Select("Id", "Name").From("Account"), file, &bulk.ClassicOptions{
+//		PKChunking: &bulk.PKChunking{ChunkSize: 250000},
+//	})
+//
+// If ctx is canceled while the job is running it is aborted, and the job is closed afterward.
+func (c *Classic) Export(ctx context.Context, object string, q soql.Builder, w io.Writer, options *ClassicOptions) (*ClassicExportResult, error) {
+	return c.ExportPages(ctx, object, q, writePages(w), options)
+}
+
+// ExportPages is Export with each result of each batch given to fn in the order of the batches
+func (c *Classic) ExportPages(ctx context.Context, object string, q soql.Builder, fn PageFunc, options *ClassicOptions) (*ClassicExportResult, error) {
+	opts := options.withDefaults()
+	c = c.Context(ctx)
+
+	if soql.ContainsFields(q) {
+		return nil, fmt.Errorf("bulk.Classic.Export: %w", soql.ErrFieldsNotSupported)
+	}
+
+	sql, err := q.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	op := OperationQuery
+	if opts.QueryAll {
+		op = OperationQueryAll
+	}
+
+	job, err := c.CreateJob(&ClassicJobRequest{
+		Operation:       op,
+		Object:          object,
+		ConcurrencyMode: opts.ConcurrencyMode,
+		ContentType:     ContentTypeCSV,
+	}, opts.PKChunking)
+
+	if err != nil {
+		return nil, fmt.Errorf("creating query job: %w", err)
+	}
+
+	// the job is closed once its batches finish, as closing it sooner would prevent PK chunking
+	// from adding the batch of each chunk
+	defer c.Context(context.Background()).CloseJob(job)
+
+	if _, err := c.AddBatch(job, strings.NewReader(sql)); err != nil {
+		c.abortJob(job)
+		return nil, fmt.Errorf("adding query to job %s: %w", job.ID, err)
+	}
+
+	batches, err := c.waitForBatches(ctx, job, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ClassicExportResult{Job: job, Batches: batches}
+	for _, batch := range batches {
+		switch batchState(batch.State) {
+		case BatchStateNotProcessed:
+			continue
+		case BatchStateFailed:
+			return result, fmt.Errorf("%w %s: %s", ErrBatchFailed, batch.ID, batch.StateMessage)
+		}
+
+		if err := result.download(c, batch, fn); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// classicNoRecords is the body of the result of a query batch without records, such as a chunk
+// of a PK chunked query whose range holds no records. It has no header and is not given to fn.
+const classicNoRecords = "Records not found for this query"
+
+// download gives each result of a completed query batch to fn
+func (r *ClassicExportResult) download(c *Classic, batch *BatchInfo, fn PageFunc) error {
+	ids, err := c.GetQueryResultIDs(r.Job.ID, batch.ID)
+	if err != nil {
+		return fmt.Errorf("getting results of batch %s: %w", batch.ID, err)
+	}
+
+	for _, id := range ids {
+		body, err := c.GetQueryResult(r.Job.ID, batch.ID, id)
+		if err != nil {
+			return fmt.Errorf("getting result %s of batch %s: %w", id, batch.ID, err)
+		}
+
+		reader := bufio.NewReader(body)
+		if prefix, _ := reader.Peek(len(classicNoRecords)); string(prefix) == classicNoRecords {
+			body.Close()
+			continue
+		}
+
+		err = fn(r.Pages, reader)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("writing result %s of batch %s: %w", id, batch.ID, err)
+		}
+		r.Pages++
+	}
+
+	r.NumberOfRecords += batch.NumberRecordsProcessed
+	return nil
+}
@@ -0,0 +1,381 @@
+package bulk_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/requests"
+)
+
+const fakeSession = "00D000000000001!session"
+
+// fakeClassicJob is a job held by a fakeClassic server
+type fakeClassicJob struct {
+	info       bulk.ClassicJobInfo
+	batches    []*fakeClassicBatch
+	pkChunking string
+}
+
+// fakeClassicBatch is a batch of a fakeClassicJob along with its results
+type fakeClassicBatch struct {
+	info    bulk.BatchInfo
+	rows    [][]string
+	polls   int
+	results [][]string
+	// pages are the results of a query batch by result ID
+	pages map[string][][]string
+	ids   []string
+}
+
+// fakeClassic imitates the job and batch endpoints of Bulk API 1.0, answering in XML unless a
+// job's content type is JSON. Batches complete after pollsUntilComplete requests for them, one
+// at a time for serial jobs. Query batches return queryRows, split into a batch for each
+// chunkSize rows when PK chunking is enabled and into a result for each resultSize rows. Results
+// without rows return the message of Salesforce rather than a header.
+type fakeClassic struct {
+	mu     sync.Mutex
+	server *httptest.Server
+	jobs   map[string]*fakeClassicJob
+	nextID int
+	// pollsUntilComplete is the number of requests for a batch before it is Completed
+	pollsUntilComplete int
+	// fail returns the Error of a row, or "" if it succeeds
+	fail func(row map[string]string) string
+	// failBatch returns the StateMessage of a batch which fails as a whole, or "" if it does not
+	failBatch func(rows [][]string) string
+	// queryRows are the header and rows returned by every query job
+	queryRows [][]string
+	// resultSize is the number of rows in each result of a query batch, all of them by default
+	resultSize int
+	// emptyChunks is the number of chunks without rows which precede the chunks of queryRows
+	emptyChunks int
+	// inProgress is the greatest number of batches of a job which were InProgress at once
+	inProgress int
+}
+
+func newFakeClassic(t *testing.T) *fakeClassic {
+	f := &fakeClassic{jobs: make(map[string]*fakeClassicJob), pollsUntilComplete: 2}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeClassic) classic() *bulk.Classic {
+	return bulk.NewClassic(requests.Sender(&fakeSender{f.server.URL}), f.server.URL, "v50.0").Session(fakeSession)
+}
+
+func (f *fakeClassic) job(id string) *fakeClassicJob {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jobs[id]
+}
+
+func (f *fakeClassic) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("X-SFDC-Session") != fakeSession {
+		writeMessage(w, http.StatusUnauthorized, "", &classicError{ExceptionCode: "InvalidSessionId"})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 4 || strings.Join(parts[:4], "/") != "services/async/50.0/job" {
+		http.NotFound(w, r)
+		return
+	}
+	parts = parts[4:]
+
+	if len(parts) == 0 && r.Method == http.MethodPost {
+		var req bulk.ClassicJobRequest
+		if err := decodeRequest(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.nextID++
+		job := &fakeClassicJob{pkChunking: r.Header.Get("Sforce-Enable-PKChunking")}
+		job.info = bulk.ClassicJobInfo{
+			ID:                  fmt.Sprintf("750%012d", f.nextID),
+			Operation:           req.Operation,
+			Object:              req.Object,
+			State:               "Open",
+			ExternalIDFieldName: req.ExternalIDFieldName,
+			ConcurrencyMode:     req.ConcurrencyMode,
+			ContentType:         req.ContentType,
+		}
+
+		if job.info.ConcurrencyMode == "" {
+			job.info.ConcurrencyMode = bulk.ConcurrencyModeParallel
+		}
+
+		f.jobs[job.info.ID] = job
+		writeMessage(w, http.StatusCreated, job.info.ContentType, &job.info)
+		return
+	}
+
+	job, ok := f.jobs[parts[0]]
+	if len(parts) == 0 || !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeMessage(w, http.StatusOK, job.info.ContentType, &job.info)
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		var req struct {
+			State string `json:"state" xml:"state"`
+		}
+
+		if err := decodeRequest(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job.info.State = req.State
+		writeMessage(w, http.StatusOK, job.info.ContentType, &job.info)
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		if job.info.State != "Open" {
+			writeMessage(w, http.StatusBadRequest, job.info.ContentType, &classicError{ExceptionCode: "InvalidJobState"})
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		batch := f.addBatch(job, body)
+		if job.info.Operation == "query" || job.info.Operation == "queryAll" {
+			f.chunk(job, batch)
+		}
+		writeMessage(w, http.StatusCreated, job.info.ContentType, &batch.info)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		f.advance(job)
+		list := struct {
+			XMLName   xml.Name          `json:"-" xml:"batchInfoList"`
+			BatchInfo []*bulk.BatchInfo `json:"batchInfo" xml:"batchInfo"`
+		}{}
+
+		for _, batch := range job.batches {
+			list.BatchInfo = append(list.BatchInfo, &batch.info)
+		}
+		writeMessage(w, http.StatusOK, job.info.ContentType, &list)
+	default:
+		f.handleBatch(w, r, job, parts[1:])
+	}
+}
+
+// handleBatch serves the requests for a single batch and its results
+func (f *fakeClassic) handleBatch(w http.ResponseWriter, r *http.Request, job *fakeClassicJob, parts []string) {
+	if len(parts) < 2 || parts[0] != "batch" || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+
+	var batch *fakeClassicBatch
+	for _, b := range job.batches {
+		if b.info.ID == parts[1] {
+			batch = b
+		}
+	}
+
+	if batch == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 2:
+		writeMessage(w, http.StatusOK, job.info.ContentType, &batch.info)
+	case batch.info.State != "Completed":
+		writeMessage(w, http.StatusBadRequest, job.info.ContentType, &classicError{ExceptionCode: "InvalidBatch"})
+	case len(parts) == 3 && batch.pages != nil:
+		writeMessage(w, http.StatusOK, job.info.ContentType, &struct {
+			XMLName xml.Name `xml:"result-list"`
+			Result  []string `xml:"result"`
+		}{Result: batch.ids})
+	case len(parts) == 3:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write(encodeRows(batch.results, "COMMA"))
+	case len(parts) == 4 && len(batch.pages[parts[3]]) == 1:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("Records not found for this query"))
+	case len(parts) == 4 && batch.pages[parts[3]] != nil:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write(encodeRows(batch.pages[parts[3]], "COMMA"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// addBatch adds a Queued batch holding the CSV of body to job
+func (f *fakeClassic) addBatch(job *fakeClassicJob, body []byte) *fakeClassicBatch {
+	f.nextID++
+	batch := &fakeClassicBatch{}
+	batch.info = bulk.BatchInfo{ID: fmt.Sprintf("751%012d", f.nextID), JobID: job.info.ID, State: "Queued"}
+	batch.rows, _ = csv.NewReader(bytes.NewReader(body)).ReadAll()
+	job.batches = append(job.batches, batch)
+	job.info.NumberBatchesTotal++
+	return batch
+}
+
+// chunk makes batch the query batch of job, replacing it by a batch for each chunk of queryRows if
+// PK chunking is enabled
+func (f *fakeClassic) chunk(job *fakeClassicJob, batch *fakeClassicBatch) {
+	header, rows := f.queryRows[0], f.queryRows[1:]
+	batch.rows = nil
+	if job.pkChunking == "" {
+		f.paginate(batch, header, rows)
+		return
+	}
+
+	size := 100000
+	for _, field := range strings.Split(job.pkChunking, ";") {
+		if kv := strings.SplitN(strings.TrimSpace(field), "=", 2); len(kv) == 2 && kv[0] == "chunkSize" {
+			size, _ = strconv.Atoi(kv[1])
+		}
+	}
+
+	batch.info.State = "NotProcessed"
+	for i := 0; i < f.emptyChunks; i++ {
+		f.paginate(f.addBatch(job, nil), header, nil)
+	}
+
+	for offset := 0; offset < len(rows); offset += size {
+		end := offset + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+		f.paginate(f.addBatch(job, nil), header, rows[offset:end])
+	}
+}
+
+// paginate divides the results of a query batch into pages of resultSize rows
+func (f *fakeClassic) paginate(batch *fakeClassicBatch, header []string, rows [][]string) {
+	batch.pages = make(map[string][][]string)
+	size := f.resultSize
+	if size <= 0 {
+		size = len(rows)
+	}
+
+	for offset := 0; ; offset += size {
+		end := offset + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		id := fmt.Sprintf("752%s%03d", batch.info.ID[len(batch.info.ID)-9:], len(batch.ids))
+		batch.ids = append(batch.ids, id)
+		batch.pages[id] = append([][]string{header}, rows[offset:end]...)
+		if end >= len(rows) {
+			break
+		}
+	}
+	batch.info.NumberRecordsProcessed = len(rows)
+}
+
+// advance moves the batches of job toward completion, one at a time for a serial job
+func (f *fakeClassic) advance(job *fakeClassicJob) {
+	var inProgress int
+	for _, batch := range job.batches {
+		switch batch.info.State {
+		case "Queued":
+			if job.info.ConcurrencyMode == bulk.ConcurrencyModeSerial && inProgress > 0 {
+				continue
+			}
+			batch.info.State = "InProgress"
+		case "InProgress":
+			if batch.polls++; batch.polls >= f.pollsUntilComplete {
+				f.process(job, batch)
+				continue
+			}
+		default:
+			continue
+		}
+
+		if inProgress++; inProgress > f.inProgress {
+			f.inProgress = inProgress
+		}
+	}
+}
+
+// process completes an ingest batch, computing its results in the order of its rows
+func (f *fakeClassic) process(job *fakeClassicJob, batch *fakeClassicBatch) {
+	batch.info.State = "Completed"
+	if batch.pages != nil {
+		return
+	}
+
+	header, rows := batch.rows[0], batch.rows[1:]
+	if f.failBatch != nil {
+		if message := f.failBatch(rows); message != "" {
+			batch.info.State = "Failed"
+			batch.info.StateMessage = message
+			return
+		}
+	}
+
+	batch.results = [][]string{{"Id", "Success", "Created", "Error"}}
+	for i, row := range rows {
+		fields := make(map[string]string, len(header))
+		for j, column := range header {
+			fields[column] = row[j]
+		}
+
+		batch.info.NumberRecordsProcessed++
+		if f.fail != nil {
+			if message := f.fail(fields); message != "" {
+				batch.info.NumberRecordsFailed++
+				batch.results = append(batch.results, []string{"", "false", "false", message})
+				continue
+			}
+		}
+
+		id := fields["Id"]
+		created := "false"
+		if id == "" {
+			id = fmt.Sprintf("001%s%03d", batch.info.ID[len(batch.info.ID)-9:], i)
+			created = "true"
+		}
+		batch.results = append(batch.results, []string{id, "true", created, ""})
+	}
+
+	job.info.NumberRecordsProcessed += batch.info.NumberRecordsProcessed
+	job.info.NumberRecordsFailed += batch.info.NumberRecordsFailed
+}
+
+// classicError is the error message of Bulk API 1.0
+type classicError struct {
+	XMLName       xml.Name `json:"-" xml:"error"`
+	ExceptionCode string   `json:"exceptionCode" xml:"exceptionCode"`
+}
+
+// decodeRequest decodes the JSON or XML body of r into dst
+func decodeRequest(r *http.Request, dst interface{}) error {
+	if strings.Contains(r.Header.Get("Content-Type"), "json") {
+		return json.NewDecoder(r.Body).Decode(dst)
+	}
+	return xml.NewDecoder(r.Body).Decode(dst)
+}
+
+// writeMessage writes v as JSON for jobs whose content type is JSON and as XML otherwise
+func writeMessage(w http.ResponseWriter, statusCode int, ct interface{}, v interface{}) {
+	if fmt.Sprint(ct) == "JSON" {
+		writeJSON(w, statusCode, v)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(v)
+}
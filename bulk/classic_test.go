@@ -0,0 +1,238 @@
+package bulk_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/requests"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+const contactsCSV = "LastName,Email\nOne,one@example.com\nTwo,invalid\nThree,three@example.com\nFour,four@example.com\nFive,five@example.com\n"
+
+func newClassicServer(t *testing.T) *fakeClassic {
+	server := newFakeClassic(t)
+	server.fail = func(row map[string]string) string {
+		if !strings.Contains(row["Email"], "@") {
+			return "INVALID_EMAIL_ADDRESS:Email: invalid email address: " + row["Email"] + ":Email --"
+		}
+		return ""
+	}
+	return server
+}
+
+func TestClassicIngest(t *testing.T) {
+	server := newClassicServer(t)
+
+	var polls int
+	result, err := server.classic().Ingest(context.Background(), "Contact", bulk.OperationInsert, strings.NewReader(contactsCSV), &bulk.ClassicOptions{
+		ConcurrencyMode: bulk.ConcurrencyModeSerial,
+		BatchSize:       2,
+		PollInterval:    time.Millisecond,
+		Progress: func(batches []*bulk.BatchInfo) {
+			polls++
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, "Closed", result.Job.State)
+	require.Equal(t, bulk.ConcurrencyModeSerial, result.Job.ConcurrencyMode)
+	require.Len(t, result.Batches, 3)
+	require.Equal(t, 1, server.inProgress, "serial batches are processed one at a time")
+	require.Greater(t, polls, 3)
+	require.Equal(t, 5, result.NumRecordsProcessed)
+	require.Equal(t, 1, result.NumRecordsFailed)
+
+	var successful []struct {
+		bulk.Result
+		LastName string
+	}
+	require.Nil(t, bulk.NewDecoder(result.Successful()).DecodeAll(&successful))
+	require.Len(t, successful, 4)
+	for i, name := range []string{"One", "Three", "Four", "Five"} {
+		require.Equal(t, name, successful[i].LastName)
+		require.True(t, successful[i].Created)
+		require.NotEmpty(t, successful[i].ID)
+	}
+
+	failed, err := result.Failed().ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, []string{"sf__Id", "sf__Error", "LastName", "Email"}, failed[0])
+	require.Len(t, failed, 2)
+	require.Equal(t, "INVALID_EMAIL_ADDRESS", bulk.ErrorCode(failed[1][1]))
+	require.Equal(t, []string{"Two", "invalid"}, failed[1][2:])
+}
+
+func TestClassicIngestBatchFailed(t *testing.T) {
+	server := newClassicServer(t)
+	server.failBatch = func(rows [][]string) string {
+		for _, row := range rows {
+			if row[0] == "Four" {
+				return "InvalidBatch : Field name not found : Emial"
+			}
+		}
+		return ""
+	}
+
+	result, err := server.classic().Ingest(context.Background(), "Contact", bulk.OperationInsert, strings.NewReader(contactsCSV), &bulk.ClassicOptions{
+		BatchSize:    2,
+		PollInterval: time.Millisecond,
+	})
+
+	require.True(t, errors.Is(err, bulk.ErrBatchFailed))
+	require.Len(t, result.Batches, 3)
+	require.Equal(t, "Failed", result.Batches[1].State)
+	require.Equal(t, 3, result.NumRecordsFailed)
+
+	var failed []bulk.Result
+	require.Nil(t, bulk.NewDecoder(result.Failed()).DecodeAll(&failed))
+	require.Len(t, failed, 3)
+	require.Equal(t, "INVALID_EMAIL_ADDRESS", failed[0].ErrorCode())
+	require.Equal(t, "InvalidBatch : Field name not found : Emial", failed[1].Error)
+	require.Equal(t, "InvalidBatch : Field name not found : Emial", failed[2].Error)
+}
+
+func TestClassicIngestCanceled(t *testing.T) {
+	server := newClassicServer(t)
+	server.pollsUntilComplete = 1000
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := server.classic().Ingest(ctx, "Contact", bulk.OperationInsert, strings.NewReader(contactsCSV), &bulk.ClassicOptions{
+		PollInterval: time.Millisecond,
+		Progress: func(batches []*bulk.BatchInfo) {
+			cancel()
+		},
+	})
+
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, "Aborted", server.job("750000000000001").info.State)
+}
+
+func newClassicExportServer(t *testing.T, n int) *fakeClassic {
+	server := newFakeClassic(t)
+	server.queryRows = [][]string{{"Id", "Name"}}
+	for i := 0; i < n; i++ {
+		server.queryRows = append(server.queryRows, []string{fmt.Sprintf("001%03d", i), fmt.Sprintf("Account %d", i)})
+	}
+	return server
+}
+
+func TestClassicExport(t *testing.T) {
+	server := newClassicExportServer(t, 7)
+	server.resultSize = 2
+
+	var buf bytes.Buffer
+	result, err := server.classic().Export(context.Background(), "Account", soql.Select("Id", "Name").From("Account"), &buf, &bulk.ClassicOptions{
+		PollInterval: time.Millisecond,
+		PKChunking:   &bulk.PKChunking{ChunkSize: 3},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, "chunkSize=3", server.job(result.Job.ID).pkChunking)
+	require.Len(t, result.Batches, 4)
+	require.Equal(t, "NotProcessed", result.Batches[0].State)
+	require.Equal(t, 5, result.Pages, "two results for each full chunk and one for the last")
+	require.Equal(t, 7, result.NumberOfRecords)
+	require.Equal(t, string(encodeRows(server.queryRows, "COMMA")), buf.String())
+	require.Equal(t, "Closed", server.job(result.Job.ID).info.State)
+}
+
+func TestClassicExportEmptyChunk(t *testing.T) {
+	server := newClassicExportServer(t, 4)
+	server.emptyChunks = 1
+
+	var buf bytes.Buffer
+	result, err := server.classic().Export(context.Background(), "Account", soql.Select("Id", "Name").From("Account"), &buf, &bulk.ClassicOptions{
+		PollInterval: time.Millisecond,
+		PKChunking:   &bulk.PKChunking{ChunkSize: 2},
+	})
+
+	require.Nil(t, err)
+	require.Len(t, result.Batches, 4)
+	require.Equal(t, 2, result.Pages, "the empty chunk has no results")
+	require.Equal(t, 4, result.NumberOfRecords)
+	require.Equal(t, string(encodeRows(server.queryRows, "COMMA")), buf.String())
+}
+
+func TestClassicExportQueryAll(t *testing.T) {
+	server := newClassicExportServer(t, 3)
+
+	var buf bytes.Buffer
+	result, err := server.classic().Export(context.Background(), "Account", soql.Select("Id", "Name").From("Account"), &buf, &bulk.ClassicOptions{
+		PollInterval: time.Millisecond,
+		QueryAll:     true,
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, "queryAll", string(server.job(result.Job.ID).info.Operation))
+	require.Equal(t, "", server.job(result.Job.ID).pkChunking)
+	require.Equal(t, 1, result.Pages)
+	require.Equal(t, string(encodeRows(server.queryRows, "COMMA")), buf.String())
+}
+
+func TestClassicJSONJob(t *testing.T) {
+	server := newFakeClassic(t)
+	classic := server.classic()
+
+	job, err := classic.CreateJob(&bulk.ClassicJobRequest{
+		Operation:   bulk.OperationUpsert,
+		Object:      "Account",
+		ContentType: bulk.ContentTypeJSON,
+		// upserts match records by an external ID
+		ExternalIDFieldName: "External_Id__c",
+	}, nil)
+
+	require.Nil(t, err)
+	require.Equal(t, "Open", job.State)
+	require.Equal(t, bulk.ContentTypeJSON, job.ContentType)
+	require.Equal(t, "External_Id__c", job.ExternalIDFieldName)
+
+	batch, err := classic.AddBatch(job, strings.NewReader(`[{"Name":"Acme","External_Id__c":"1"}]`))
+	require.Nil(t, err)
+	require.Equal(t, "Queued", batch.State)
+
+	job, err = classic.CloseJob(job)
+	require.Nil(t, err)
+	require.Equal(t, "Closed", job.State)
+
+	_, err = classic.AddBatch(job, strings.NewReader(`[]`))
+	require.NotNil(t, err, "a closed job accepts no batches")
+}
+
+func TestClassicSession(t *testing.T) {
+	server := newFakeClassic(t)
+	classic := bulk.NewClassic(requests.Sender(&fakeSender{server.server.URL}), server.server.URL, "50.0")
+
+	_, err := classic.CreateJob(&bulk.ClassicJobRequest{Operation: bulk.OperationInsert, Object: "Account", ContentType: bulk.ContentTypeCSV}, nil)
+	var requestErr *requests.RequestError
+	require.True(t, errors.As(err, &requestErr))
+	require.Equal(t, 401, requestErr.Code)
+}
+
+func TestPKChunkingHeader(t *testing.T) {
+	server := newClassicExportServer(t, 1)
+	for _, tc := range []struct {
+		chunking *bulk.PKChunking
+		header   string
+	}{
+		{&bulk.PKChunking{}, "true"},
+		{&bulk.PKChunking{ChunkSize: 250000, Parent: "Account"}, "chunkSize=250000; parent=Account"},
+		{&bulk.PKChunking{StartRow: "001000000000001"}, "startRow=001000000000001"},
+	} {
+		job, err := server.classic().CreateJob(&bulk.ClassicJobRequest{
+			Operation:   bulk.OperationQuery,
+			Object:      "AccountShare",
+			ContentType: bulk.ContentTypeCSV,
+		}, tc.chunking)
+
+		require.Nil(t, err)
+		require.Equal(t, tc.header, server.job(job.ID).pkChunking)
+	}
+}
@@ -0,0 +1,366 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/types"
+)
+
+// decode.go reads the CSV results of query and ingest jobs into Go structs, such as the types
+// generated by the codegen package, or into maps.
+
+// Result holds the columns which Salesforce prepends to the rows of the successful and failed
+// results of an ingest job. Embed it within a struct to decode them alongside the fields of each row:
+//
+//	type AccountResult struct {
+//		bulk.Result
+//		Name string `json:"Name"`
+//	}
+type Result struct {
+	ID      string `json:"sf__Id"`
+	Created bool   `json:"sf__Created"`
+	Error   string `json:"sf__Error"`
+}
+
+// Decoder reads the rows of a CSV into structs whose fields are named by their bulk or json tags,
+// or by their names, in the manner of an Encoder. Columns of related objects such as Owner.Name
+// are decoded into the matching field of a parent relationship struct, which is allocated as
+// needed. Columns without a matching field are ignored.
+//
+// Empty values, and NullValue, decode to null Nullable types and to the zero value of other types.
+type Decoder struct {
+	reader *csv.Reader
+	header []string
+	row    int
+	paths  map[reflect.Type][][][]int
+}
+
+// NewDecoder returns a Decoder reading from reader, whose first row is the header
+func NewDecoder(reader *csv.Reader) *Decoder {
+	return &Decoder{reader: reader, paths: make(map[reflect.Type][][][]int)}
+}
+
+// Unmarshal decodes every row of data, a CSV whose delimiter is d, into dst, see Decoder.DecodeAll
+func Unmarshal(data []byte, d delimiter, dst interface{}) error {
+	return NewDecoder(newCSVReader(bytes.NewReader(data), d)).DecodeAll(dst)
+}
+
+// Header returns the header row
+func (d *Decoder) Header() ([]string, error) {
+	if d.header != nil {
+		return d.header, nil
+	}
+
+	header, err := d.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	d.header = header
+	return header, nil
+}
+
+// Decode reads the next row into dst, a pointer to a struct or to a map[string]string. It returns
+// io.EOF once every row has been read.
+func (d *Decoder) Decode(dst interface{}) error {
+	if _, err := d.Header(); err != nil {
+		return err
+	}
+
+	row, err := d.reader.Read()
+	if err != nil {
+		return err
+	}
+	d.row++
+
+	if err := d.decode(reflect.ValueOf(dst), row); err != nil {
+		return fmt.Errorf("bulk.Decoder: row %d: %w", d.row, err)
+	}
+
+	return nil
+}
+
+// DecodeAll reads every remaining row into dst, a pointer to a slice of structs, of pointers to
+// structs, or of map[string]string
+func (d *Decoder) DecodeAll(dst interface{}) error {
+	slice := reflect.ValueOf(dst)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("bulk.Decoder: expected pointer to slice, not %T", dst)
+	}
+
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	for {
+		elem := reflect.New(indirectType(elemType))
+		if err := d.Decode(elem.Interface()); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if elemType.Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		slice.Set(reflect.Append(slice, elem))
+	}
+}
+
+// decode sets the fields of dst to the values of row
+func (d *Decoder) decode(dst reflect.Value, row []string) error {
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return fmt.Errorf("expected non-nil pointer, not %s", dst.Type())
+	}
+
+	dst = dst.Elem()
+	if dst.Kind() == reflect.Map && dst.Type().Key().Kind() == reflect.String && dst.Type().Elem().Kind() == reflect.String {
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(d.header)))
+		}
+
+		for i, column := range d.header {
+			if i < len(row) {
+				dst.SetMapIndex(reflect.ValueOf(column).Convert(dst.Type().Key()), reflect.ValueOf(row[i]).Convert(dst.Type().Elem()))
+			}
+		}
+		return nil
+	}
+
+	if dst.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct or map[string]string, not %s", dst.Type())
+	}
+
+	paths, ok := d.paths[dst.Type()]
+	if !ok {
+		paths = make([][][]int, len(d.header))
+		for i, column := range d.header {
+			paths[i], _ = columnPath(dst.Type(), column)
+		}
+		d.paths[dst.Type()] = paths
+	}
+
+	for i, path := range paths {
+		if path == nil || i >= len(row) {
+			continue
+		}
+
+		if err := parseValue(fieldByPath(dst, path), row[i]); err != nil {
+			return fmt.Errorf("%s: %w", d.header[i], err)
+		}
+	}
+
+	return nil
+}
+
+// columnPath returns the path to the field of t which column is decoded into
+func columnPath(t reflect.Type, column string) ([][]int, bool) {
+	for _, field := range flattenFields(t) {
+		name, ok := apiName(field)
+		if !ok {
+			continue
+		}
+
+		ft := indirectType(field.Type)
+		switch {
+		case isColumnType(field.Type):
+			if strings.EqualFold(name, column) {
+				return [][]int{field.Index}, true
+			}
+		case ft.Kind() != reflect.Struct:
+			continue
+		case strings.EqualFold(name, column) && strings.Contains(name, "."):
+			// a bulk tag naming the external ID field of a parent relationship
+			if nested, ok := fieldByAPIName(ft, name[strings.LastIndex(name, ".")+1:]); ok {
+				return [][]int{field.Index, nested.Index}, true
+			}
+		case len(column) > len(name) && strings.EqualFold(column[:len(name)+1], name+"."):
+			if nested, ok := columnPath(ft, column[len(name)+1:]); ok {
+				return append([][]int{field.Index}, nested...), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// fieldByPath returns the field at path within struct v, allocating nil pointers along the way
+func fieldByPath(v reflect.Value, path [][]int) reflect.Value {
+	for _, index := range path {
+		for _, i := range index {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+			v = v.Field(i)
+		}
+	}
+
+	return v
+}
+
+// parseValue sets v to the value str as Salesforce writes it within CSV data
+func parseValue(v reflect.Value, str string) error {
+	isNull := str == "" || str == NullValue
+	if v.Kind() == reflect.Ptr {
+		if isNull {
+			return nil
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return parseValue(v.Elem(), str)
+	}
+
+	var err error
+	switch value := v.Addr().Interface().(type) {
+	case *types.NullableString:
+		value.IsNull, value.IsHydrated, value.Value = isNull, !isNull, str
+		if isNull {
+			value.Value = ""
+		}
+		return nil
+	case *types.NullableBool:
+		value.IsNull, value.IsHydrated = isNull, !isNull
+		if !isNull {
+			value.Value, err = strconv.ParseBool(str)
+		}
+		return err
+	case *types.AlmostBool:
+		value.IsNull = isNull
+		if !isNull {
+			value.Value, err = strconv.ParseBool(str)
+		}
+		return err
+	case *types.NullableInt:
+		value.IsNull, value.IsHydrated = isNull, !isNull
+		if !isNull {
+			value.Value, err = strconv.Atoi(str)
+		}
+		return err
+	case *types.NullableInt64:
+		value.IsNull, value.IsHydrated = isNull, !isNull
+		if !isNull {
+			value.Value, err = strconv.ParseInt(str, 10, 64)
+		}
+		return err
+	case *types.NullableFloat64:
+		value.IsNull, value.IsHydrated = isNull, !isNull
+		if !isNull {
+			value.Value, err = strconv.ParseFloat(str, 64)
+		}
+		return err
+	case *types.Date:
+		value.IsNull, value.IsHydrated = isNull, !isNull
+		if !isNull {
+			var date types.Date
+			date, err = types.ParseDate(str, types.DefaultDateFormats...)
+			value.Value = date.Value
+		}
+		return err
+	case *types.Datetime:
+		value.IsNull, value.IsHydrated = isNull, !isNull
+		if !isNull {
+			value.Value, err = parseTime(str)
+		}
+		return err
+	case *types.MultiPicklist:
+		*value = nil
+		if !isNull {
+			*value = types.ParseMultiPicklist(str)
+		}
+		return nil
+	case *time.Time:
+		*value = time.Time{}
+		if !isNull {
+			*value, err = parseTime(str)
+		}
+		return err
+	case encoding.TextUnmarshaler:
+		if isNull {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		return value.UnmarshalText([]byte(str))
+	}
+
+	if v.Kind() == reflect.String || v.Kind() == reflect.Interface {
+		v.Set(reflect.ValueOf(str).Convert(v.Type()))
+		return nil
+	}
+
+	if isNull {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		v.SetBool(b)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(str, 10, v.Type().Bits())
+		v.SetInt(i)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(str, 10, v.Type().Bits())
+		v.SetUint(i)
+		return err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(str, v.Type().Bits())
+		v.SetFloat(f)
+		return err
+	}
+
+	return fmt.Errorf("cannot decode into %s", v.Type())
+}
+
+// parseTime parses a datetime in the formats of types.DefaultDatetimeFormats or RFC 3339, which
+// Salesforce uses for query results, i.e. 2021-03-04T05:06:07.000Z
+func parseTime(str string) (time.Time, error) {
+	datetime, err := types.ParseDatetime(str, append(types.DefaultDatetimeFormats, time.RFC3339Nano)...)
+	return datetime.Value, err
+}
+
+// delimiters are the characters which may separate the columns of job data
+var delimiters = []rune{',', '|', ';', '\t', '^', '`'}
+
+// newResultReader returns a reader for the results of a job, detecting the delimiter the job
+// was created with from the header row. API names cannot contain a delimiter, so the first
+// character following the first column name is the delimiter.
+func newResultReader(contents []byte) *csv.Reader {
+	reader := csv.NewReader(bytes.NewReader(contents))
+	line, _ := bufio.NewReader(bytes.NewReader(contents)).ReadString('\n')
+	if strings.HasPrefix(line, `"`) {
+		if end := strings.Index(line[1:], `"`); end >= 0 {
+			line = line[end+2:]
+		}
+	}
+
+	if i := strings.IndexFunc(line, func(r rune) bool { return runeIn(r, delimiters) }); i >= 0 {
+		reader.Comma = []rune(line[i:])[0]
+	}
+
+	return reader
+}
+
+func runeIn(r rune, runes []rune) bool {
+	for _, candidate := range runes {
+		if r == candidate {
+			return true
+		}
+	}
+	return false
+}
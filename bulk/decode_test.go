@@ -0,0 +1,106 @@
+package bulk_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeUser struct {
+	Name string `json:"Name"`
+}
+
+type decodeAccount struct {
+	ID           string                 `json:"Id"`
+	Name         types.NullableString   `json:"Name"`
+	Employees    types.NullableInt      `json:"NumberOfEmployees"`
+	Revenue      *types.NullableFloat64 `json:"AnnualRevenue"`
+	IsPartner    bool                   `json:"IsPartner"`
+	FoundedOn    types.Date             `json:"Founded__c"`
+	LastModified types.Datetime         `json:"LastModifiedDate"`
+	Regions      types.MultiPicklist    `json:"Regions__c"`
+	Owner        *decodeUser            `json:"Owner"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := `"Id"|"Name"|"NumberOfEmployees"|"AnnualRevenue"|"IsPartner"|"Founded__c"|"LastModifiedDate"|"Regions__c"|"Owner.Name"|"Ignored"` + "\n" +
+		`"001A"|"Acme"|"30"|"1.5"|"true"|"1980-01-02"|"2021-03-04T05:06:07.000Z"|"East;West"|"Jane"|"x"` + "\n" +
+		`"001B"|""|""|""|"false"|""|""|""|""|""` + "\n"
+
+	var accounts []*decodeAccount
+	require.Nil(t, bulk.Unmarshal([]byte(data), bulk.DelimiterPipe, &accounts))
+	require.Len(t, accounts, 2)
+
+	acme := accounts[0]
+	require.Equal(t, "001A", acme.ID)
+	require.Equal(t, types.NullableString{IsHydrated: true, Value: "Acme"}, acme.Name)
+	require.Equal(t, 30, acme.Employees.Value)
+	require.Equal(t, 1.5, acme.Revenue.Value)
+	require.True(t, acme.IsPartner)
+	require.Equal(t, time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC), acme.FoundedOn.Value)
+	require.True(t, acme.LastModified.Value.Equal(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)))
+	require.Equal(t, types.MultiPicklist{"East", "West"}, acme.Regions)
+	require.Equal(t, "Jane", acme.Owner.Name)
+
+	empty := accounts[1]
+	require.True(t, empty.Name.IsNull)
+	require.True(t, empty.Employees.IsNull)
+	require.Nil(t, empty.Revenue)
+	require.True(t, empty.FoundedOn.IsNull)
+	require.True(t, empty.LastModified.IsNull)
+	require.Equal(t, "", empty.Owner.Name)
+
+	var rows []map[string]string
+	require.Nil(t, bulk.Unmarshal([]byte(data), bulk.DelimiterPipe, &rows))
+	require.Equal(t, "Jane", rows[0]["Owner.Name"])
+
+	err := bulk.Unmarshal([]byte("NumberOfEmployees\nmany\n"), bulk.DelimiterComma, &accounts)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "row 1: NumberOfEmployees")
+}
+
+type accountResult struct {
+	bulk.Result
+	Name      string `json:"Name"`
+	Employees int    `json:"NumberOfEmployees"`
+}
+
+func TestDecodeIngestResults(t *testing.T) {
+	server := newFakeBulk(t)
+	server.fail = func(job *bulk.GetJobInfoResponse, row map[string]string) string {
+		if row["Name"] == "fail" {
+			return "REQUIRED_FIELD_MISSING:Required fields are missing: [Name]:Name --"
+		}
+		return ""
+	}
+
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader(accountsCSV), &bulk.IngestOptions{
+		ColumnDelimiter: bulk.DelimiterPipe,
+		PollInterval:    time.Millisecond,
+	})
+	require.Nil(t, err)
+
+	// the delimiter of the job is detected from the header
+	reader, err := bulk.GetSuccessfulRecords(server.builder(), result.Jobs[0].ID)
+	require.Nil(t, err)
+
+	var successful []accountResult
+	require.Nil(t, bulk.NewDecoder(reader).DecodeAll(&successful))
+	require.Len(t, successful, 2)
+	for _, row := range successful {
+		require.NotEmpty(t, row.ID)
+		require.True(t, row.Created)
+	}
+
+	var failed []accountResult
+	require.Nil(t, bulk.NewDecoder(result.Failed()).DecodeAll(&failed))
+	require.Len(t, failed, 1)
+	require.Equal(t, "fail", failed[0].Name)
+	require.Equal(t, 40, failed[0].Employees)
+	require.Contains(t, failed[0].Error, "REQUIRED_FIELD_MISSING")
+}
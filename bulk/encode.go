@@ -0,0 +1,428 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/types"
+)
+
+// encode.go writes Go structs, such as the types generated by the codegen package, as the CSV
+// data of an ingest job.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/datafiles_prepare_csv.htm
+
+// NullValue is written for a field which is explicitly null, clearing its value
+const NullValue = "#N/A"
+
+// ErrMixedTypes is returned when the values given to an Encoder are not all of the same struct type
+var ErrMixedTypes = errors.New("bulk.Encoder: every record must be of the same struct type")
+
+var textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Encoder writes structs as CSV rows whose columns are named by the bulk or json tag of each
+// field, or by the name of the field:
+//
+//	type Contact struct {
+//		FirstName types.NullableString `json:"FirstName"`
+//		LastName  string               `json:"LastName"`
+//		Account   *Account             `bulk:"Account.External_Id__c"`
+//		Internal  string               `bulk:"-"`
+//	}
+//
+// Nullable types which are null are written as NullValue. A parent relationship field is only
+// written when its external ID field is named, by a bulk tag or by Lookup, in which case the
+// column is "Relationship.ExternalIdField__c". Read-only compound fields such as types.Address
+// and child relationships are skipped.
+type Encoder struct {
+	writer  *csv.Writer
+	columns []string
+	lookups map[string]string
+	rowType reflect.Type
+	fields  []encoderField
+}
+
+// encoderField is a column along with the path to its value within a struct
+type encoderField struct {
+	name  string
+	index [][]int
+}
+
+// NewEncoder returns an Encoder which writes to w using the delimiter and line ending of a job
+func NewEncoder(w io.Writer, d delimiter, l lineEnding) *Encoder {
+	writer := csv.NewWriter(w)
+	writer.Comma = d.rune()
+	writer.UseCRLF = l == LineEndingCRLF
+	return &Encoder{writer: writer, lookups: make(map[string]string)}
+}
+
+// Columns restricts the columns written, and their order, to names. Use it to omit read-only
+// fields of generated types.
+func (e *Encoder) Columns(names ...string) *Encoder {
+	e.columns = names
+	return e
+}
+
+// Lookup writes the parent relationship field relationshipName as a lookup by the external ID
+// field externalIDField of the parent struct, i.e. Lookup("Account", "External_Id__c") writes
+// the column Account.External_Id__c.
+func (e *Encoder) Lookup(relationshipName string, externalIDField string) *Encoder {
+	e.lookups[relationshipName] = externalIDField
+	return e
+}
+
+// Encode writes v, which is a struct, a slice or array of structs, a channel of structs, or a
+// pointer to any of them. The header row is written before the first record.
+func (e *Encoder) Encode(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() != reflect.Struct {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		if err := e.init(value.Type().Elem()); err != nil {
+			return err
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			if err := e.encode(value.Index(i)); err != nil {
+				return fmt.Errorf("record %d: %w", i, err)
+			}
+		}
+	case reflect.Chan:
+		if err := e.init(value.Type().Elem()); err != nil {
+			return err
+		}
+
+		for i := 0; ; i++ {
+			record, ok := value.Recv()
+			if !ok {
+				break
+			}
+
+			if err := e.encode(record); err != nil {
+				return fmt.Errorf("record %d: %w", i, err)
+			}
+		}
+	default:
+		if err := e.encode(value); err != nil {
+			return err
+		}
+	}
+
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (e *Encoder) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// Marshal returns the CSV encoding of v, see Encoder
+func Marshal(v interface{}, d delimiter, l lineEnding) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, d, l).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// init derives the columns of t and writes the header row, once
+func (e *Encoder) init(t reflect.Type) error {
+	t = indirectType(t)
+	if e.rowType != nil {
+		if t != e.rowType {
+			return fmt.Errorf("%s: %w", t, ErrMixedTypes)
+		}
+		return nil
+	}
+
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("bulk.Encoder: expected struct, not %s", t)
+	}
+
+	fields := e.structFields(t)
+	if len(e.columns) > 0 {
+		byName := make(map[string]encoderField, len(fields))
+		for _, field := range fields {
+			byName[strings.ToLower(field.name)] = field
+		}
+
+		fields = fields[:0]
+		for _, column := range e.columns {
+			field, ok := byName[strings.ToLower(column)]
+			if !ok {
+				return fmt.Errorf("bulk.Encoder: %s has no field for column %s", t, column)
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	if len(fields) == 0 {
+		return fmt.Errorf("bulk.Encoder: %s has no fields to encode", t)
+	}
+
+	e.rowType, e.fields = t, fields
+	header := make([]string, 0, len(fields))
+	for _, field := range fields {
+		header = append(header, field.name)
+	}
+
+	return e.writer.Write(header)
+}
+
+// encode writes a single record
+func (e *Encoder) encode(value reflect.Value) error {
+	if err := e.init(value.Type()); err != nil {
+		return err
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return errors.New("bulk.Encoder: cannot encode a nil record")
+		}
+		value = value.Elem()
+	}
+
+	row := make([]string, 0, len(e.fields))
+	for _, field := range e.fields {
+		str, err := formatField(value, field.index)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.name, err)
+		}
+		row = append(row, str)
+	}
+
+	return e.writer.Write(row)
+}
+
+// structFields returns the columns of t
+func (e *Encoder) structFields(t reflect.Type) []encoderField {
+	var fields []encoderField
+	for _, field := range flattenFields(t) {
+		name, ok := apiName(field)
+		if !ok {
+			continue
+		}
+
+		ft := indirectType(field.Type)
+		switch {
+		case isColumnType(field.Type):
+			fields = append(fields, encoderField{name, [][]int{field.Index}})
+		case ft.Kind() != reflect.Struct:
+			continue
+		case strings.Contains(name, "."):
+			// a bulk tag naming the external ID field of a parent relationship
+			if nested, ok := fieldByAPIName(ft, name[strings.LastIndex(name, ".")+1:]); ok {
+				fields = append(fields, encoderField{name, [][]int{field.Index, nested.Index}})
+			}
+		case e.lookups[name] != "":
+			if nested, ok := fieldByAPIName(ft, e.lookups[name]); ok {
+				fields = append(fields, encoderField{name + "." + e.lookups[name], [][]int{field.Index, nested.Index}})
+			}
+		}
+	}
+
+	return fields
+}
+
+// flattenFields returns the exported fields of t, flattening embedded structs as encoding/json does
+func flattenFields(t reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Tag.Get("bulk") == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			for _, embedded := range flattenFields(indirectType(field.Type)) {
+				embedded.Index = append([]int{i}, embedded.Index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+
+		if field.PkgPath == "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// apiName returns the column name of field from its bulk tag, json tag, or name. Fields tagged
+// "-" and record attributes are skipped.
+func apiName(field reflect.StructField) (string, bool) {
+	name := field.Name
+	for _, key := range []string{"json", "bulk"} {
+		tag := strings.Split(field.Tag.Get(key), ",")[0]
+		if tag == "-" {
+			return "", false
+		}
+
+		if tag != "" {
+			name = tag
+		}
+	}
+
+	return name, !strings.EqualFold(name, "attributes")
+}
+
+// fieldByAPIName returns the field of t with the given column name
+func fieldByAPIName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for _, field := range flattenFields(t) {
+		if fieldName, ok := apiName(field); ok && strings.EqualFold(fieldName, name) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// isColumnType reports whether t is a single column rather than a relationship
+func isColumnType(t reflect.Type) bool {
+	t = indirectType(t)
+	switch t {
+	case reflect.TypeOf(types.Address{}):
+		return false
+	case reflect.TypeOf(types.MultiPicklist{}), reflect.TypeOf(time.Time{}):
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return isNullable(t) || t.Implements(textMarshaler) || reflect.PtrTo(t).Implements(textMarshaler)
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan, reflect.Func:
+		return false
+	}
+
+	return true
+}
+
+// isNullable reports whether t is one of the nullable types of the types package
+func isNullable(t reflect.Type) bool {
+	if t.PkgPath() != reflect.TypeOf(types.Date{}).PkgPath() {
+		return false
+	}
+
+	field, ok := t.FieldByName("IsNull")
+	return ok && field.Type.Kind() == reflect.Bool
+}
+
+// formatField formats the value at the given path of struct v. A nil pointer along the path is
+// written as an empty value, leaving the field unchanged.
+func formatField(v reflect.Value, path [][]int) (string, error) {
+	for _, index := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+
+	return formatValue(v)
+}
+
+// formatValue formats a single value as Salesforce expects it within CSV data
+func formatValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case types.NullableString:
+		return nullable(value.IsNull, value.Value), nil
+	case types.NullableBool:
+		return hydrated(value.IsNull, value.IsHydrated || value.Value, strconv.FormatBool(value.Value)), nil
+	case types.AlmostBool:
+		return nullable(value.IsNull, strconv.FormatBool(value.Value)), nil
+	case types.NullableInt:
+		return hydrated(value.IsNull, value.IsHydrated || value.Value != 0, strconv.Itoa(value.Value)), nil
+	case types.NullableInt64:
+		return hydrated(value.IsNull, value.IsHydrated || value.Value != 0, strconv.FormatInt(value.Value, 10)), nil
+	case types.NullableFloat64:
+		return hydrated(value.IsNull, value.IsHydrated || value.Value != 0, strconv.FormatFloat(value.Value, 'f', -1, 64)), nil
+	case types.Date:
+		return nullable(value.IsNull, formatTime(value.Value, types.ISODate)), nil
+	case types.Datetime:
+		return nullable(value.IsNull, formatTime(value.Value, types.DefaultDatetimeFormats[0])), nil
+	case types.MultiPicklist:
+		return value.String(), nil
+	case time.Time:
+		return formatTime(value, types.DefaultDatetimeFormats[0]), nil
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		return string(text), err
+	}
+
+	if v.CanAddr() {
+		if marshaler, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			text, err := marshaler.MarshalText()
+			return string(text), err
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+
+	return "", fmt.Errorf("cannot encode %s", v.Type())
+}
+
+// hydrated returns NullValue if isNull is true, str if isSet is true, or an empty value otherwise.
+// The zero value of a nullable number or checkbox was never set, and writing it as 0 or false
+// would overwrite the field in Salesforce. Values created by the types.New* constructors or
+// unmarshaled from a response are set.
+func hydrated(isNull bool, isSet bool, str string) string {
+	if !isNull && !isSet {
+		return ""
+	}
+	return nullable(isNull, str)
+}
+
+// nullable returns NullValue if isNull is true, or str otherwise
+func nullable(isNull bool, str string) string {
+	if isNull {
+		return NullValue
+	}
+	return str
+}
+
+// formatTime formats t, or returns an empty value if t is zero
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// indirectType dereferences pointer types
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,92 @@
+package bulk_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type encodeAccount struct {
+	ExternalID string `json:"External_Id__c"`
+}
+
+type encodeContact struct {
+	Attributes     *types.Attributes     `json:"attributes"`
+	LastName       string                `json:"LastName"`
+	FirstName      types.NullableString  `json:"FirstName"`
+	Employees      types.NullableInt     `json:"NumberOfEmployees__c"`
+	Score          types.NullableFloat64 `json:"Score__c"`
+	DoNotCall      types.AlmostBool      `json:"DoNotCall"`
+	Birthdate      types.Date            `json:"Birthdate"`
+	LastActivity   *types.Datetime       `json:"LastActivity__c"`
+	Interests      types.MultiPicklist   `json:"Interests__c"`
+	MailingAddress types.Address         `json:"MailingAddress"`
+	Account        *encodeAccount        `json:"Account" bulk:"Account.External_Id__c"`
+	ReportsTo      *encodeAccount        `json:"ReportsTo"`
+	Internal       string                `json:"-"`
+}
+
+func TestEncoder(t *testing.T) {
+	activity := types.NewDatetime(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	contacts := []*encodeContact{
+		{
+			LastName:     "Smith, Jr.",
+			FirstName:    types.NewString("John"),
+			Employees:    types.NewInt(0),
+			Score:        types.NewFloat64(1.5),
+			DoNotCall:    types.AlmostBool{Value: true},
+			Birthdate:    types.NewDate(time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC)),
+			LastActivity: &activity,
+			Interests:    types.MultiPicklist{"Golf", "Tennis"},
+			Account:      &encodeAccount{ExternalID: "A-1"},
+		},
+		{
+			LastName:  "Doe",
+			FirstName: types.NullableString{IsNull: true},
+			Birthdate: types.Date{IsNull: true},
+		},
+	}
+
+	data, err := bulk.Marshal(contacts, bulk.DelimiterPipe, bulk.LineEndingCRLF)
+	require.Nil(t, err)
+	require.Equal(t, "LastName|FirstName|NumberOfEmployees__c|Score__c|DoNotCall|Birthdate|LastActivity__c|Interests__c|Account.External_Id__c\r\n"+
+		"Smith, Jr.|John|0|1.5|true|1980-01-02|2021-03-04T05:06:07.000+0000|Golf;Tennis|A-1\r\n"+
+		"Doe|#N/A|||false|#N/A|||\r\n", string(data))
+}
+
+func TestEncoderSetZeroValues(t *testing.T) {
+	type account struct {
+		Active    types.NullableBool    `json:"Active__c"`
+		Employees types.NullableInt64   `json:"NumberOfEmployees"`
+		Revenue   types.NullableFloat64 `json:"AnnualRevenue"`
+	}
+
+	data, err := bulk.Marshal([]account{
+		{Active: types.NewBool(false), Employees: types.NewInt64(0), Revenue: types.NewFloat64(0)},
+		{},
+	}, bulk.DelimiterComma, bulk.LineEndingLF)
+	require.Nil(t, err)
+	require.Equal(t, "Active__c,NumberOfEmployees,AnnualRevenue\nfalse,0,0\n,,\n", string(data))
+}
+
+func TestEncoderColumnsAndLookup(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := bulk.NewEncoder(&buf, bulk.DelimiterComma, bulk.LineEndingLF).
+		Lookup("ReportsTo", "External_Id__c").
+		Columns("ReportsTo.External_Id__c", "LastName")
+
+	records := make(chan encodeContact, 2)
+	records <- encodeContact{LastName: "Smith", ReportsTo: &encodeAccount{ExternalID: "C-1"}}
+	records <- encodeContact{LastName: "Doe"}
+	close(records)
+
+	require.Nil(t, encoder.Encode(records))
+	require.Equal(t, "ReportsTo.External_Id__c,LastName\nC-1,Smith\n,Doe\n", buf.String())
+
+	require.NotNil(t, encoder.Encode(encodeAccount{}))
+	require.NotNil(t, bulk.NewEncoder(&buf, bulk.DelimiterComma, bulk.LineEndingLF).Columns("Missing").Encode(encodeContact{}))
+}
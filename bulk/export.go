@@ -0,0 +1,327 @@
+package bulk
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/requests"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+)
+
+// export.go runs a Bulk API 2.0 query job and streams every page of its results, following the
+// locator of each page to the next, without holding a page in memory.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/query_get_job_results.htm
+
+// ExportOptions configures Export. The zero value creates a comma delimited, LF terminated job
+// which is polled as IngestOptions are, downloads one page at a time, and deletes the job once
+// its results are downloaded.
+type ExportOptions struct {
+	// ColumnDelimiter of the results
+	ColumnDelimiter delimiter
+	// LineEnding of the results
+	LineEnding lineEnding
+	// PollInterval is the initial delay between requests for the state of the job
+	PollInterval time.Duration
+	// MaxPollInterval is the greatest delay between requests for the state of the job
+	MaxPollInterval time.Duration
+	// Progress, if not nil, is called with the state of the job after each request for it
+	Progress func(job *GetJobInfoResponse)
+	// MaxRecords is the greatest number of records in each page, chosen by Salesforce by default
+	MaxRecords int
+	// Concurrency is the number of pages requested at once. As each page holds the locator of
+	// the next, a page is requested as soon as the headers of the previous page arrive and its
+	// body is read once every earlier page has been written.
+	Concurrency int
+	// Downloaded, if not nil, is called after each page is written with the number of pages and
+	// records written so far
+	Downloaded func(pages int, records int)
+	// KeepJob leaves the job in place rather than deleting it once its results are downloaded
+	KeepJob bool
+	// CheckpointKey, if not empty, records the job and the locator of the next page in
+	// CheckpointStore under this key. An export given the same key resumes the job of an earlier
+	// run which did not finish from the first page it did not write, so the writer should append
+	// to the output of that run. The job is kept after a failure so that it may be resumed, and
+	// the checkpoint is deleted once the export succeeds.
+	CheckpointKey string
+	// CheckpointStore is a FileCheckpointStore within os.TempDir() by default
+	CheckpointStore CheckpointStore
+}
+
+// withDefaults returns a copy of options with unset values defaulted
+func (o *ExportOptions) withDefaults() ExportOptions {
+	var options ExportOptions
+	if o != nil {
+		options = *o
+	}
+
+	if options.ColumnDelimiter == "" {
+		options.ColumnDelimiter = DelimiterComma
+	}
+
+	if options.LineEnding == "" {
+		options.LineEnding = LineEndingLF
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+
+	return options
+}
+
+// ExportResult summarizes a completed export
+type ExportResult struct {
+	// Job is the final state of the query job
+	Job *GetJobInfoResponse
+	// Pages is the number of pages of results written
+	Pages int
+	// NumberOfRecords is the number of records written
+	NumberOfRecords int
+}
+
+// PageFunc receives the CSV of each page of results, including its header row, in order. body
+// is streamed from the response and is only valid until PageFunc returns. page counts from 0,
+// or from the pages written by an earlier run when an export is resumed.
+type PageFunc func(page int, body io.Reader) error
+
+// Export creates a query job for q, waits for it to complete, and writes every page of its
+// results to w as a single CSV with one header row:
+//
+//	file, _ := os.Create("accounts.csv")
+//	result, err := bulk.Export(ctx, requests.Sender(salesforce.DefaultClient), soql.Select("Id", "Name").From("Account"), file, nil)
+//
+// If ctx is canceled while the job is running it is aborted. Unless options.KeepJob is true the
+// job is deleted afterward.
+func Export(ctx context.Context, builder requests.Builder, q soql.Builder, w io.Writer, options *ExportOptions) (*ExportResult, error) {
+	return ExportPages(ctx, builder, q, writePages(w), options)
+}
+
+// writePages returns a PageFunc writing each page to w, skipping the header row repeated by
+// every page after the first
+func writePages(w io.Writer) PageFunc {
+	return func(page int, body io.Reader) error {
+		if page > 0 {
+			reader := bufio.NewReader(body)
+			if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+				return err
+			}
+			body = reader
+		}
+
+		_, err := io.Copy(w, body)
+		return err
+	}
+}
+
+// ExportPages is Export with each page of results given to fn, for instance to write each page
+// to its own file
+func ExportPages(ctx context.Context, builder requests.Builder, q soql.Builder, fn PageFunc, options *ExportOptions) (result *ExportResult, err error) {
+	opts := options.withDefaults()
+	builder = builder.Context(ctx)
+
+	cp, err := loadCheckpoint(opts.CheckpointStore, opts.CheckpointKey, "", string(QueryOperation))
+	if err != nil {
+		return nil, err
+	}
+
+	saved, resumed := cp.job(0)
+	if !resumed {
+		created, err := CreateQuery(builder, opts.ColumnDelimiter, opts.LineEnding, q)
+		if err != nil {
+			return nil, fmt.Errorf("creating query job: %w", err)
+		}
+
+		saved = CheckpointJob{ID: created.ID, State: created.State}
+		if err := cp.setJob(saved); err != nil {
+			abortQuery(builder, created.ID)
+			return nil, err
+		}
+	}
+
+	defer func() {
+		if !opts.KeepJob && (cp == nil || err == nil) {
+			DeleteQuery(builder.Context(context.Background()), saved.ID)
+		}
+	}()
+
+	// storeErr is the first error recording the state of the job with cp
+	var storeErr error
+	job, err := waitFor(ctx, builder, saved.ID, &IngestOptions{
+		PollInterval:    opts.PollInterval,
+		MaxPollInterval: opts.MaxPollInterval,
+		Progress: func(job *GetJobInfoResponse) {
+			if err := cp.setState(0, job.State); err != nil && storeErr == nil {
+				storeErr = err
+			}
+			if opts.Progress != nil {
+				opts.Progress(job)
+			}
+		},
+	}, GetQuery, abortQuery)
+
+	if err == nil {
+		err = storeErr
+	}
+
+	checkpoint := cp.snapshot()
+	result = &ExportResult{Job: job, Pages: checkpoint.Pages, NumberOfRecords: checkpoint.NumberOfRecords}
+	if err != nil {
+		return result, err
+	}
+
+	// every page was written by an earlier run
+	if resumed && checkpoint.Pages > 0 && checkpoint.Locator == "" {
+		return result, cp.remove()
+	}
+
+	if err := result.download(ctx, builder, fn, checkpoint.Locator, opts, cp); err != nil {
+		return result, err
+	}
+
+	return result, cp.remove()
+}
+
+// resultPage is the response for a page of results whose body has not yet been read
+type resultPage struct {
+	response *http.Response
+	body     io.Reader
+	records  int
+	next     string
+	err      error
+}
+
+// close releases the response of p
+func (p *resultPage) close() {
+	if p.response != nil {
+		p.response.Body.Close()
+	}
+}
+
+// download requests each page of results from locator onward as soon as its locator is known,
+// holding at most opts.Concurrency responses open, and gives their bodies to fn in order
+func (r *ExportResult) download(ctx context.Context, builder requests.Builder, fn PageFunc, locator string, opts ExportOptions, cp *checkpointer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	builder = builder.Context(ctx)
+
+	pages := make(chan *resultPage, opts.Concurrency)
+	sem := make(chan struct{}, opts.Concurrency)
+	go func() {
+		defer close(pages)
+
+		for {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			page := getResultPage(builder, r.Job.ID, locator, opts.MaxRecords)
+			pages <- page
+			if page.err != nil || page.next == "" {
+				return
+			}
+			locator = page.next
+		}
+	}()
+
+	defer func() {
+		cancel()
+		for page := range pages {
+			page.close()
+		}
+	}()
+
+	for page := range pages {
+		if page.err != nil {
+			return fmt.Errorf("getting results of job %s: %w", r.Job.ID, page.err)
+		}
+
+		err := fn(r.Pages, page.body)
+		page.close()
+		<-sem
+
+		if err != nil {
+			return fmt.Errorf("writing page %d of job %s: %w", r.Pages, r.Job.ID, err)
+		}
+
+		r.Pages++
+		r.NumberOfRecords += page.records
+		err = cp.update(func(checkpoint *Checkpoint) {
+			checkpoint.Locator, checkpoint.Pages, checkpoint.NumberOfRecords = page.next, r.Pages, r.NumberOfRecords
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if opts.Downloaded != nil {
+			opts.Downloaded(r.Pages, r.NumberOfRecords)
+		}
+	}
+
+	return nil
+}
+
+// getResultPage requests a page of the results of a query job
+func getResultPage(builder requests.Builder, jobID string, locator string, maxRecords int) *resultPage {
+	builder = builder.
+		Method(http.MethodGet).
+		URL(fmt.Sprintf("%s/%s/results", queryEndpoint, jobID))
+
+	if locator != "" {
+		builder = builder.Param("locator", locator)
+	}
+
+	if maxRecords > 0 {
+		builder = builder.Param("maxRecords", strconv.Itoa(maxRecords))
+	}
+
+	page := getPage(builder)
+	if page.err != nil {
+		return page
+	}
+
+	page.records, _ = strconv.Atoi(page.response.Header.Get("Sforce-NumberOfRecords"))
+	// the locator of the last page is the string "null"
+	if next := page.response.Header.Get("Sforce-Locator"); next != "null" {
+		page.next = next
+	}
+
+	return page
+}
+
+// getPage sends the request of builder, returning its response unread unless it failed
+func getPage(builder requests.Builder) *resultPage {
+	response, err := builder.Response()
+	if err != nil {
+		return &resultPage{err: err}
+	}
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		_, err := requests.ReadAndCloseResponse(response)
+		return &resultPage{err: err}
+	}
+
+	page := &resultPage{response: response, body: response.Body}
+	if response.Header.Get("Content-Encoding") == "gzip" {
+		if page.body, err = gzip.NewReader(response.Body); err != nil {
+			page.close()
+			return &resultPage{err: err}
+		}
+	}
+
+	return page
+}
+
+// abortQuery makes a best effort attempt to abort a query job, regardless of whether the context
+// of builder was canceled
+func abortQuery(builder requests.Builder, jobID string) {
+	UpdateQuery(builder.Context(context.Background()), jobID, &UpdateJobRequest{State: JobStateAborted})
+}
@@ -0,0 +1,81 @@
+package bulk_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportServer(t *testing.T, n int) *fakeBulk {
+	server := newFakeBulk(t)
+	server.queryRows = [][]string{{"Id", "Name"}}
+	for i := 0; i < n; i++ {
+		server.queryRows = append(server.queryRows, []string{fmt.Sprintf("001%03d", i), fmt.Sprintf("Account %d", i)})
+	}
+	return server
+}
+
+func TestExport(t *testing.T) {
+	server := newExportServer(t, 7)
+
+	var buf bytes.Buffer
+	var downloaded []int
+	result, err := bulk.Export(context.Background(), server.builder(), soql.Select("Id", "Name").From("Account"), &buf, &bulk.ExportOptions{
+		ColumnDelimiter: bulk.DelimiterPipe,
+		PollInterval:    time.Millisecond,
+		MaxRecords:      3,
+		Concurrency:     2,
+		Downloaded: func(pages int, records int) {
+			downloaded = append(downloaded, records)
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, "JobComplete", result.Job.State)
+	require.Equal(t, 3, result.Pages)
+	require.Equal(t, 7, result.NumberOfRecords)
+	require.Equal(t, []int{3, 6, 7}, downloaded)
+	require.Equal(t, string(encodeRows(server.queryRows, "PIPE")), buf.String())
+	require.Nil(t, server.job(result.Job.ID), "the job is deleted")
+}
+
+func TestExportPages(t *testing.T) {
+	server := newExportServer(t, 5)
+	server.pageSize = 2
+
+	var pages []string
+	result, err := bulk.ExportPages(context.Background(), server.builder(), soql.Select("Id", "Name").From("Account"), func(page int, body io.Reader) error {
+		contents, err := ioutil.ReadAll(body)
+		pages = append(pages, string(contents))
+		return err
+	}, &bulk.ExportOptions{PollInterval: time.Millisecond, KeepJob: true})
+
+	require.Nil(t, err)
+	require.Len(t, pages, 3)
+	for _, page := range pages {
+		require.True(t, strings.HasPrefix(page, "Id,Name\n"), "every page has a header")
+	}
+	require.NotNil(t, server.job(result.Job.ID), "the job is kept")
+
+	errPage := errors.New("disk full")
+	result, err = bulk.ExportPages(context.Background(), server.builder(), soql.Select("Id", "Name").From("Account"), func(page int, body io.Reader) error {
+		if page == 1 {
+			return errPage
+		}
+		return nil
+	}, &bulk.ExportOptions{PollInterval: time.Millisecond, Concurrency: 3})
+
+	require.True(t, errors.Is(err, errPage))
+	require.Equal(t, 1, result.Pages)
+	require.Nil(t, server.job(result.Job.ID), "the job is deleted")
+}
@@ -1,7 +1,6 @@
 package bulk
 
 import (
-	"bytes"
 	"encoding/csv"
 	"fmt"
 	"io"
@@ -67,11 +66,16 @@ func UpdateJob(builder requests.Builder, jobID string, update *UpdateJobRequest)
 // DeleteJob ...
 // https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/delete_job.htm
 func DeleteJob(builder requests.Builder, jobID string) error {
-	_, err := builder.
+	response, err := builder.
 		Method(http.MethodDelete).
 		URL(fmt.Sprintf("%s/%s", ingestEndpoint, jobID)).
 		Response()
 
+	if err != nil {
+		return err
+	}
+
+	_, err = requests.ReadAndCloseResponse(response)
 	return err
 }
 
@@ -119,60 +123,33 @@ func GetJob(builder requests.Builder, jobID string) (job *GetJobInfoResponse, er
 // GetSuccessfulRecords ...
 // https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/get_job_successful_results.htm
 func GetSuccessfulRecords(builder requests.Builder, jobID string) (*csv.Reader, error) {
-	response, err := builder.
-		Method(http.MethodGet).
-		URL(fmt.Sprintf("%s/%s/successfulResults", ingestEndpoint, jobID)).
-		Response()
-
+	contents, err := getResults(builder, jobID, "successfulResults")
 	if err != nil {
 		return nil, err
 	}
 
-	contents, err := requests.ReadAndCloseResponse(response)
-	if err != nil {
-		return nil, err 
-	}
-
-	return csv.NewReader(bytes.NewBuffer(contents)), nil
+	return newResultReader(contents), nil
 }
 
 // GetFailedRecords ...
 // https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/get_job_failed_results.htm
 func GetFailedRecords(builder requests.Builder, jobID string) (*csv.Reader, error) {
-	response, err := builder.
-		Method(http.MethodGet).
-		URL(fmt.Sprintf("%s/%s/failedResults", ingestEndpoint, jobID)).
-		Response()
-
+	contents, err := getResults(builder, jobID, "failedResults")
 	if err != nil {
 		return nil, err
 	}
 
-	contents, err := requests.ReadAndCloseResponse(response)
-	if err != nil {
-		return nil, err 
-	}
-
-	return csv.NewReader(bytes.NewBuffer(contents)), nil
+	return newResultReader(contents), nil
 }
 
 // GetUnprocessedJobs ...
 // https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/get_job_unprocessed_results.htm
 func GetUnprocessedJobs(builder requests.Builder, jobID string) (*csv.Reader, error) {
-	response, err := builder.
-		Method(http.MethodGet).
-		URL(fmt.Sprintf("%s/%s/unprocessedrecords", ingestEndpoint, jobID)).
-		Response()
-
+	contents, err := getResults(builder, jobID, "unprocessedrecords")
 	if err != nil {
 		return nil, err
 	}
 
-	contents, err := requests.ReadAndCloseResponse(response)
-	if err != nil {
-		return nil, err 
-	}
-
-	return csv.NewReader(bytes.NewBuffer(contents)), nil
+	return newResultReader(contents), nil
 }
 
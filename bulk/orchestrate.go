@@ -0,0 +1,593 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/requests"
+)
+
+// orchestrate.go runs the complete lifecycle of a Bulk API 2.0 ingest job: creating it,
+// uploading its data, closing it, polling until Salesforce finishes processing, and collecting
+// its results.
+
+const (
+	// DefaultPollInterval is the initial delay between requests for the state of a job
+	DefaultPollInterval = 2 * time.Second
+	// DefaultMaxPollInterval is the greatest delay between requests for the state of a job
+	DefaultMaxPollInterval = 30 * time.Second
+)
+
+var (
+	// ErrJobFailed is returned when a job finishes in the Failed state
+	ErrJobFailed = errors.New("bulk job failed")
+	// ErrJobAborted is returned when a job finishes in the Aborted state
+	ErrJobAborted = errors.New("bulk job aborted")
+)
+
+// JobError describes a job which did not complete
+type JobError struct {
+	Job *GetJobInfoResponse
+	Err error
+}
+
+// Error ...
+func (e *JobError) Error() string {
+	if e.Job.ErrorMessage != "" {
+		return fmt.Sprintf("%s %s: %s", e.Err, e.Job.ID, e.Job.ErrorMessage)
+	}
+	return fmt.Sprintf("%s %s", e.Err, e.Job.ID)
+}
+
+// Unwrap ...
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+// IngestOptions configures Ingest. The zero value creates comma delimited, LF terminated jobs
+// which are polled every DefaultPollInterval, doubling up to DefaultMaxPollInterval, and run one
+// at a time.
+type IngestOptions struct {
+	// ExternalIDFieldName is required for upserts
+	ExternalIDFieldName string
+	// AssignmentRuleID is the assignment rule to run for a Case or Lead
+	AssignmentRuleID string
+	// ColumnDelimiter of the uploaded data and of the results
+	ColumnDelimiter delimiter
+	// LineEnding of the uploaded data
+	LineEnding lineEnding
+	// PollInterval is the initial delay between requests for the state of a job
+	PollInterval time.Duration
+	// MaxPollInterval is the greatest delay between requests for the state of a job
+	MaxPollInterval time.Duration
+	// Progress, if not nil, is called with the state of a job after each request for it. It is
+	// called concurrently when Concurrency is greater than 1.
+	Progress func(job *GetJobInfoResponse)
+	// MaxUploadBytes is the size at which the data is split into another job, DefaultMaxUploadBytes
+	// by default. It is limited to the greatest size whose base64 encoding is within
+	// MaxEncodedUploadBytes.
+	MaxUploadBytes int
+	// MaxUploadCharacters is the number of characters at which the data is split into another
+	// job, MaxUploadCharacters by default
+	MaxUploadCharacters int
+	// Concurrency is the number of jobs run at once when the data is split
+	Concurrency int
+	// CheckpointKey, if not empty, records the jobs of the ingest in CheckpointStore under this
+	// key. An ingest given the same key and input resumes the jobs of an earlier run which did
+	// not finish rather than submitting their data again, including the jobs of retries. The
+	// checkpoint is deleted once the ingest and its retries succeed.
+	CheckpointKey string
+	// CheckpointStore is a FileCheckpointStore within os.TempDir() by default
+	CheckpointStore CheckpointStore
+	// Retries is the number of times rows which failed with a retryable error are ingested again
+	// by a new job
+	Retries int
+	// RetryableErrors are the status codes of sf__Error which are retried, DefaultRetryableErrors
+	// by default
+	RetryableErrors []string
+	// RetryInterval is the delay before the first retry, which doubles for each retry after it,
+	// DefaultRetryInterval by default
+	RetryInterval time.Duration
+	// CorrelationColumn, if not empty, is the column whose value identifies each row of the input
+	// within IngestResult.Rows, such as the external ID field of an upsert. If the input has no
+	// such column one is appended holding the line number of each row. Salesforce rejects
+	// columns which are not fields of the object, so an appended column must name a field which
+	// is safe to write, such as a text field reserved for the purpose.
+	CorrelationColumn string
+}
+
+// withDefaults returns a copy of options with unset values defaulted
+func (o *IngestOptions) withDefaults() IngestOptions {
+	var options IngestOptions
+	if o != nil {
+		options = *o
+	}
+
+	if options.ColumnDelimiter == "" {
+		options.ColumnDelimiter = DelimiterComma
+	}
+
+	if options.LineEnding == "" {
+		options.LineEnding = LineEndingLF
+	}
+
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+
+	if options.MaxPollInterval < options.PollInterval {
+		options.MaxPollInterval = DefaultMaxPollInterval
+		if options.MaxPollInterval < options.PollInterval {
+			options.MaxPollInterval = options.PollInterval
+		}
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+
+	if options.RetryableErrors == nil {
+		options.RetryableErrors = DefaultRetryableErrors
+	}
+
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = DefaultRetryInterval
+	}
+
+	return options
+}
+
+// IngestResult summarizes a completed ingest. When the data was split across several jobs their
+// results are merged in the order of the input.
+type IngestResult struct {
+	// Jobs are the final states of the jobs created by the ingest, in the order of the input
+	Jobs []*GetJobInfoResponse
+	// NumRecordsProcessed is the number of records of the input processed
+	NumRecordsProcessed int
+	// NumRecordsFailed is the number of records which failed, including those which failed again
+	// when retried
+	NumRecordsFailed int
+	// NumRecordsRecovered is the number of records which failed with a retryable error and
+	// succeeded when retried
+	NumRecordsRecovered int
+	// Retries is the number of times failed records were retried
+	Retries int
+	// Rows are the outcomes of each row of the input in order when options.CorrelationColumn is set
+	Rows []*RowResult
+
+	delimiter   delimiter
+	keys        []string
+	successful  []byte
+	recovered   []byte
+	failed      []byte
+	unprocessed []byte
+}
+
+// Successful returns the successfully processed rows prefixed by the sf__Id and sf__Created
+// columns. Rows recovered by retries follow the others.
+func (r *IngestResult) Successful() *csv.Reader {
+	return newCSVReader(bytes.NewReader(r.successful), r.delimiter)
+}
+
+// Failed returns the rows which failed prefixed by the sf__Id and sf__Error columns. After
+// retries these are the rows which failed with permanent errors, such as validation rules and
+// duplicates, along with those which failed on every retry.
+func (r *IngestResult) Failed() *csv.Reader {
+	return newCSVReader(bytes.NewReader(r.failed), r.delimiter)
+}
+
+// Unprocessed returns the rows which were not processed, such as those of an aborted job
+func (r *IngestResult) Unprocessed() *csv.Reader {
+	return newCSVReader(bytes.NewReader(r.unprocessed), r.delimiter)
+}
+
+// Ingest creates a job to perform operation upon object, uploads the CSV data of body, and waits
+// for Salesforce to process it before collecting the results:
+//
+//	result, err := bulk.Ingest(ctx, requests.Sender(salesforce.DefaultClient), "Account", bulk.OperationInsert, file, nil)
+//	failed := result.Failed()
+//
+// Data exceeding the upload limits of a job is split at row boundaries into several jobs, each
+// repeating the header row, which are run options.Concurrency at a time.
+//
+// Rows which fail with one of options.RetryableErrors are ingested again by a new job up to
+// options.Retries times, see IngestResult.Recovered.
+//
+// When options.CorrelationColumn is set the results are joined with the rows of the input by
+// that column, see IngestResult.Rows.
+//
+// If ctx is canceled any running jobs are aborted. A job which finishes in the Failed or Aborted
+// state is returned as a *JobError along with whatever results are available.
+func Ingest(ctx context.Context, builder requests.Builder, object string, operation operation, body io.Reader, options *IngestOptions) (*IngestResult, error) {
+	opts := options.withDefaults()
+	cp, err := loadCheckpoint(opts.CheckpointStore, opts.CheckpointKey, object, string(operation))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ingest(ctx, builder, object, operation, body, opts, cp)
+	if err == nil && opts.Retries > 0 {
+		err = retryFailures(ctx, builder, object, operation, result, opts, cp)
+	}
+
+	// the checkpoint is kept until the retries finish, as a crash before then would otherwise
+	// submit the whole input again
+	if err == nil {
+		err = cp.remove()
+	}
+
+	if result != nil && opts.CorrelationColumn != "" {
+		if reconcileErr := result.reconcile(opts.CorrelationColumn); err == nil {
+			err = reconcileErr
+		}
+	}
+
+	return result, err
+}
+
+// ingest splits body into jobs and runs them, recording them with cp
+func ingest(ctx context.Context, builder requests.Builder, object string, operation operation, body io.Reader, opts IngestOptions, cp *checkpointer) (*IngestResult, error) {
+	splits, err := newSplitter(body, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	create := &CreateJobRequest{
+		AssignmentRuleID:    opts.AssignmentRuleID,
+		ColumnDelimiter:     opts.ColumnDelimiter,
+		ContentType:         ContentTypeCSV,
+		ExternalIDFieldName: opts.ExternalIDFieldName,
+		LineEnding:          opts.LineEnding,
+		Object:              object,
+		Operation:           operation,
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		results  []*splitResult
+		errs     []error
+		splitErr error
+	)
+
+	sem := make(chan struct{}, opts.Concurrency)
+	for splitErr == nil {
+		sp, err := splits.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			splitErr = err
+			break
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			splitErr = ctx.Err()
+			continue
+		}
+
+		mu.Lock()
+		results = append(results, nil)
+		errs = append(errs, nil)
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(sp *split) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := ingestSplit(ctx, builder, create, sp, opts, cp)
+			mu.Lock()
+			results[sp.index], errs[sp.index] = result, err
+			mu.Unlock()
+		}(sp)
+	}
+
+	wg.Wait()
+
+	err = splitErr
+	for _, e := range errs {
+		if e != nil {
+			err = e
+			break
+		}
+	}
+
+	result := newIngestResult(results, opts.ColumnDelimiter)
+	if len(result.Jobs) == 0 && err != nil {
+		return nil, err
+	}
+	result.keys = splits.correlations
+
+	return result, err
+}
+
+// splitResult is the outcome of the job of a single split
+type splitResult struct {
+	job    *GetJobInfoResponse
+	tables []*resultTable
+}
+
+// ingestSplit runs a job for sp, or resumes the job recorded for sp by cp, and downloads its results
+func ingestSplit(ctx context.Context, builder requests.Builder, create *CreateJobRequest, sp *split, opts IngestOptions, cp *checkpointer) (*splitResult, error) {
+	builder = builder.Context(ctx)
+
+	// storeErr is the first error recording the state of the job with cp
+	var storeErr error
+	if cp != nil {
+		progress := opts.Progress
+		opts.Progress = func(job *GetJobInfoResponse) {
+			if err := cp.setState(sp.index, job.State); err != nil && storeErr == nil {
+				storeErr = err
+			}
+			if progress != nil {
+				progress(job)
+			}
+		}
+	}
+
+	var final *GetJobInfoResponse
+	var err error
+	if saved, ok := cp.job(sp.index); ok {
+		final, err = resumeIngestJob(ctx, builder, saved, sp, opts)
+	}
+
+	if final == nil && err == nil {
+		final, err = startIngestJob(ctx, builder, create, sp, opts, cp)
+	}
+
+	if final == nil {
+		return nil, err
+	}
+
+	result := &splitResult{job: final}
+	resultErr := result.download(ctx, builder, sp, opts.ColumnDelimiter)
+	if resultErr == nil {
+		resultErr = cp.setState(sp.index, final.State)
+	}
+
+	if resultErr != nil && err == nil {
+		err = resultErr
+	}
+
+	if storeErr != nil && err == nil {
+		err = storeErr
+	}
+
+	return result, err
+}
+
+// startIngestJob creates a job for sp, records it with cp, and runs it
+func startIngestJob(ctx context.Context, builder requests.Builder, create *CreateJobRequest, sp *split, opts IngestOptions, cp *checkpointer) (*GetJobInfoResponse, error) {
+	job, err := CreateJob(builder, create)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s job: %w", create.Operation, err)
+	}
+
+	if err := cp.setJob(CheckpointJob{Split: sp.index, ID: job.ID, State: job.State, Digest: digest(sp.data)}); err != nil {
+		abortJob(builder, job.ID)
+		return nil, err
+	}
+
+	return runIngestJob(ctx, builder, job.ID, bytes.NewReader(sp.data), opts)
+}
+
+// resumeIngestJob waits for a job created for sp by an earlier ingest. The state of the job is
+// taken from Salesforce rather than the checkpoint, which may be stale. A job which is still Open
+// may hold incomplete data and is never processed, so it is aborted and nil is returned to
+// submit sp again.
+func resumeIngestJob(ctx context.Context, builder requests.Builder, saved CheckpointJob, sp *split, opts IngestOptions) (*GetJobInfoResponse, error) {
+	if saved.Digest != digest(sp.data) {
+		return nil, fmt.Errorf("split %d: %w", sp.index, ErrCheckpointMismatch)
+	}
+
+	job, err := GetJob(builder, saved.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job %s: %w", saved.ID, err)
+	}
+
+	if jobState(job.State) == JobStateOpen {
+		abortJob(builder, saved.ID)
+		return nil, nil
+	}
+
+	return WaitForJob(ctx, builder, saved.ID, &opts)
+}
+
+// newIngestResult merges the results of each split in order
+func newIngestResult(results []*splitResult, d delimiter) *IngestResult {
+	r := &IngestResult{delimiter: d}
+	tables := make([][]*resultTable, len(resultKinds))
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+
+		r.Jobs = append(r.Jobs, result.job)
+		r.NumRecordsProcessed += result.job.NumRecordsProcessed
+		r.NumRecordsFailed += result.job.NumRecordsFailed
+		for i, table := range result.tables {
+			tables[i] = append(tables[i], table)
+		}
+	}
+
+	r.successful = mergeResults(tables[0], d)
+	r.failed = mergeResults(tables[1], d)
+	r.unprocessed = mergeResults(tables[2], d)
+	return r
+}
+
+// runIngestJob uploads body to an open job, closes it, and waits for it to finish. The job is
+// aborted if any step fails or ctx is canceled.
+func runIngestJob(ctx context.Context, builder requests.Builder, jobID string, body io.Reader, opts IngestOptions) (*GetJobInfoResponse, error) {
+	statusCode, err := UploadJob(builder, jobID, body)
+	if err == nil && statusCode >= http.StatusMultipleChoices {
+		err = fmt.Errorf("http response (%v)", statusCode)
+	}
+
+	if err != nil {
+		abortJob(builder, jobID)
+		return nil, fmt.Errorf("uploading job %s: %w", jobID, err)
+	}
+
+	if _, err := UpdateJob(builder, jobID, &UpdateJobRequest{State: JobStateUploadComplete}); err != nil {
+		abortJob(builder, jobID)
+		return nil, fmt.Errorf("closing job %s: %w", jobID, err)
+	}
+
+	return WaitForJob(ctx, builder, jobID, &opts)
+}
+
+// WaitForJob polls the state of an ingest job, backing off exponentially between requests as
+// configured by options, until it is JobComplete, Failed, or Aborted. If ctx is canceled while
+// the job is still running the job is aborted.
+func WaitForJob(ctx context.Context, builder requests.Builder, jobID string, options *IngestOptions) (*GetJobInfoResponse, error) {
+	return waitFor(ctx, builder, jobID, options, GetJob, abortJob)
+}
+
+// waitFor polls a job using get until it finishes, or aborts it using abort if ctx is canceled
+func waitFor(ctx context.Context, builder requests.Builder, jobID string, options *IngestOptions, get func(requests.Builder, string) (*GetJobInfoResponse, error), abort func(requests.Builder, string)) (*GetJobInfoResponse, error) {
+	opts := options.withDefaults()
+	builder = builder.Context(ctx)
+
+	var job *GetJobInfoResponse
+	err := poll(ctx, opts.PollInterval, opts.MaxPollInterval, func() (bool, error) {
+		var err error
+		if job, err = get(builder, jobID); err != nil {
+			return false, fmt.Errorf("getting job %s: %w", jobID, err)
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(job)
+		}
+
+		return isFinished(job.State), nil
+	})
+
+	// a cancel which races with the last poll leaves a finished job alone
+	finished := job != nil && isFinished(job.State)
+	if !finished && ctx.Err() != nil {
+		abort(builder, jobID)
+		return job, ctx.Err()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch jobState(job.State) {
+	case JobStateFailed:
+		return job, &JobError{job, ErrJobFailed}
+	case JobStateAborted:
+		return job, &JobError{job, ErrJobAborted}
+	}
+
+	return job, nil
+}
+
+// download retrieves the results of the job of sp and orders them as the rows of sp
+func (r *splitResult) download(ctx context.Context, builder requests.Builder, sp *split, d delimiter) error {
+	// a job which never started has no results
+	if jobState(r.job.State) == JobStateOpen {
+		return nil
+	}
+
+	builder = builder.Context(ctx)
+	r.tables = make([]*resultTable, len(resultKinds))
+	for i, kind := range resultKinds {
+		contents, err := getResults(builder, r.job.ID, kind.path)
+		if err != nil {
+			return fmt.Errorf("getting %s of job %s: %w", kind.path, r.job.ID, err)
+		}
+
+		if r.tables[i], err = parseResults(contents, d, kind.prefix, sp); err != nil {
+			return fmt.Errorf("parsing %s of job %s: %w", kind.path, r.job.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// isFinished reports whether a job has reached a terminal state
+func isFinished(state string) bool {
+	switch jobState(state) {
+	case JobStateComplete, JobStateFailed, JobStateAborted:
+		return true
+	}
+	return false
+}
+
+// abortJob makes a best effort attempt to abort a job, regardless of whether the context of
+// builder was canceled
+func abortJob(builder requests.Builder, jobID string) {
+	UpdateJob(builder.Context(context.Background()), jobID, &UpdateJobRequest{State: JobStateAborted})
+}
+
+// poll calls fn until it returns true or an error, sleeping between calls for interval which
+// doubles up to max
+func poll(ctx context.Context, interval time.Duration, max time.Duration, fn func() (bool, error)) error {
+	for {
+		done, err := fn()
+		if err != nil || done {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		if interval *= 2; interval > max {
+			interval = max
+		}
+	}
+}
+
+// getResults returns the CSV contents of the given results of a job
+func getResults(builder requests.Builder, jobID string, path string) ([]byte, error) {
+	response, err := builder.
+		Method(http.MethodGet).
+		URL(fmt.Sprintf("%s/%s/%s", ingestEndpoint, jobID, path)).
+		Response()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return requests.ReadAndCloseResponse(response)
+}
+
+// newCSVReader returns a csv.Reader of r using the given delimiter
+func newCSVReader(r io.Reader, d delimiter) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = d.rune()
+	return reader
+}
+
+// rune returns the character of a delimiter, a comma by default
+func (d delimiter) rune() rune {
+	switch d {
+	case DelimiterBackQuote:
+		return '`'
+	case DelimeterCaret:
+		return '^'
+	case DelimiterPipe:
+		return '|'
+	case DelimiterSemiColon:
+		return ';'
+	case DelimiterTab:
+		return '\t'
+	}
+	return ','
+}
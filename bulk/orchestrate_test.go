@@ -0,0 +1,110 @@
+package bulk_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/stretchr/testify/require"
+)
+
+const accountsCSV = "Name|NumberOfEmployees\nAcme|30\nfail|40\nGlobex|50\n"
+
+func TestIngest(t *testing.T) {
+	server := newFakeBulk(t)
+	server.fail = func(job *bulk.GetJobInfoResponse, row map[string]string) string {
+		if row["Name"] == "fail" {
+			return "REQUIRED_FIELD_MISSING:Required fields are missing: [Name]:Name --"
+		}
+		return ""
+	}
+
+	var states []string
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader(accountsCSV), &bulk.IngestOptions{
+		ColumnDelimiter: bulk.DelimiterPipe,
+		PollInterval:    time.Millisecond,
+		Progress: func(job *bulk.GetJobInfoResponse) {
+			states = append(states, job.State)
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, []string{"InProgress", "JobComplete"}, states)
+	require.Len(t, result.Jobs, 1)
+	require.Equal(t, 3, result.NumRecordsProcessed)
+	require.Equal(t, 1, result.NumRecordsFailed)
+
+	successful, err := result.Successful().ReadAll()
+	require.Nil(t, err)
+	require.Len(t, successful, 3)
+	require.Equal(t, []string{"sf__Id", "sf__Created", "Name", "NumberOfEmployees"}, successful[0])
+
+	failed, err := result.Failed().ReadAll()
+	require.Nil(t, err)
+	require.Len(t, failed, 2)
+	require.Equal(t, "fail", failed[1][2])
+
+	unprocessed, err := result.Unprocessed().ReadAll()
+	require.Nil(t, err)
+	require.Len(t, unprocessed, 1)
+}
+
+func TestIngestCanceled(t *testing.T) {
+	server := newFakeBulk(t)
+	server.pollsUntilComplete = 100
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := bulk.Ingest(ctx, server.builder(), "Account", bulk.OperationInsert, strings.NewReader("Name\nAcme\n"), &bulk.IngestOptions{
+		PollInterval: time.Millisecond,
+		Progress: func(job *bulk.GetJobInfoResponse) {
+			cancel()
+		},
+	})
+
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, "Aborted", server.job(server.order[0]).info.State)
+}
+
+func TestWaitForJobCanceledAfterCompletion(t *testing.T) {
+	server := newFakeBulk(t)
+	server.pollsUntilComplete = 1
+
+	job, err := bulk.CreateJob(server.builder(), &bulk.CreateJobRequest{Object: "Account", Operation: bulk.OperationInsert})
+	require.Nil(t, err)
+	server.job(job.ID).info.State = "UploadComplete"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	final, err := bulk.WaitForJob(ctx, server.builder(), job.ID, &bulk.IngestOptions{
+		PollInterval: time.Millisecond,
+		Progress: func(job *bulk.GetJobInfoResponse) {
+			if job.State == "JobComplete" {
+				cancel()
+			}
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, "JobComplete", final.State)
+	require.Equal(t, "JobComplete", server.job(job.ID).info.State, "a finished job is not aborted")
+}
+
+func TestWaitForJobFailed(t *testing.T) {
+	server := newFakeBulk(t)
+	server.pollsUntilComplete = 1
+
+	job, err := bulk.CreateJob(server.builder(), &bulk.CreateJobRequest{Object: "Account", Operation: bulk.OperationInsert})
+	require.Nil(t, err)
+	server.job(job.ID).info.State = "Failed"
+	server.job(job.ID).info.ErrorMessage = "InvalidBatch : Field name not found : Nme"
+
+	_, err = bulk.WaitForJob(context.Background(), server.builder(), job.ID, nil)
+	require.True(t, errors.Is(err, bulk.ErrJobFailed))
+	require.Contains(t, err.Error(), "Field name not found")
+}
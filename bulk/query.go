@@ -1,7 +1,6 @@
 package bulk
 
 import (
-	"bytes"
 	"encoding/csv"
 	"fmt"
 	"net/http"
@@ -14,8 +13,15 @@ import (
 const queryEndpoint = "jobs/query"
 
 // CreateQuery ...
+//
+// Bulk queries don't support FIELDS(), expand it beforehand with a soql.FieldsExpander whose
+// Bulk field is true.
 // https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/query_create_job.htm
 func CreateQuery(builder requests.Builder, delimiter delimiter, lineEnding lineEnding, q soql.Builder) (job *JobInfo, err error) {
+	if soql.ContainsFields(q) {
+		return nil, fmt.Errorf("bulk.CreateQuery: %w", soql.ErrFieldsNotSupported)
+	}
+
 	sql, err := q.ToSQL()
 	if err != nil {
 		return nil, err
@@ -103,7 +109,7 @@ func GetQueryResults(builder requests.Builder, jobID string, locator string, max
 		return "", nil, err
 	}
 
-	return response.Header.Get("Sforce-Locator"), csv.NewReader(bytes.NewBuffer(contents)), nil
+	return response.Header.Get("Sforce-Locator"), newResultReader(contents), nil
 }
 
 // UpdateQuery ...
@@ -126,10 +132,15 @@ func UpdateQuery(builder requests.Builder, jobID string, update *UpdateJobReques
 // DeleteQuery ...
 // https://developer.salesforce.com/docs/atlas.en-us.api_bulk_v2.meta/api_bulk_v2/query_delete_job.htm
 func DeleteQuery(builder requests.Builder, jobID string) error {
-	_, err := builder.
+	response, err := builder.
 		Method(http.MethodDelete).
 		URL(fmt.Sprintf("%s/%s", queryEndpoint, jobID)).
 		Response()
 
+	if err != nil {
+		return err
+	}
+
+	_, err = requests.ReadAndCloseResponse(response)
 	return err
 }
\ No newline at end of file
@@ -0,0 +1,93 @@
+package bulk
+
+import "fmt"
+
+// reconcile.go joins the results of an ingest with the rows of its input by a correlation column.
+// Salesforce neither preserves the order of the rows nor echoes them exactly as they were
+// uploaded, so a column holding a key of the source system is the reliable way to map each new
+// sf__Id back to the record it came from.
+
+// RowResult is the outcome of a single row of the input of an ingest
+type RowResult struct {
+	// Row is the index of the row within the input, excluding the header
+	Row int
+	// Key is the value of the correlation column of the row
+	Key string
+	// ID is the sf__Id of the record, which is empty for some failures
+	ID string
+	// Created is true if the record was created and false if it was updated
+	Created bool
+	// Error is the sf__Error of a row which failed
+	Error string
+	// Processed is false for rows which Salesforce did not process, such as those of an aborted
+	// job, and for rows missing from the results
+	Processed bool
+}
+
+// Success reports whether the row was processed without error
+func (r *RowResult) Success() bool {
+	return r.Processed && r.Error == ""
+}
+
+// ErrorCode returns the status code of the sf__Error of r
+func (r *RowResult) ErrorCode() string {
+	return ErrorCode(r.Error)
+}
+
+// reconcile sets r.Rows by matching the correlation column of each row of the results with the
+// keys of the input. Rows sharing a key are matched in the order they appear.
+func (r *IngestResult) reconcile(column string) error {
+	r.Rows = make([]*RowResult, len(r.keys))
+	positions := make(map[string][]int, len(r.keys))
+	for i, key := range r.keys {
+		r.Rows[i] = &RowResult{Row: i, Key: key}
+		positions[key] = append(positions[key], i)
+	}
+
+	tables := []struct {
+		name     string
+		contents []byte
+		prefix   int
+		apply    func(row *RowResult, values []string)
+	}{
+		{"successful", r.successful, 2, func(row *RowResult, values []string) {
+			row.ID, row.Created, row.Processed = values[0], values[1] == "true", true
+		}},
+		{"failed", r.failed, 2, func(row *RowResult, values []string) {
+			row.ID, row.Error, row.Processed = values[0], values[1], true
+		}},
+		{"unprocessed", r.unprocessed, 0, func(row *RowResult, values []string) {}},
+	}
+
+	for _, t := range tables {
+		table, err := parseResults(t.contents, r.delimiter, 0, nil)
+		if err != nil {
+			return fmt.Errorf("parsing %s results: %w", t.name, err)
+		}
+
+		if len(table.rows) == 0 {
+			continue
+		}
+
+		index := columnIndex(table.header, column)
+		if index < 0 {
+			return fmt.Errorf("%s results do not include the correlation column %s", t.name, column)
+		}
+
+		for _, values := range table.rows {
+			if index >= len(values) || len(values) < t.prefix {
+				continue
+			}
+
+			indexes := positions[values[index]]
+			if len(indexes) == 0 {
+				continue
+			}
+
+			positions[values[index]] = indexes[1:]
+			t.apply(r.Rows[indexes[0]], values)
+		}
+	}
+
+	return nil
+}
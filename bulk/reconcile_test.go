@@ -0,0 +1,91 @@
+package bulk_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/stretchr/testify/require"
+)
+
+func failNamed(name string) func(job *bulk.GetJobInfoResponse, row map[string]string) string {
+	return func(job *bulk.GetJobInfoResponse, row map[string]string) string {
+		if row["Name"] == name {
+			return "REQUIRED_FIELD_MISSING:Required fields are missing: [Name]:Name --"
+		}
+		return ""
+	}
+}
+
+func TestIngestCorrelationInjected(t *testing.T) {
+	server := newFakeBulk(t)
+	server.fail = failNamed("fail")
+
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader(accountsCSV), &bulk.IngestOptions{
+		ColumnDelimiter:   bulk.DelimiterPipe,
+		PollInterval:      time.Millisecond,
+		CorrelationColumn: "Source_Key__c",
+	})
+
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(string(server.job(result.Jobs[0].ID).uploads[0]), "Name|NumberOfEmployees|Source_Key__c\nAcme|30|1\n"))
+	require.Len(t, result.Rows, 3)
+
+	for i, row := range result.Rows {
+		require.Equal(t, i, row.Row)
+		require.Equal(t, []string{"1", "2", "3"}[i], row.Key)
+		require.True(t, row.Processed)
+	}
+
+	require.True(t, result.Rows[0].Success())
+	require.True(t, result.Rows[0].Created)
+	require.NotEmpty(t, result.Rows[0].ID)
+	require.False(t, result.Rows[1].Success())
+	require.Equal(t, "REQUIRED_FIELD_MISSING", result.Rows[1].ErrorCode())
+	require.True(t, result.Rows[2].Success())
+	require.NotEqual(t, result.Rows[0].ID, result.Rows[2].ID)
+}
+
+func TestIngestCorrelationColumn(t *testing.T) {
+	server := newFakeBulk(t)
+	server.fail = failNamed("fail")
+
+	input := "External_Id__c,Name,Id\nA,Acme,\nB,fail,\nA,Acme,001000000000009\nC,Globex,001000000000010\n"
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationUpsert, strings.NewReader(input), &bulk.IngestOptions{
+		ExternalIDFieldName: "External_Id__c",
+		PollInterval:        time.Millisecond,
+		CorrelationColumn:   "External_Id__c",
+	})
+
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(string(server.job(result.Jobs[0].ID).uploads[0]), "External_Id__c,Name,Id\n"), "the column is not injected")
+	require.Len(t, result.Rows, 4)
+	require.Equal(t, "A", result.Rows[0].Key)
+	require.True(t, result.Rows[0].Created)
+	require.Equal(t, "REQUIRED_FIELD_MISSING:Required fields are missing: [Name]:Name --", result.Rows[1].Error)
+	require.Equal(t, "A", result.Rows[2].Key)
+	require.Equal(t, 2, result.Rows[2].Row)
+	require.False(t, result.Rows[2].Created, "an existing record is updated")
+	require.Equal(t, "001000000000010", result.Rows[3].ID)
+}
+
+func TestIngestCorrelationRetries(t *testing.T) {
+	server := lockingServer(t, 1)
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader("Name,NumberOfEmployees\nAcme,1\nlock,2\nfail,3\nlock,4\n"), &bulk.IngestOptions{
+		PollInterval:      time.Millisecond,
+		Retries:           1,
+		RetryInterval:     time.Millisecond,
+		CorrelationColumn: "Key__c",
+		MaxUploadBytes:    40,
+	})
+
+	require.Nil(t, err)
+	require.Greater(t, len(result.Jobs), 2, "the input is split and retried")
+	require.Len(t, result.Rows, 4)
+	for i, success := range []bool{true, true, false, true} {
+		require.Equal(t, success, result.Rows[i].Success(), "row %d", i)
+		require.Equal(t, success, result.Rows[i].ID != "", "row %d", i)
+	}
+}
@@ -0,0 +1,176 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/requests"
+)
+
+// retry.go ingests again the rows of an ingest which failed with transient errors, such as lock
+// contention between parallel jobs updating the same parent record.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/asynch_api_concepts_limits.htm
+
+// DefaultRetryInterval is the delay before the first retry of failed rows
+const DefaultRetryInterval = 5 * time.Second
+
+// DefaultRetryableErrors are the status codes of failures which are retried by default
+var DefaultRetryableErrors = []string{
+	"UNABLE_TO_LOCK_ROW",
+	"REQUEST_RUNNING_TOO_LONG",
+	"SERVER_UNAVAILABLE",
+}
+
+// ErrorCode returns the status code of an sf__Error, i.e. UNABLE_TO_LOCK_ROW for
+// "UNABLE_TO_LOCK_ROW:unable to obtain exclusive access to this record:--"
+func ErrorCode(sfError string) string {
+	if i := strings.Index(sfError, ":"); i >= 0 {
+		return strings.TrimSpace(sfError[:i])
+	}
+	return strings.TrimSpace(sfError)
+}
+
+// ErrorCode returns the status code of the sf__Error of r
+func (r Result) ErrorCode() string {
+	return ErrorCode(r.Error)
+}
+
+// retryFailures ingests the rows of result which failed with a retryable error again, up to
+// opts.Retries times, merging the outcome of each attempt into result. The jobs of each attempt
+// are recorded with cp, and an attempt whose jobs were recorded by an earlier run is resumed
+// without waiting.
+func retryFailures(ctx context.Context, builder requests.Builder, object string, operation operation, result *IngestResult, opts IngestOptions, cp *checkpointer) error {
+	retryOpts := opts
+	retryOpts.Retries = 0
+
+	interval := opts.RetryInterval
+	for attempt := 0; attempt < opts.Retries; attempt++ {
+		retry, failed, err := partitionFailures(result, opts)
+		if err != nil || retry == nil {
+			return err
+		}
+
+		retryCp := cp.retry(attempt + 1)
+		if !retryCp.started() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+		interval *= 2
+
+		retried, err := ingest(ctx, builder, object, operation, bytes.NewReader(retry), retryOpts, retryCp)
+		if retried == nil {
+			return err
+		}
+
+		if mergeErr := result.mergeRetry(retried, failed); mergeErr != nil {
+			return mergeErr
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// partitionFailures returns the CSV input of a job for the failed rows of result whose errors are
+// retryable, or nil if there are none, and the table of the other failed rows
+func partitionFailures(result *IngestResult, opts IngestOptions) ([]byte, *resultTable, error) {
+	failed, err := parseResults(result.failed, result.delimiter, 0, nil)
+	if err != nil || len(failed.header) < 2 {
+		return nil, failed, err
+	}
+
+	retryable := make(map[string]bool, len(opts.RetryableErrors))
+	for _, code := range opts.RetryableErrors {
+		retryable[code] = true
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = opts.ColumnDelimiter.rune()
+	writer.UseCRLF = opts.LineEnding == LineEndingCRLF
+	writer.Write(failed.header[2:])
+
+	permanent := &resultTable{header: failed.header}
+	var retries int
+	for _, row := range failed.rows {
+		// sf__Id and sf__Error precede the columns of the input
+		if len(row) >= 2 && retryable[ErrorCode(row[1])] {
+			writer.Write(row[2:])
+			retries++
+			continue
+		}
+		permanent.rows = append(permanent.rows, row)
+	}
+
+	writer.Flush()
+	if retries == 0 {
+		return nil, permanent, writer.Error()
+	}
+
+	return buf.Bytes(), permanent, writer.Error()
+}
+
+// mergeRetry merges the results of retried into r, whose failures are now those of failed and
+// those of retried
+func (r *IngestResult) mergeRetry(retried *IngestResult, failed *resultTable) error {
+	successful, err := parseTables(r.delimiter, r.successful, retried.successful)
+	if err != nil {
+		return err
+	}
+
+	recovered, err := parseTables(r.delimiter, r.recovered, retried.successful)
+	if err != nil {
+		return err
+	}
+
+	failures, err := parseTables(r.delimiter, nil, retried.failed)
+	if err != nil {
+		return err
+	}
+
+	unprocessed, err := parseTables(r.delimiter, r.unprocessed, retried.unprocessed)
+	if err != nil {
+		return err
+	}
+
+	failures[0] = failed
+	r.Jobs = append(r.Jobs, retried.Jobs...)
+	r.Retries++
+	r.NumRecordsFailed = len(failed.rows) + retried.NumRecordsFailed
+	r.NumRecordsRecovered += len(successful[1].rows)
+	r.successful = mergeResults(successful, r.delimiter)
+	r.recovered = mergeResults(recovered, r.delimiter)
+	r.failed = mergeResults(failures, r.delimiter)
+	r.unprocessed = mergeResults(unprocessed, r.delimiter)
+	return nil
+}
+
+// parseTables parses each of contents as merged results
+func parseTables(d delimiter, contents ...[]byte) ([]*resultTable, error) {
+	tables := make([]*resultTable, len(contents))
+	for i, c := range contents {
+		table, err := parseResults(c, d, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		tables[i] = table
+	}
+
+	return tables, nil
+}
+
+// Recovered returns the rows which failed with a retryable error and succeeded when retried,
+// prefixed by the sf__Id and sf__Created columns
+func (r *IngestResult) Recovered() *csv.Reader {
+	return newCSVReader(bytes.NewReader(r.recovered), r.delimiter)
+}
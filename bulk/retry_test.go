@@ -0,0 +1,150 @@
+package bulk_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/stretchr/testify/require"
+)
+
+const lockError = "UNABLE_TO_LOCK_ROW:unable to obtain exclusive access to this record:--"
+
+// lockingServer fails rows named "fail" permanently and rows named "lock" until they have been
+// attempted locks times
+func lockingServer(t *testing.T, locks int) *fakeBulk {
+	server := newFakeBulk(t)
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	server.fail = func(job *bulk.GetJobInfoResponse, row map[string]string) string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch row["Name"] {
+		case "fail":
+			return "REQUIRED_FIELD_MISSING:Required fields are missing: [Name]:Name --"
+		case "lock":
+			if attempts[row["NumberOfEmployees"]]++; attempts[row["NumberOfEmployees"]] <= locks {
+				return lockError
+			}
+		}
+		return ""
+	}
+	return server
+}
+
+func ingestWithRetries(t *testing.T, server *fakeBulk) *bulk.IngestResult {
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader("Name,NumberOfEmployees\nAcme,1\nlock,2\nfail,3\nlock,4\n"), &bulk.IngestOptions{
+		PollInterval:  time.Millisecond,
+		Retries:       2,
+		RetryInterval: time.Millisecond,
+	})
+	require.Nil(t, err)
+	return result
+}
+
+func TestIngestRetries(t *testing.T) {
+	result := ingestWithRetries(t, lockingServer(t, 1))
+	require.Len(t, result.Jobs, 2)
+	require.Equal(t, 1, result.Retries)
+	require.Equal(t, 2, result.NumRecordsRecovered)
+	require.Equal(t, 1, result.NumRecordsFailed)
+
+	successful, err := result.Successful().ReadAll()
+	require.Nil(t, err)
+	require.Len(t, successful, 4)
+
+	recovered, err := result.Recovered().ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, []string{"sf__Id", "sf__Created", "Name", "NumberOfEmployees"}, recovered[0])
+	require.Len(t, recovered, 3)
+	for _, row := range recovered[1:] {
+		require.Equal(t, "lock", row[2])
+	}
+
+	var failed []bulk.Result
+	require.Nil(t, bulk.NewDecoder(result.Failed()).DecodeAll(&failed))
+	require.Len(t, failed, 1)
+	require.Equal(t, "REQUIRED_FIELD_MISSING", failed[0].ErrorCode())
+}
+
+func TestIngestRetriesExhausted(t *testing.T) {
+	result := ingestWithRetries(t, lockingServer(t, 5))
+	require.Len(t, result.Jobs, 3)
+	require.Equal(t, 2, result.Retries)
+	require.Equal(t, 0, result.NumRecordsRecovered)
+	require.Equal(t, 3, result.NumRecordsFailed)
+
+	var failed []bulk.Result
+	require.Nil(t, bulk.NewDecoder(result.Failed()).DecodeAll(&failed))
+	require.Len(t, failed, 3)
+	require.Equal(t, "REQUIRED_FIELD_MISSING", failed[0].ErrorCode())
+	require.Equal(t, "UNABLE_TO_LOCK_ROW", failed[1].ErrorCode())
+	require.Equal(t, "UNABLE_TO_LOCK_ROW", failed[2].ErrorCode())
+
+	recovered, err := result.Recovered().ReadAll()
+	require.Nil(t, err)
+	require.Len(t, recovered, 1, "only the header")
+}
+
+// retryCrashStore copies the first checkpoint which records a retry job into crashed, as if the
+// process had crashed while that job was running
+type retryCrashStore struct {
+	bulk.FileCheckpointStore
+	crashed *bulk.FileCheckpointStore
+	saved   bool
+}
+
+func (s *retryCrashStore) Save(key string, checkpoint *bulk.Checkpoint) error {
+	for _, job := range checkpoint.Jobs {
+		if job.Attempt > 0 && !s.saved {
+			s.saved = true
+			if err := s.crashed.Save(key, checkpoint); err != nil {
+				return err
+			}
+		}
+	}
+	return s.FileCheckpointStore.Save(key, checkpoint)
+}
+
+func TestIngestResumeRetry(t *testing.T) {
+	server := lockingServer(t, 1)
+	input := "Name,NumberOfEmployees\nAcme,1\nlock,2\nfail,3\nlock,4\n"
+	crashed := &bulk.FileCheckpointStore{Dir: t.TempDir()}
+	options := &bulk.IngestOptions{
+		PollInterval:    time.Millisecond,
+		Retries:         2,
+		RetryInterval:   time.Millisecond,
+		CheckpointKey:   "accounts",
+		CheckpointStore: &retryCrashStore{FileCheckpointStore: bulk.FileCheckpointStore{Dir: t.TempDir()}, crashed: crashed},
+	}
+
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader(input), options)
+	require.Nil(t, err)
+	require.Len(t, server.order, 2)
+
+	checkpoint, err := crashed.Load("accounts")
+	require.Nil(t, err)
+	require.Len(t, checkpoint.Jobs, 2, "the retry job is recorded alongside the job of the input")
+	require.Equal(t, result.Jobs[1].ID, checkpoint.Jobs[1].ID)
+
+	// a resumed retry does not wait for the retry interval again
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	options.CheckpointStore = crashed
+	options.RetryInterval = time.Hour
+
+	resumed, err := bulk.Ingest(ctx, server.builder(), "Account", bulk.OperationInsert, strings.NewReader(input), options)
+	require.Nil(t, err)
+	require.Len(t, server.order, 2, "neither the input nor the retry is submitted again")
+	require.Equal(t, result.Jobs[1].ID, resumed.Jobs[1].ID)
+	require.Equal(t, 2, resumed.NumRecordsRecovered)
+	require.Equal(t, 1, resumed.NumRecordsFailed)
+
+	checkpoint, err = crashed.Load("accounts")
+	require.Nil(t, err)
+	require.Nil(t, checkpoint, "the checkpoint is deleted once the retries finish")
+}
@@ -0,0 +1,371 @@
+package bulk_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/requests"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+)
+
+// fakeSender sends requests to a fakeBulk server
+type fakeSender struct {
+	url string
+}
+
+func (s *fakeSender) Do(req *http.Request) (*http.Response, error) {
+	return http.DefaultClient.Do(req)
+}
+
+func (s *fakeSender) QueryMore(builder soql.Builder, dst interface{}, includeSoftDelete bool) error {
+	return nil
+}
+
+func (s *fakeSender) URL(path string) string {
+	if strings.HasPrefix(path, "http") {
+		return path
+	}
+	return s.url + "/" + strings.TrimPrefix(path, "/")
+}
+
+// fakeJob is an ingest or query job held by a fakeBulk server
+type fakeJob struct {
+	info    bulk.GetJobInfoResponse
+	query   bool
+	uploads [][]byte
+	polls   int
+	results map[string][][]string
+	created time.Time
+}
+
+// fakeBulk imitates the ingest and query endpoints of Bulk API 2.0. Rows are processed when an
+// ingest job is closed and their results are returned in reverse order, as Salesforce does not
+// preserve order. Every query job returns queryRows.
+type fakeBulk struct {
+	mu     sync.Mutex
+	server *httptest.Server
+	jobs   map[string]*fakeJob
+	order  []string
+	nextID int
+	// pollsUntilComplete is the number of requests for a job before it is JobComplete
+	pollsUntilComplete int
+	// fail returns the sf__Error of a row, or "" if it succeeds
+	fail func(job *bulk.GetJobInfoResponse, row map[string]string) string
+	// queryRows are the header and rows returned by every query job
+	queryRows [][]string
+	// pageSize is the number of rows in each page of query results when maxRecords is not given
+	pageSize int
+	// listSize is the number of jobs in each page of the list of jobs, 1000 by default
+	listSize int
+}
+
+func newFakeBulk(t *testing.T) *fakeBulk {
+	f := &fakeBulk{jobs: make(map[string]*fakeJob), pollsUntilComplete: 2}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeBulk) builder() requests.Builder {
+	return requests.Sender(&fakeSender{f.server.URL})
+}
+
+func (f *fakeBulk) job(id string) *fakeJob {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jobs[id]
+}
+
+// age makes a job appear to have been created d ago
+func (f *fakeBulk) age(id string, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs[id].created = time.Now().Add(-d)
+}
+
+func (f *fakeBulk) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "jobs" || (parts[1] != "ingest" && parts[1] != "query") {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 2 && r.Method == http.MethodPost && parts[1] == "query" {
+		var req bulk.CreateQueryJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job := f.newJob()
+		job.query = true
+		job.info.JobType = "V2Query"
+		job.info.Operation = req.Operation
+		job.info.ColumnDelimiter = req.ColumnDelimiter
+		job.info.LineEnding = req.LineEnding
+		job.info.State = "UploadComplete"
+		writeJSON(w, http.StatusOK, job.info.JobInfo)
+		return
+	}
+
+	if len(parts) == 2 && r.Method == http.MethodPost {
+		var req bulk.CreateJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job := f.newJob()
+		job.info.Object = req.Object
+		job.info.Operation = req.Operation
+		job.info.ColumnDelimiter = req.ColumnDelimiter
+		job.info.LineEnding = req.LineEnding
+		job.info.ExternalIDFieldName = req.ExternalIDFieldName
+		job.info.State = "Open"
+		writeJSON(w, http.StatusOK, job.info.JobInfo)
+		return
+	}
+
+	if len(parts) == 2 && r.Method == http.MethodGet {
+		f.writeJobs(w, r, parts[1] == "query")
+		return
+	}
+
+	job, ok := f.jobs[parts[2]]
+	if !ok || job.query != (parts[1] == "query") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		if job.info.State == "UploadComplete" || job.info.State == "InProgress" {
+			job.polls++
+			job.info.State = "InProgress"
+			if job.polls >= f.pollsUntilComplete {
+				job.info.State = "JobComplete"
+				if job.query {
+					job.info.NumRecordsProcessed = len(f.queryRows) - 1
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, job.info)
+	case len(parts) == 3 && r.Method == http.MethodPatch:
+		var req bulk.UpdateJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job.info.State = fmt.Sprint(req.State)
+		if job.info.State == "UploadComplete" && !job.query {
+			if err := f.process(job); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, job.info.JobInfo)
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		delete(f.jobs, job.info.ID)
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 4 && parts[3] == "batches" && r.Method == http.MethodPut:
+		var buf bytes.Buffer
+		buf.ReadFrom(r.Body)
+		job.uploads = append(job.uploads, buf.Bytes())
+		w.WriteHeader(http.StatusCreated)
+	case len(parts) == 4 && parts[3] == "results" && job.query && r.Method == http.MethodGet:
+		f.writePage(w, r, job)
+	case len(parts) == 4 && r.Method == http.MethodGet:
+		rows, ok := job.results[parts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write(encodeRows(rows, job.info.ColumnDelimiter))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newJob adds a job with a new ID
+func (f *fakeBulk) newJob() *fakeJob {
+	f.nextID++
+	job := &fakeJob{results: make(map[string][][]string)}
+	job.info.ID = fmt.Sprintf("750%012d", f.nextID)
+	job.info.CreatedDate.IsNull = true
+	job.info.SystemModstamp.IsNull = true
+	job.info.CreatedByID = "005000000000001AAA"
+	job.info.JobType = "V2Ingest"
+	job.created = time.Now()
+	f.jobs[job.info.ID] = job
+	f.order = append(f.order, job.info.ID)
+	return job
+}
+
+// writeJobs writes the page of ingest or query jobs beginning at the offset given by the
+// queryLocator. Datetime does not marshal as a JSON string, so each record is encoded by hand.
+func (f *fakeBulk) writeJobs(w http.ResponseWriter, r *http.Request, query bool) {
+	size := f.listSize
+	if size <= 0 {
+		size = 1000
+	}
+
+	var jobs []*fakeJob
+	for _, id := range f.order {
+		job, ok := f.jobs[id]
+		if !ok || job.query != query {
+			continue
+		}
+
+		if jt := r.URL.Query().Get("jobType"); jt != "" && jt != job.info.JobType {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("queryLocator"))
+	end := len(jobs)
+	if offset+size < end {
+		end = offset + size
+	}
+
+	page := map[string]interface{}{"done": end == len(jobs), "records": []interface{}{}}
+	if end < len(jobs) {
+		page["nextRecordsUrl"] = fmt.Sprintf("/services/data/v50.0/jobs/ingest?queryLocator=%d", end)
+	}
+
+	for _, job := range jobs[offset:end] {
+		page["records"] = append(page["records"].([]interface{}), map[string]interface{}{
+			"id":          job.info.ID,
+			"operation":   job.info.Operation,
+			"object":      job.info.Object,
+			"createdById": job.info.CreatedByID,
+			"createdDate": job.created.UTC().Format("2006-01-02T15:04:05.000-0700"),
+			"state":       job.info.State,
+			"jobType":     job.info.JobType,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// writePage writes the page of queryRows beginning at the offset given by the locator
+func (f *fakeBulk) writePage(w http.ResponseWriter, r *http.Request, job *fakeJob) {
+	if job.info.State != "JobComplete" {
+		http.Error(w, "job is not complete", http.StatusBadRequest)
+		return
+	}
+
+	size, _ := strconv.Atoi(r.URL.Query().Get("maxRecords"))
+	if size <= 0 {
+		size = f.pageSize
+	}
+
+	header, rows := f.queryRows[0], f.queryRows[1:]
+	offset, _ := strconv.Atoi(r.URL.Query().Get("locator"))
+	end := len(rows)
+	if size > 0 && offset+size < end {
+		end = offset + size
+	}
+
+	locator := "null"
+	if end < len(rows) {
+		locator = strconv.Itoa(end)
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Sforce-Locator", locator)
+	w.Header().Set("Sforce-NumberOfRecords", strconv.Itoa(end-offset))
+	w.Write(encodeRows(append([][]string{header}, rows[offset:end]...), job.info.ColumnDelimiter))
+}
+
+// process computes the results of the uploaded data of job
+func (f *fakeBulk) process(job *fakeJob) error {
+	var header []string
+	var rows [][]string
+	for _, upload := range job.uploads {
+		reader := csv.NewReader(bytes.NewReader(upload))
+		reader.Comma = delimiterRune(job.info.ColumnDelimiter)
+		records, err := reader.ReadAll()
+		if err != nil {
+			return err
+		}
+
+		if len(records) > 0 {
+			header = records[0]
+			rows = append(rows, records[1:]...)
+		}
+	}
+
+	successful := [][]string{append([]string{"sf__Id", "sf__Created"}, header...)}
+	failed := [][]string{append([]string{"sf__Id", "sf__Error"}, header...)}
+	for i := len(rows) - 1; i >= 0; i-- {
+		fields := make(map[string]string, len(header))
+		for j, column := range header {
+			fields[column] = rows[i][j]
+		}
+
+		if f.fail != nil {
+			if message := f.fail(&job.info, fields); message != "" {
+				failed = append(failed, append([]string{"", message}, rows[i]...))
+				job.info.NumRecordsFailed++
+				continue
+			}
+		}
+
+		id := fields["Id"]
+		created := "false"
+		if id == "" {
+			id = fmt.Sprintf("001%s%03d", job.info.ID[len(job.info.ID)-9:], i)
+			created = "true"
+		}
+		successful = append(successful, append([]string{id, created}, rows[i]...))
+	}
+
+	job.info.NumRecordsProcessed = len(rows)
+	job.results["successfulResults"] = successful
+	job.results["failedResults"] = failed
+	job.results["unprocessedrecords"] = [][]string{header}
+	return nil
+}
+
+func encodeRows(rows [][]string, d interface{}) []byte {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = delimiterRune(d)
+	writer.WriteAll(rows)
+	return buf.Bytes()
+}
+
+func delimiterRune(d interface{}) rune {
+	switch fmt.Sprint(d) {
+	case "PIPE":
+		return '|'
+	case "TAB":
+		return '\t'
+	case "SEMICOLON":
+		return ';'
+	}
+	return ','
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
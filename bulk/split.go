@@ -0,0 +1,299 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// split.go divides the CSV data of an ingest into jobs which fit within the upload limits of
+// Bulk API 2.0 and merges the results of those jobs back into the order of the input.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.salesforce_app_limits_cheatsheet.meta/salesforce_app_limits_cheatsheet/salesforce_app_limits_platform_bulkapi.htm
+
+const (
+	// MaxEncodedUploadBytes is the greatest size of the data of a job once Salesforce has base64
+	// encoded it
+	MaxEncodedUploadBytes = 150 * 1024 * 1024
+	// DefaultMaxUploadBytes is the size at which the data of an ingest is split into another job.
+	// Salesforce recommends 100 MB to account for the ~50% growth of base64 encoding.
+	DefaultMaxUploadBytes = 100 * 1024 * 1024
+	// MaxUploadCharacters is the greatest number of characters in the data of a job
+	MaxUploadCharacters = 100 * 1000 * 1000
+)
+
+// ErrRowTooLarge is returned when a single row cannot fit within the upload limits of a job
+var ErrRowTooLarge = errors.New("CSV row exceeds the upload limits of a bulk job")
+
+// base64Len is the length of n bytes once base64 encoded
+func base64Len(n int) int {
+	return (n + 2) / 3 * 4
+}
+
+// split is a portion of the input of an ingest uploaded as a single job
+type split struct {
+	index int
+	data  []byte
+	// keys identify each row so that results can be returned in the order of the input
+	keys []string
+}
+
+// splitter reads the rows of a CSV and groups them into splits beneath the upload limits
+type splitter struct {
+	reader   *csv.Reader
+	header   []string
+	encoded  []byte
+	maxBytes int
+	maxChars int
+	maxRows  int
+	comma    rune
+	crlf     bool
+	pending  []string
+	index    int
+	// line is the number of rows read, excluding the header
+	line int
+	// correlate is the index of the correlation column, or -1 if there is none
+	correlate int
+	// inject is true if the correlation column was appended to the input
+	inject bool
+	// correlations are the correlation keys of the rows read, in order
+	correlations []string
+}
+
+// newSplitter reads the header of r. The upload limits of opts are clamped to those of Salesforce.
+func newSplitter(r io.Reader, opts IngestOptions) (*splitter, error) {
+	s := &splitter{
+		reader:   newCSVReader(r, opts.ColumnDelimiter),
+		maxBytes: opts.MaxUploadBytes,
+		maxChars: opts.MaxUploadCharacters,
+		comma:    opts.ColumnDelimiter.rune(),
+		crlf:     opts.LineEnding == LineEndingCRLF,
+	}
+
+	if s.maxBytes <= 0 {
+		s.maxBytes = DefaultMaxUploadBytes
+	}
+
+	if base64Len(s.maxBytes) > MaxEncodedUploadBytes {
+		s.maxBytes = MaxEncodedUploadBytes / 4 * 3
+	}
+
+	if s.maxChars <= 0 || s.maxChars > MaxUploadCharacters {
+		s.maxChars = MaxUploadCharacters
+	}
+
+	header, err := s.reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV data must contain a header row")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	s.correlate = -1
+	if opts.CorrelationColumn != "" {
+		if s.correlate = columnIndex(header, opts.CorrelationColumn); s.correlate < 0 {
+			header = append(header, opts.CorrelationColumn)
+			s.correlate, s.inject = len(header)-1, true
+		}
+	}
+
+	s.header = header
+	s.encoded = s.encode(header)
+	return s, nil
+}
+
+// next returns the next split, or io.EOF once every row has been read
+func (s *splitter) next() (*split, error) {
+	buf := bytes.NewBuffer(append([]byte{}, s.encoded...))
+	chars := utf8.RuneCount(s.encoded)
+	sp := &split{index: s.index}
+
+	for {
+		row := s.pending
+		s.pending = nil
+		if row == nil {
+			var err error
+			row, err = s.reader.Read()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("reading CSV: %w", err)
+			}
+			s.line++
+			row = s.correlateRow(row)
+		}
+
+		if s.maxRows > 0 && len(sp.keys) >= s.maxRows {
+			s.pending = row
+			break
+		}
+
+		encoded := s.encode(row)
+		rowChars := utf8.RuneCount(encoded)
+		if buf.Len()+len(encoded) > s.maxBytes || chars+rowChars > s.maxChars {
+			if len(sp.keys) == 0 {
+				return nil, fmt.Errorf("row %d: %w", s.line, ErrRowTooLarge)
+			}
+
+			s.pending = row
+			break
+		}
+
+		buf.Write(encoded)
+		chars += rowChars
+		sp.keys = append(sp.keys, rowKey(row))
+	}
+
+	if len(sp.keys) == 0 {
+		return nil, io.EOF
+	}
+
+	s.index++
+	sp.data = buf.Bytes()
+	return sp, nil
+}
+
+// correlateRow appends the line number of row as its key if the correlation column is injected,
+// and records the correlation key of row
+func (s *splitter) correlateRow(row []string) []string {
+	if s.inject {
+		row = append(row, strconv.Itoa(s.line))
+	}
+
+	if s.correlate >= 0 && s.correlate < len(row) {
+		s.correlations = append(s.correlations, row[s.correlate])
+	}
+
+	return row
+}
+
+// columnIndex returns the index of column within header, or -1 if it is absent
+func columnIndex(header []string, column string) int {
+	for i, name := range header {
+		if name == column {
+			return i
+		}
+	}
+	return -1
+}
+
+// encode returns a single CSV row using the delimiter and line ending of the job
+func (s *splitter) encode(row []string) []byte {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = s.comma
+	writer.UseCRLF = s.crlf
+	writer.Write(row)
+	writer.Flush()
+	return buf.Bytes()
+}
+
+// rowKey identifies a row by its values, which Salesforce echoes within the results of a job
+func rowKey(row []string) string {
+	return strings.Join(row, "\x00")
+}
+
+// resultTable is the parsed results of a job
+type resultTable struct {
+	header []string
+	rows   [][]string
+}
+
+// resultKinds are the results downloaded for each job along with the number of sf__ columns
+// which precede the echoed input columns
+var resultKinds = []struct {
+	path   string
+	prefix int
+}{
+	{"successfulResults", 2},
+	{"failedResults", 2},
+	{"unprocessedrecords", 0},
+}
+
+// parseResults parses the CSV results of a job and orders its rows as they were in the split
+func parseResults(contents []byte, d delimiter, prefix int, sp *split) (*resultTable, error) {
+	records, err := newCSVReader(bytes.NewReader(contents), d).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	table := &resultTable{}
+	if len(records) == 0 {
+		return table, nil
+	}
+
+	table.header, table.rows = records[0], records[1:]
+	if sp != nil {
+		orderRows(table.rows, prefix, sp.keys)
+	}
+
+	return table, nil
+}
+
+// orderRows sorts rows into the order of keys, the rows of the input. Rows which cannot be
+// matched to the input remain after those which can in the order they were returned.
+func orderRows(rows [][]string, prefix int, keys []string) {
+	positions := make(map[string][]int, len(keys))
+	for i, key := range keys {
+		positions[key] = append(positions[key], i)
+	}
+
+	ordered := make([][]string, len(keys))
+	var unmatched [][]string
+	for _, row := range rows {
+		var key string
+		if len(row) >= prefix {
+			key = rowKey(row[prefix:])
+		}
+
+		if indexes := positions[key]; len(indexes) > 0 {
+			ordered[indexes[0]] = row
+			positions[key] = indexes[1:]
+			continue
+		}
+
+		unmatched = append(unmatched, row)
+	}
+
+	i := 0
+	for _, row := range ordered {
+		if row != nil {
+			rows[i] = row
+			i++
+		}
+	}
+	copy(rows[i:], unmatched)
+}
+
+// mergeResults concatenates tables in order and encodes them as CSV using delimiter d
+func mergeResults(tables []*resultTable, d delimiter) []byte {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = d.rune()
+
+	var wroteHeader bool
+	for _, table := range tables {
+		if table == nil || table.header == nil {
+			continue
+		}
+
+		if !wroteHeader {
+			writer.Write(table.header)
+			wroteHeader = true
+		}
+
+		writer.WriteAll(table.rows)
+	}
+
+	writer.Flush()
+	return buf.Bytes()
+}
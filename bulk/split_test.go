@@ -0,0 +1,72 @@
+package bulk_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestSplits(t *testing.T) {
+	server := newFakeBulk(t)
+	server.fail = func(job *bulk.GetJobInfoResponse, row map[string]string) string {
+		if strings.HasSuffix(row["Name"], "3") || strings.HasSuffix(row["Name"], "6") {
+			return "FIELD_CUSTOM_VALIDATION_EXCEPTION:invalid name:--"
+		}
+		return ""
+	}
+
+	var input bytes.Buffer
+	input.WriteString("Name,Description\n")
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&input, "Account%d,\"line one\nline two\"\n", i)
+	}
+
+	result, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, &input, &bulk.IngestOptions{
+		PollInterval:   time.Millisecond,
+		MaxUploadBytes: 100,
+		Concurrency:    2,
+	})
+
+	require.Nil(t, err)
+	require.Len(t, result.Jobs, 5)
+	require.Equal(t, 10, result.NumRecordsProcessed)
+	require.Equal(t, 2, result.NumRecordsFailed)
+
+	for _, id := range server.order {
+		upload := server.job(id).uploads[0]
+		require.True(t, bytes.HasPrefix(upload, []byte("Name,Description\n")))
+		require.LessOrEqual(t, len(upload), 100)
+	}
+
+	successful, err := result.Successful().ReadAll()
+	require.Nil(t, err)
+	var names []string
+	for _, row := range successful[1:] {
+		names = append(names, row[2])
+		require.Equal(t, "line one\nline two", row[3])
+	}
+	require.Equal(t, []string{"Account0", "Account1", "Account2", "Account4", "Account5", "Account7", "Account8", "Account9"}, names)
+
+	failed, err := result.Failed().ReadAll()
+	require.Nil(t, err)
+	require.Len(t, failed, 3)
+	require.Equal(t, "Account3", failed[1][2])
+	require.Equal(t, "Account6", failed[2][2])
+}
+
+func TestIngestRowTooLarge(t *testing.T) {
+	server := newFakeBulk(t)
+	_, err := bulk.Ingest(context.Background(), server.builder(), "Account", bulk.OperationInsert, strings.NewReader("Name\n"+strings.Repeat("a", 100)+"\n"), &bulk.IngestOptions{
+		MaxUploadCharacters: 50,
+	})
+
+	require.True(t, errors.Is(err, bulk.ErrRowTooLarge))
+	require.Len(t, server.order, 0)
+}
@@ -33,6 +33,10 @@ type contentType string
 const (
 	// ContentTypeCSV ...
 	ContentTypeCSV contentType = "CSV"
+	// ContentTypeJSON is supported by Bulk API 1.0 jobs only
+	ContentTypeJSON contentType = "JSON"
+	// ContentTypeXML is supported by Bulk API 1.0 jobs only
+	ContentTypeXML contentType = "XML"
 )
 
 type lineEnding string
@@ -110,6 +114,8 @@ const (
 	JobStateComplete jobState = "JobComplete"
 	// JobStateFailed — Some records in the job failed. Job data that was successfully processed isn’t rolled back.
 	JobStateFailed jobState = "Failed"
+	// JobStateClosed — No new batches can be added to this Bulk API 1.0 job.
+	JobStateClosed jobState = "Closed"
 )
 
 // UpdateJobRequest Closes or aborts a job. If you close a job, Salesforce queues the job and
@@ -199,6 +205,8 @@ type GetJobInfoResponse struct {
 	// NumRecordsProcessed The number of records already processed.
 	// This property is of type int in API version 46.0 and earlier.
 	NumRecordsProcessed int `json:"numRecordsProcessed"`
+	// ErrorMessage The error message shown for jobs with errors.
+	ErrorMessage string `json:"errorMessage"`
 	// Retries The number of times that Salesforce attempted to save the results of an operation. The repeated attempts are due to a problem, such as a lock contention.
 	Retries int `json:"retries"`
 	TotalProcessingTime int `json:"totalProcessingTime"`
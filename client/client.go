@@ -28,6 +28,7 @@ type Client struct {
 	apiUsageLimit float64
 	dailyAPILimit int64
 	usedAPILast24 int64
+	planCheck     *queryPlanCheck
 }
 
 // Limiter defines behavior for ratelimiting outgoing http requests to the Salesforce API
@@ -118,12 +119,17 @@ func (c *Client) IsWithinAPIUsageLimit() error {
 // Do proxies call to http.Client.Do with the following extended behavior:
 // * Requests are only made if IsWithinAPIUsageLimit does not return an error
 // * Any configured Limiter allows a request
+// * Queries are explained first if WithQueryPlanCheck is configured
 // * Responses are parsed in order to update client.UsedAPILast24
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	if err := c.IsWithinAPIUsageLimit(); err != nil {
 		return nil, err
 	}
 
+	if err := c.checkQueryPlan(req); err != nil {
+		return nil, err
+	}
+
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
@@ -269,6 +275,17 @@ func (c *Client) querySubsequentURLs(paginatedURLs ...string) (payloads [][]byte
 	return payloads, nil 
 }
 
+// Version returns the API version of subsequent requests, such as "50.0"
+func (c *Client) Version() string {
+	return strings.TrimPrefix(c.apiVersion, "v")
+}
+
+// InstanceURL returns the URL of the Salesforce instance requests are sent to, such as
+// "https://na1.salesforce.com"
+func (c *Client) InstanceURL() string {
+	return c.instanceURL
+}
+
 // URL parses a url segment into a fully qualified Salesforce API request using client.instanceURL,
 // client.apiPathPrefix, and client.apiVersion
 //
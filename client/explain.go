@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/requests"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+)
+
+// explain.go retrieves query plans from the explain parameter of the query endpoint and
+// optionally checks the selectivity of every query sent by a Client.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/dome_query_explain.htm
+
+// LeadingOperationTableScan is the leading operation of a plan which scans every row of an object
+const LeadingOperationTableScan = "TableScan"
+
+// ErrNonSelectiveQuery is returned by a Client configured WithQueryPlanCheck(n, true) when the plan
+// of a query is not selective
+var ErrNonSelectiveQuery = errors.New("non-selective query")
+
+// QueryPlans is the response of an explain request
+type QueryPlans struct {
+	// Plans are sorted by RelativeCost, the first plan is the one Salesforce will use
+	Plans       []*QueryPlan `json:"plans"`
+	SourceQuery string       `json:"sourceQuery"`
+}
+
+// QueryPlan describes a single way Salesforce could execute a query
+type QueryPlan struct {
+	// Cardinality is the estimated number of records the leading operation type would return
+	Cardinality int `json:"cardinality"`
+	// Fields are the indexed fields used by the query, if any
+	Fields []string `json:"fields"`
+	// LeadingOperationType is the primary operation type used to optimize the query i.e. Index,
+	// Other, Sharing, or TableScan
+	LeadingOperationType string `json:"leadingOperationType"`
+	// Notes explain why an index or optimization could not be used
+	Notes []*QueryPlanNote `json:"notes"`
+	// RelativeCost is the cost of the plan relative to the selectivity threshold, a cost above 1
+	// means the query won't be selective
+	RelativeCost float64 `json:"relativeCost"`
+	// SobjectCardinality is the approximate record count of the queried object
+	SobjectCardinality int `json:"sobjectCardinality"`
+	// SobjectType is the name of the queried object
+	SobjectType string `json:"sobjectType"`
+}
+
+// QueryPlanNote ...
+type QueryPlanNote struct {
+	Description   string   `json:"description"`
+	Fields        []string `json:"fields"`
+	TableEnumOrID string   `json:"tableEnumOrId"`
+}
+
+// IsSelective reports whether the plan uses an index or another optimization rather than a
+// full scan of the object
+func (p *QueryPlan) IsSelective() bool {
+	return p.RelativeCost <= 1 && p.LeadingOperationType != LeadingOperationTableScan
+}
+
+// Best returns the plan Salesforce will use to execute the query, or nil if there are none
+func (q *QueryPlans) Best() *QueryPlan {
+	if q == nil || len(q.Plans) == 0 {
+		return nil
+	}
+
+	return q.Plans[0]
+}
+
+// NonSelectiveQueryError describes a query whose best plan is not selective
+type NonSelectiveQueryError struct {
+	Query string
+	Plan  *QueryPlan
+}
+
+// Error ...
+func (e *NonSelectiveQueryError) Error() string {
+	return fmt.Sprintf(
+		"%s: %s on %s (%d records) has relative cost %g: %s",
+		ErrNonSelectiveQuery, e.Plan.LeadingOperationType, e.Plan.SobjectType, e.Plan.SobjectCardinality, e.Plan.RelativeCost, e.Query,
+	)
+}
+
+// Unwrap allows errors.Is(err, client.ErrNonSelectiveQuery)
+func (e *NonSelectiveQueryError) Unwrap() error {
+	return ErrNonSelectiveQuery
+}
+
+// Explain returns the query plans Salesforce considered for query without executing it
+func (c *Client) Explain(ctx context.Context, query soql.Builder) (*QueryPlans, error) {
+	sql, err := query.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.explain(ctx, sql)
+}
+
+func (c *Client) explain(ctx context.Context, sql string) (plans *QueryPlans, err error) {
+	_, err = requests.
+		Sender(c).
+		URL("query").
+		Context(ctx).
+		Param("explain", sql).
+		JSON(&plans)
+
+	if err != nil {
+		return nil, fmt.Errorf("explain: %w", err)
+	}
+
+	return plans, nil
+}
+
+// queryPlanCheck is the configuration set by WithQueryPlanCheck
+type queryPlanCheck struct {
+	minRecords int
+	reject     bool
+	logf       func(format string, v ...interface{})
+}
+
+// checkQueryPlan explains the query of a request to the query or queryAll endpoints and logs
+// or rejects it if its best plan is not selective. Requests for subsequent pages of results
+// carry no query and are not checked.
+func (c *Client) checkQueryPlan(req *http.Request) error {
+	if c.planCheck == nil || req.Method != http.MethodGet {
+		return nil
+	}
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	if !strings.HasSuffix(path, "/query") && !strings.HasSuffix(path, "/queryAll") {
+		return nil
+	}
+
+	sql := req.URL.Query().Get("q")
+	if sql == "" {
+		return nil
+	}
+
+	plans, err := c.explain(req.Context(), sql)
+	if err != nil {
+		return err
+	}
+
+	plan := plans.Best()
+	if plan == nil || plan.SobjectCardinality < c.planCheck.minRecords || plan.IsSelective() {
+		return nil
+	}
+
+	nonSelective := &NonSelectiveQueryError{Query: sql, Plan: plan}
+	if c.planCheck.reject {
+		return nonSelective
+	}
+
+	c.planCheck.logf("%s", nonSelective.Error())
+	return nil
+}
+
+// WithQueryPlanCheck explains every query before it is sent and, for objects with at least
+// minRecords records, either logs or rejects (with a *NonSelectiveQueryError) queries whose
+// plan has a relative cost over 1 or leads with a TableScan. Each checked query costs an
+// additional API request.
+//
+// Default: disabled
+func WithQueryPlanCheck(minRecords int, reject bool) Option {
+	return func(client *Client) error {
+		if minRecords < 0 {
+			return errors.New("WithQueryPlanCheck(): minRecords cannot be negative")
+		}
+
+		client.planCheck = &queryPlanCheck{minRecords: minRecords, reject: reject, logf: log.Printf}
+		return nil
+	}
+}
+
+// WithQueryPlanLogger sets the function used by WithQueryPlanCheck to log non-selective queries.
+// It must be applied after WithQueryPlanCheck.
+//
+// Default: log.Printf
+func WithQueryPlanLogger(logf func(format string, v ...interface{})) Option {
+	return func(client *Client) error {
+		if client.planCheck == nil {
+			return errors.New("WithQueryPlanLogger(): WithQueryPlanCheck must be applied first")
+		}
+
+		if logf == nil {
+			return errors.New("WithQueryPlanLogger(): logf must not be nil")
+		}
+
+		client.planCheck.logf = logf
+		return nil
+	}
+}
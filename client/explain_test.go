@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/client"
+	"github.com/stretchr/testify/require"
+)
+
+// explainServer answers explain requests with a TableScan plan for queries without a WHERE
+// clause and an Index plan otherwise, reporting sobjectCardinality records for every object.
+// It counts the explain requests and the queries it executes.
+type explainServer struct {
+	mu                 sync.Mutex
+	server             *httptest.Server
+	sobjectCardinality int
+	explained          int
+	queried            []string
+}
+
+func newExplainServer(t *testing.T, sobjectCardinality int) *explainServer {
+	s := &explainServer{sobjectCardinality: sobjectCardinality}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *explainServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sql := r.URL.Query().Get("explain"); sql != "" {
+		s.explained++
+		plan := &client.QueryPlan{LeadingOperationType: "Index", RelativeCost: 0.1, SobjectCardinality: s.sobjectCardinality, SobjectType: "Lead"}
+		if !strings.Contains(sql, "WHERE") {
+			plan.LeadingOperationType, plan.RelativeCost = client.LeadingOperationTableScan, 2.5
+		}
+		json.NewEncoder(w).Encode(&client.QueryPlans{Plans: []*client.QueryPlan{plan}, SourceQuery: sql})
+		return
+	}
+
+	s.queried = append(s.queried, r.URL.Path+"?"+r.URL.RawQuery)
+	w.Write([]byte(`{"totalSize":0,"done":true,"records":[]}`))
+}
+
+// do sends a GET request for path with the query parameter q, if not empty
+func (s *explainServer) do(c *client.Client, path string, q string) error {
+	uri := c.URL(path)
+	if q != "" {
+		uri += "?" + url.Values{"q": []string{q}}.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func newPlanCheckClient(t *testing.T, s *explainServer, minRecords int, reject bool, logged *[]string) *client.Client {
+	c, err := client.New(
+		client.WithHTTPClient(s.server.Client()),
+		client.WithInstanceURL(s.server.URL),
+		client.WithVersion("51.0"),
+		client.WithQueryPlanCheck(minRecords, reject),
+		client.WithQueryPlanLogger(func(format string, v ...interface{}) {
+			*logged = append(*logged, fmt.Sprintf(format, v...))
+		}),
+	)
+	require.Nil(t, err)
+	return c
+}
+
+func TestQueryPlanCheckReject(t *testing.T) {
+	server := newExplainServer(t, 500000)
+	var logged []string
+	c := newPlanCheckClient(t, server, 100000, true, &logged)
+
+	err := server.do(c, "query", "SELECT Id FROM Lead")
+	require.True(t, errors.Is(err, client.ErrNonSelectiveQuery))
+
+	var nonSelective *client.NonSelectiveQueryError
+	require.True(t, errors.As(err, &nonSelective))
+	require.Equal(t, "SELECT Id FROM Lead", nonSelective.Query)
+	require.Equal(t, client.LeadingOperationTableScan, nonSelective.Plan.LeadingOperationType)
+	require.Empty(t, server.queried, "a rejected query is not sent")
+	require.Empty(t, logged)
+
+	require.Nil(t, server.do(c, "queryAll", "SELECT Id FROM Lead WHERE Email = 'a@example.com'"))
+	require.Equal(t, 2, server.explained)
+	require.Len(t, server.queried, 1)
+}
+
+func TestQueryPlanCheckLog(t *testing.T) {
+	server := newExplainServer(t, 500000)
+	var logged []string
+	c := newPlanCheckClient(t, server, 100000, false, &logged)
+
+	require.Nil(t, server.do(c, "query", "SELECT Id FROM Lead"))
+	require.Len(t, logged, 1)
+	require.Contains(t, logged[0], "non-selective query")
+	require.Contains(t, logged[0], "SELECT Id FROM Lead")
+	require.Len(t, server.queried, 1, "a logged query is still sent")
+}
+
+func TestQueryPlanCheckMinRecords(t *testing.T) {
+	server := newExplainServer(t, 5000)
+	var logged []string
+	c := newPlanCheckClient(t, server, 100000, true, &logged)
+
+	require.Nil(t, server.do(c, "query", "SELECT Id FROM Lead"))
+	require.Equal(t, 1, server.explained)
+	require.Len(t, server.queried, 1, "objects with fewer than minRecords records are not checked")
+	require.Empty(t, logged)
+}
+
+func TestQueryPlanCheckSkipsQueryMore(t *testing.T) {
+	server := newExplainServer(t, 500000)
+	var logged []string
+	c := newPlanCheckClient(t, server, 0, true, &logged)
+
+	require.Nil(t, server.do(c, "query/01gD0000002HU6KIAW-2000", ""))
+	require.Nil(t, server.do(c, "sobjects/Lead", ""))
+	require.Equal(t, 0, server.explained, "pages of results and other endpoints are not explained")
+	require.Len(t, server.queried, 2)
+}
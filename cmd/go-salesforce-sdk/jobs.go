@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk"
+	"github.com/beeekind/go-salesforce-sdk/bulk"
+	"github.com/beeekind/go-salesforce-sdk/requests"
+)
+
+// jobsCommand lists the bulk jobs of the org matching its flags
+func jobsCommand() {
+	flags := flag.NewFlagSet("jobs", flag.ExitOnError)
+	queries := flags.Bool("queries", false, "list query jobs rather than ingest jobs")
+	states := flags.String("state", "", "comma separated states of the jobs listed i.e. Open,UploadComplete")
+	object := flags.String("object", "", "object of the jobs listed")
+	createdBy := flags.String("created-by", "", "ID of the user who created the jobs listed")
+	olderThan := flags.Duration("older-than", 0, "list only jobs created longer ago than this i.e. 24h")
+	flags.Parse(os.Args[2:])
+
+	filter := &bulk.JobFilter{Object: *object, CreatedByID: *createdBy, OlderThan: *olderThan}
+	if *states != "" {
+		filter.States = strings.Split(*states, ",")
+	}
+
+	list := bulk.EachJob
+	if *queries {
+		list = bulk.EachQuery
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tTYPE\tOPERATION\tOBJECT\tSTATE\tCREATED\tCREATED BY")
+	err := list(requests.Sender(salesforce.DefaultClient), filter, func(job *bulk.JobInfo) error {
+		_, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", job.ID, job.JobType, job.Operation, job.Object, job.State, job.CreatedDate.Value.Format(time.RFC3339), job.CreatedByID)
+		return err
+	})
+
+	writer.Flush()
+	if err != nil {
+		fmt.Printf("Error listing bulk jobs: %s\n", err.Error())
+	}
+}
+
+// cleanupCommand aborts stale bulk jobs and deletes those past their retention
+func cleanupCommand() {
+	flags := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	queries := flags.Bool("queries", false, "clean up query jobs rather than ingest jobs")
+	object := flags.String("object", "", "clean up only the jobs of this object")
+	createdBy := flags.String("created-by", "", "clean up only the jobs created by this user ID")
+	abortAfter := flags.Duration("abort-after", bulk.DefaultAbortAfter, "abort Open or UploadComplete jobs created longer ago than this")
+	retention := flags.Duration("retention", bulk.DefaultRetention, "delete finished jobs created longer ago than this")
+	dryRun := flags.Bool("dry-run", false, "list the jobs which would be aborted or deleted without changing them")
+	flags.Parse(os.Args[2:])
+
+	cleanup := bulk.CleanupJobs
+	if *queries {
+		cleanup = bulk.CleanupQueries
+	}
+
+	result, err := cleanup(context.Background(), requests.Sender(salesforce.DefaultClient), &bulk.CleanupOptions{
+		Object:      *object,
+		CreatedByID: *createdBy,
+		AbortAfter:  *abortAfter,
+		Retention:   *retention,
+		DryRun:      *dryRun,
+	})
+
+	if err != nil {
+		fmt.Printf("Error cleaning up bulk jobs: %s\n", err.Error())
+		return
+	}
+
+	aborted, deleted := "aborted", "deleted"
+	if *dryRun {
+		aborted, deleted = "would be aborted", "would be deleted"
+	}
+
+	for _, job := range result.Aborted {
+		fmt.Printf("%s %s %s (%s)\n", job.ID, job.Object, aborted, job.State)
+	}
+
+	for _, job := range result.Deleted {
+		fmt.Printf("%s %s %s (%s)\n", job.ID, job.Object, deleted, job.State)
+	}
+
+	for id, err := range result.Failures {
+		fmt.Printf("%s could not be cleaned up: %s\n", id, err.Error())
+	}
+
+	fmt.Printf("%d jobs %s, %d jobs %s, %d failures\n", len(result.Aborted), aborted, len(result.Deleted), deleted, len(result.Failures))
+}
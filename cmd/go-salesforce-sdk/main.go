@@ -16,7 +16,7 @@ import (
 
 var objectDocumentationTmpl = "https://developer.salesforce.com/docs/atlas.en-us.object_reference.meta/object_reference/sforce_api_objects_%s.htm"
 var toolingDocumentationTmpl = "https://developer.salesforce.com/docs/atlas.en-us.api_tooling.meta/api_tooling/tooling_api_objects_%s.htm"
-var invalidCommandText = "expected command 'ls' or 'generate {objectName}' run command with --help for more info"
+var invalidCommandText = "expected command 'ls', 'generate {objectName}', 'jobs' or 'cleanup' run command with --help for more info"
 
 var helpText = `
 Welcome to the go-salesforce-sdk CLI!
@@ -40,7 +40,7 @@ SALESFORCE_SDK_USERNAME
 SALESFORCE_SDK_PASSWORD
 SALESFORCE_SDK_SECURITY_TOKEN
 
-There are currently two commands:
+There are currently four commands:
 
 ---
 ls 
@@ -56,11 +56,28 @@ generate type definitions for the given salesforce object i.e. {Lead, User, Acco
 relationshipLevel denotes how many relations should be generated. Because salesforce objects
 are so deeply connected we suggest 0 or 1 for this value.
 
+---
+jobs [-queries] [-state {state,...}] [-object {objectName}] [-created-by {userID}] [-older-than {duration}]
+---
+
+list the bulk ingest jobs, or query jobs with -queries, of your org across every page of results,
+filtered by state i.e. {Open, UploadComplete, JobComplete}, object, creator and age i.e. {24h}.
+
+---
+cleanup [-queries] [-object {objectName}] [-created-by {userID}] [-abort-after {duration}] [-retention {duration}] [-dry-run]
+---
+
+abort bulk jobs stuck in Open or UploadComplete for longer than -abort-after (24h by default) and
+delete finished jobs older than -retention (168h by default). -dry-run lists the jobs which would
+be aborted or deleted without changing them.
+
 Examples:
 
 go-salesforce-sdk ls 
 go-salesforce-sdk generate Lead ./ leads 0
 go-salesforce-sdk generate Account /path/to/desired/output accounts 1
+go-salesforce-sdk jobs -state Open,UploadComplete -older-than 24h
+go-salesforce-sdk cleanup -object Account -retention 48h -dry-run
 `
 
 func panicIfErr(err error) {
@@ -87,6 +104,10 @@ func main() {
 		lsCommand()
 	case "generate":
 		generateCommand()
+	case "jobs":
+		jobsCommand()
+	case "cleanup":
+		cleanupCommand()
 	default:
 		fmt.Println(invalidCommandText)
 	}
@@ -0,0 +1,294 @@
+package composite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/metadata"
+	"github.com/beeekind/go-salesforce-sdk/requests"
+	"github.com/lann/builder"
+)
+
+// batch.go composes requests for the Composite Batch resource, which executes up to 25
+// independent subrequests in a single API call. Unlike the composite resource, subrequests cannot
+// reference one another and each returns its own status code and result body.
+//
+// Documentation:
+// https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_composite_batch.htm
+
+// MaxBatchSubrequests is the maximum number of subrequests of a single batch request
+const MaxBatchSubrequests = 25
+
+var batchEndpoint = metadata.CompositeEndpoint + "/batch"
+
+var (
+	// ErrTooManySubrequests is returned for batch requests with more than MaxBatchSubrequests
+	// subrequests
+	ErrTooManySubrequests = fmt.Errorf("a batch request may contain at most %d subrequests", MaxBatchSubrequests)
+	// ErrNoBatchResult is returned when decoding a result which is not in a BatchResponse
+	ErrNoBatchResult = errors.New("batch response has no result at index")
+)
+
+// BatchBuilder ...
+type BatchBuilder builder.Builder
+
+// BatchBase ...
+var BatchBase = BatchBuilder(builder.EmptyBuilder)
+
+func init() {
+	builder.Register(BatchBuilder{}, BatchRequest{})
+}
+
+// BatchRequest ...
+type BatchRequest struct {
+	Client        client            `json:"-"`
+	HaltOnError   bool              `json:"haltOnError"`
+	BatchRequests []BatchSubrequest `json:"batchRequests"`
+}
+
+// BatchSubrequest ...
+type BatchSubrequest struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	RichInput interface{} `json:"richInput,omitempty"`
+	// sqlizer, if not nil, is converted to the q parameter of URL
+	sqlizer SQLizer
+}
+
+// BatchResponse https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/responses_composite_batch.htm
+type BatchResponse struct {
+	HasErrors bool           `json:"hasErrors"`
+	Results   []*BatchResult `json:"results"`
+}
+
+// Err returns the error of the first result with a status code > 299
+func (r *BatchResponse) Err() error {
+	for i, result := range r.Results {
+		if err := result.Err(); err != nil {
+			return fmt.Errorf("subrequest %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Decode unmarshals the result of the subrequest at index into dst, returning the error of the
+// result instead if the subrequest failed
+func (r *BatchResponse) Decode(index int, dst interface{}) error {
+	if index < 0 || index >= len(r.Results) {
+		return fmt.Errorf("%w %d", ErrNoBatchResult, index)
+	}
+
+	return r.Results[index].Decode(dst)
+}
+
+// BatchResult is the outcome of a single subrequest
+type BatchResult struct {
+	StatusCode int             `json:"statusCode"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// Err returns the errors of r if its status code is > 299
+func (r *BatchResult) Err() error {
+	if r.StatusCode <= 299 {
+		return nil
+	}
+
+	var errs Errors
+	if err := json.Unmarshal(r.Result, &errs); err != nil || len(errs) == 0 {
+		return &requests.RequestError{Code: r.StatusCode, Contents: r.Result}
+	}
+
+	var buff strings.Builder
+	for _, e := range errs {
+		buff.WriteString(fmt.Sprintf("%v: %s: %s", r.StatusCode, e.ErrorCode, e.Message))
+	}
+
+	return errors.New(buff.String())
+}
+
+// Decode unmarshals the result of r into dst. Results without a body, such as those of PATCH and
+// DELETE subrequests, leave dst unchanged.
+func (r *BatchResult) Decode(dst interface{}) error {
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	if len(r.Result) == 0 || string(r.Result) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(r.Result, dst)
+}
+
+// Request marshals the given BatchBuilder into an http.Request object
+func (b BatchBuilder) Request() (*http.Request, error) {
+	data := builder.GetStruct(b).(BatchRequest)
+	return data.request()
+}
+
+// request ...
+func (r BatchRequest) request() (*http.Request, error) {
+	if r.Client == nil {
+		return nil, fmt.Errorf("BatchBuilder must have a non-nil client to call Response()")
+	}
+
+	if len(r.BatchRequests) > MaxBatchSubrequests {
+		return nil, ErrTooManySubrequests
+	}
+
+	// batch subrequest urls are relative to /services/data. The query of a subrequest is
+	// appended once its endpoint is normalized, as the query may contain any text.
+	for i := 0; i < len(r.BatchRequests); i++ {
+		uri, err := normalizeURL(r.Client, r.BatchRequests[i].URL)
+		if err != nil {
+			return nil, fmt.Errorf("subrequest %d: %w", i, err)
+		}
+		r.BatchRequests[i].URL = strings.TrimPrefix(uri, "/services/data/")
+
+		if sqlizer := r.BatchRequests[i].sqlizer; sqlizer != nil {
+			soql, err := sqlizer.ToSQL()
+			if err != nil {
+				return nil, fmt.Errorf("subrequest %d: %w", i, err)
+			}
+			r.BatchRequests[i].URL += "?" + url.Values{"q": []string{soql}}.Encode()
+		}
+	}
+
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling composite.BatchRequest: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.Client.URL(batchEndpoint), bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, fmt.Errorf("converting composite.BatchRequest to http.Request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// Response ...
+func (b BatchBuilder) Response() (*http.Response, error) {
+	data := builder.GetStruct(b).(BatchRequest)
+
+	if data.Client == nil {
+		return nil, fmt.Errorf("BatchBuilder must have a non-nil client to call Response()")
+	}
+
+	req, err := b.Request()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Client.Do(req)
+}
+
+// Send executes the batch and decodes the result of each successful subrequest into the
+// destination of the same index, skipping nil destinations:
+//
+//	var lead leads.Lead
+//	var result types.QueryResponse
+//	response, err := composite.
+//		Batch(salesforce.DefaultClient).
+//		Get("Lead/00Q5e000001ABCDEAA").
+//		Query(soql.Select("Id").From("Lead").Limit(10)).
+//		Delete("Lead/00Q5e000001ABCFEAA").
+//		Send(&lead, &result)
+//
+// The error of the first failed subrequest is returned along with the response.
+func (b BatchBuilder) Send(dst ...interface{}) (*BatchResponse, error) {
+	response, err := b.Response()
+	if err != nil {
+		return nil, err
+	}
+
+	var batch BatchResponse
+	if _, err := requests.Unmarshal(response, &batch); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(dst) && i < len(batch.Results); i++ {
+		if dst[i] == nil || batch.Results[i].Err() != nil {
+			continue
+		}
+
+		if err := batch.Results[i].Decode(dst[i]); err != nil {
+			return &batch, fmt.Errorf("decoding result of subrequest %d: %w", i, err)
+		}
+	}
+
+	return &batch, batch.Err()
+}
+
+// Batch ...
+func Batch(client client) BatchBuilder {
+	return BatchBase.Client(client)
+}
+
+// Client ...
+func (b BatchBuilder) Client(client client) BatchBuilder {
+	return builder.Set(b, "Client", client).(BatchBuilder)
+}
+
+// HaltOnError stops the execution of subsequent subrequests once a subrequest fails, whose
+// results then have the status code 412
+func (b BatchBuilder) HaltOnError(haltOnError bool) BatchBuilder {
+	return builder.Set(b, "HaltOnError", haltOnError).(BatchBuilder)
+}
+
+// Add creates a new BatchSubrequest instance from the given parameters and appends the instance
+// to builder.BatchRequests ([]BatchSubrequest)
+func (b BatchBuilder) Add(method string, URL string, richInput interface{}) BatchBuilder {
+	subrequest := BatchSubrequest{
+		Method:    method,
+		URL:       URL,
+		RichInput: richInput,
+	}
+	return builder.Append(b, "BatchRequests", subrequest).(BatchBuilder)
+}
+
+// Get ...
+func (b BatchBuilder) Get(objectName string) BatchBuilder {
+	return b.Add(http.MethodGet, fmt.Sprintf("%s/%s", metadata.SobjectsEndpoint, objectName), nil)
+}
+
+// Post ...
+func (b BatchBuilder) Post(objectName string, body map[string]interface{}) BatchBuilder {
+	return b.Add(http.MethodPost, fmt.Sprintf("%s/%s", metadata.SobjectsEndpoint, objectName), body)
+}
+
+// Patch ...
+func (b BatchBuilder) Patch(objectName string, body map[string]interface{}) BatchBuilder {
+	return b.Add(http.MethodPatch, fmt.Sprintf("%s/%s", metadata.SobjectsEndpoint, objectName), body)
+}
+
+// Delete ...
+func (b BatchBuilder) Delete(objectName string) BatchBuilder {
+	return b.Add(http.MethodDelete, fmt.Sprintf("%s/%s", metadata.SobjectsEndpoint, objectName), nil)
+}
+
+// Query appends a GET subrequest for the query composed by sqlizer
+func (b BatchBuilder) Query(sqlizer SQLizer) BatchBuilder {
+	return b.query(metadata.QueryEndpoint, sqlizer)
+}
+
+// QueryAll is Query including deleted and archived records
+func (b BatchBuilder) QueryAll(sqlizer SQLizer) BatchBuilder {
+	return b.query(metadata.QueryAllEndpoint, sqlizer)
+}
+
+func (b BatchBuilder) query(endpoint string, sqlizer SQLizer) BatchBuilder {
+	subrequest := BatchSubrequest{
+		Method:  http.MethodGet,
+		URL:     endpoint,
+		sqlizer: sqlizer,
+	}
+	return builder.Append(b, "BatchRequests", subrequest).(BatchBuilder)
+}
@@ -0,0 +1,145 @@
+package composite_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/composite"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient prefixes partial URLs with the REST API path of its server as client.Client does
+type fakeClient struct {
+	url string
+}
+
+func (c *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	return http.DefaultClient.Do(req)
+}
+
+func (c *fakeClient) URL(partial string) string {
+	if strings.Contains(partial, "https") {
+		return partial
+	}
+	return c.url + "/services/data/v51.0/" + strings.TrimPrefix(partial, "/")
+}
+
+func TestBatchRequestBody(t *testing.T) {
+	b := composite.
+		Batch(&fakeClient{url: "https://placeholder-dev-ed.my.salesforce.com"}).
+		HaltOnError(true).
+		Post("Lead", map[string]interface{}{"LastName": "Richards"}).
+		Patch("Lead/00Q000000000001AAA", map[string]interface{}{"FirstName": "fred"}).
+		Get("Lead/00Q000000000001AAA").
+		Delete("Lead/00Q000000000002AAA").
+		Query(soql.Select("Id", "Name").From("Lead").Limit(1))
+
+	// a builder may be sent more than once
+	for i := 0; i < 2; i++ {
+		req, err := b.Request()
+		require.Nil(t, err)
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/services/data/v51.0/composite/batch", req.URL.Path)
+
+		contents, err := ioutil.ReadAll(req.Body)
+		require.Nil(t, err)
+		require.JSONEq(t, `{
+			"haltOnError":true,
+			"batchRequests":[
+				{"method":"POST","url":"v51.0/sobjects/Lead","richInput":{"LastName":"Richards"}},
+				{"method":"PATCH","url":"v51.0/sobjects/Lead/00Q000000000001AAA","richInput":{"FirstName":"fred"}},
+				{"method":"GET","url":"v51.0/sobjects/Lead/00Q000000000001AAA"},
+				{"method":"DELETE","url":"v51.0/sobjects/Lead/00Q000000000002AAA"},
+				{"method":"GET","url":"v51.0/query?q=SELECT+Id%2C+Name+FROM+Lead+LIMIT+1"}
+			]
+		}`, string(contents))
+	}
+}
+
+func TestBatchTooManySubrequests(t *testing.T) {
+	b := composite.Batch(&fakeClient{url: "https://placeholder-dev-ed.my.salesforce.com"})
+	for i := 0; i <= composite.MaxBatchSubrequests; i++ {
+		b = b.Get("Lead/00Q000000000001AAA")
+	}
+
+	_, err := b.Request()
+	require.ErrorIs(t, err, composite.ErrTooManySubrequests)
+}
+
+func TestBatchSend(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{
+			"hasErrors":true,
+			"results":[
+				{"statusCode":200,"result":{"Id":"00Q000000000001AAA","LastName":"Richards"}},
+				{"statusCode":204,"result":null},
+				{"statusCode":200,"result":{"totalSize":1,"done":true,"records":[{"Id":"00Q000000000001AAA"}]}},
+				{"statusCode":404,"result":[{"errorCode":"NOT_FOUND","message":"The requested resource does not exist"}]}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	type lead struct {
+		ID       string `json:"Id"`
+		LastName string `json:"LastName"`
+	}
+
+	var first, missing lead
+	var query struct {
+		TotalSize int     `json:"totalSize"`
+		Records   []*lead `json:"records"`
+	}
+
+	response, err := composite.
+		Batch(&fakeClient{url: server.URL}).
+		Get("Lead/00Q000000000001AAA").
+		Patch("Lead/00Q000000000001AAA", map[string]interface{}{"LastName": "Richards"}).
+		Query(soql.Select("Id").From("Lead")).
+		Get("Lead/00Q000000000002AAA").
+		Send(&first, nil, &query, &missing)
+
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "subrequest 3")
+	require.Contains(t, err.Error(), "NOT_FOUND")
+	require.Equal(t, false, received["haltOnError"])
+
+	require.True(t, response.HasErrors)
+	require.Len(t, response.Results, 4)
+	require.Equal(t, "Richards", first.LastName)
+	require.Equal(t, 1, query.TotalSize)
+	require.Equal(t, "00Q000000000001AAA", query.Records[0].ID)
+	require.Equal(t, "", missing.ID)
+
+	var again lead
+	require.Nil(t, response.Decode(0, &again))
+	require.Equal(t, first, again)
+	require.Nil(t, response.Decode(1, &again))
+	require.NotNil(t, response.Decode(3, &again))
+	require.ErrorIs(t, response.Decode(4, &again), composite.ErrNoBatchResult)
+}
+
+func TestBatchQueryWithURL(t *testing.T) {
+	req, err := composite.
+		Batch(&fakeClient{url: "https://placeholder-dev-ed.my.salesforce.com"}).
+		Query(soql.Select("Id").From("Account").Where(soql.Eq{"Website": "https://example.com"})).
+		Request()
+	require.Nil(t, err)
+
+	var body composite.BatchRequest
+	require.Nil(t, json.NewDecoder(req.Body).Decode(&body))
+	require.Equal(t, "v51.0/query?q=SELECT+Id+FROM+Account+WHERE+Website+%3D+%27https%3A%2F%2Fexample.com%27", body.BatchRequests[0].URL)
+}
+
+func TestBatchInvalidURL(t *testing.T) {
+	_, err := composite.Batch(&fakeClient{url: "https://placeholder-dev-ed.my.salesforce.com"}).Get("https:Lead").Request()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "subrequest 0")
+}
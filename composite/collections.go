@@ -0,0 +1,300 @@
+package composite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/beeekind/go-salesforce-sdk/metadata"
+	"github.com/beeekind/go-salesforce-sdk/requests"
+)
+
+// collections.go implements the sObject Collections resource, which creates, updates, upserts,
+// deletes or retrieves up to 200 records in a single request. Larger inputs are split into
+// chunks of MaxCollectionRecords which are sent concurrently.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_composite_sobjects_collections.htm
+
+const (
+	// MaxCollectionRecords is the maximum number of records of a single sObject Collections request
+	MaxCollectionRecords = 200
+	// DefaultCollectionConcurrency is the number of chunks of records sent at once when
+	// CollectionOptions.Concurrency is not set
+	DefaultCollectionConcurrency = 4
+)
+
+var collectionsEndpoint = metadata.CompositeEndpoint + "/sobjects"
+
+var (
+	// ErrRecordsNotSlice is returned when the records given to a collection request are not a slice
+	ErrRecordsNotSlice = errors.New("records must be a slice")
+)
+
+// CollectionOptions configures a collection request. The zero value sends each chunk of records
+// with allOrNone false and DefaultCollectionConcurrency chunks at once.
+type CollectionOptions struct {
+	// AllOrNone rolls back every record of a chunk if any record of that chunk fails. Chunks are
+	// separate requests, so records of other chunks are unaffected.
+	AllOrNone bool
+	// Concurrency is the number of chunks sent at once
+	Concurrency int
+}
+
+// concurrency returns the number of chunks sent at once for o
+func (o *CollectionOptions) concurrency() int {
+	if o == nil || o.Concurrency <= 0 {
+		return DefaultCollectionConcurrency
+	}
+	return o.Concurrency
+}
+
+// allOrNone returns o.AllOrNone, which is false for a nil o
+func (o *CollectionOptions) allOrNone() bool {
+	return o != nil && o.AllOrNone
+}
+
+// SaveResult is the outcome of a single record of a create, update, upsert or delete collection
+// request
+type SaveResult struct {
+	ID      string `json:"id,omitempty"`
+	Success bool   `json:"success"`
+	// Created is set by upserts to true for records which were inserted rather than updated
+	Created bool   `json:"created,omitempty"`
+	Errors  Errors `json:"errors,omitempty"`
+}
+
+// Err returns the errors of r or nil if r succeeded
+func (r *SaveResult) Err() error {
+	if r.Success {
+		return nil
+	}
+
+	if len(r.Errors) == 0 {
+		return errors.New("record was not saved")
+	}
+
+	return r.Errors
+}
+
+// collectionRequest is the body of create, update and upsert collection requests
+type collectionRequest struct {
+	AllOrNone bool              `json:"allOrNone"`
+	Records   []json.RawMessage `json:"records"`
+}
+
+// retrieveRequest is the body of a retrieve collection request
+type retrieveRequest struct {
+	IDs    []string `json:"ids"`
+	Fields []string `json:"fields"`
+}
+
+// CreateRecords inserts records, a slice of structs or maps, as objects of type objectName and
+// returns a SaveResult for each record in the order of records:
+//
+//	results, err := composite.CreateRecords(requests.Sender(salesforce.DefaultClient), "Lead", []map[string]interface{}{
+//		{"LastName": "Richards", "Company": "ACME inc"},
+//		{"LastName": "Johnson", "Company": "ACME inc"},
+//	}, &composite.CollectionOptions{AllOrNone: true})
+//
+// The attributes.type of each record is set to objectName unless the record already has one.
+// If a chunk of records cannot be sent the results of that chunk are nil and the first such
+// error is returned along with the results of the other chunks.
+func CreateRecords(builder requests.Builder, objectName string, records interface{}, options *CollectionOptions) ([]*SaveResult, error) {
+	return saveRecords(builder, http.MethodPost, collectionsEndpoint, objectName, records, options)
+}
+
+// UpdateRecords updates records, each of which must have an Id, as CreateRecords inserts them
+func UpdateRecords(builder requests.Builder, objectName string, records interface{}, options *CollectionOptions) ([]*SaveResult, error) {
+	return saveRecords(builder, http.MethodPatch, collectionsEndpoint, objectName, records, options)
+}
+
+// UpsertRecords inserts or updates records matched by the external ID field externalIDField,
+// which each record must have, as CreateRecords inserts them. SaveResult.Created reports whether
+// each record was inserted.
+func UpsertRecords(builder requests.Builder, objectName string, externalIDField string, records interface{}, options *CollectionOptions) ([]*SaveResult, error) {
+	uri := fmt.Sprintf("%s/%s/%s", collectionsEndpoint, objectName, externalIDField)
+	return saveRecords(builder, http.MethodPatch, uri, objectName, records, options)
+}
+
+// DeleteRecords deletes the records of ids and returns a SaveResult for each ID in the order of ids
+func DeleteRecords(builder requests.Builder, ids []string, options *CollectionOptions) ([]*SaveResult, error) {
+	results := make([]*SaveResult, len(ids))
+	err := eachChunk(len(ids), options.concurrency(), func(start, end int) error {
+		var chunk []*SaveResult
+		_, err := builder.
+			Method(http.MethodDelete).
+			URL(collectionsEndpoint).
+			Param("ids", strings.Join(ids[start:end], ",")).
+			Param("allOrNone", strconv.FormatBool(options.allOrNone())).
+			JSON(&chunk)
+
+		if err != nil {
+			return err
+		}
+
+		return align(results[start:end], chunk)
+	})
+
+	return results, err
+}
+
+// RetrieveRecords retrieves the fields of the records of ids into dst, a pointer to a slice,
+// which holds a record for each ID in the order of ids:
+//
+//	var leads []*leads.Lead
+//	err := composite.RetrieveRecords(requests.Sender(salesforce.DefaultClient), "Lead", ids, []string{"Id", "LastName"}, &leads, nil)
+//
+// Records which do not exist or cannot be read are null and so are nil for a slice of pointers.
+func RetrieveRecords(builder requests.Builder, objectName string, ids []string, fields []string, dst interface{}, options *CollectionOptions) error {
+	records := make([]json.RawMessage, len(ids))
+	err := eachChunk(len(ids), options.concurrency(), func(start, end int) error {
+		var chunk []json.RawMessage
+		_, err := builder.
+			Method(http.MethodPost).
+			URL(fmt.Sprintf("%s/%s", collectionsEndpoint, objectName)).
+			Header("Content-Type", "application/json").
+			Marshal(&retrieveRequest{IDs: ids[start:end], Fields: fields}).
+			JSON(&chunk)
+
+		if err != nil {
+			return err
+		}
+
+		if len(chunk) != end-start {
+			return fmt.Errorf("expected %d records but received %d", end-start, len(chunk))
+		}
+
+		copy(records[start:end], chunk)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("marshaling retrieved records: %w", err)
+	}
+
+	if err := json.Unmarshal(contents, dst); err != nil {
+		return fmt.Errorf("unmarshaling retrieved records: %w", err)
+	}
+
+	return nil
+}
+
+// saveRecords sends records to uri in chunks and aligns the results of each chunk with records
+func saveRecords(builder requests.Builder, method string, uri string, objectName string, records interface{}, options *CollectionOptions) ([]*SaveResult, error) {
+	payloads, err := encodeRecords(objectName, records)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*SaveResult, len(payloads))
+	err = eachChunk(len(payloads), options.concurrency(), func(start, end int) error {
+		var chunk []*SaveResult
+		_, err := builder.
+			Method(method).
+			URL(uri).
+			Header("Content-Type", "application/json").
+			Marshal(&collectionRequest{AllOrNone: options.allOrNone(), Records: payloads[start:end]}).
+			JSON(&chunk)
+
+		if err != nil {
+			return err
+		}
+
+		return align(results[start:end], chunk)
+	})
+
+	return results, err
+}
+
+// encodeRecords marshals each element of records, setting its attributes.type to objectName
+// unless it already has one
+func encodeRecords(objectName string, records interface{}) ([]json.RawMessage, error) {
+	value := reflect.ValueOf(records)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, ErrRecordsNotSlice
+	}
+
+	attributes, err := json.Marshal(map[string]string{"type": objectName})
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := make([]json.RawMessage, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		contents, err := json.Marshal(value.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("marshaling record %d: %w", i, err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(contents, &fields); err != nil || fields == nil {
+			return nil, fmt.Errorf("record %d is not an object", i)
+		}
+
+		if attrs, ok := fields["attributes"]; !ok || string(attrs) == "null" {
+			fields["attributes"] = attributes
+			if contents, err = json.Marshal(fields); err != nil {
+				return nil, fmt.Errorf("marshaling record %d: %w", i, err)
+			}
+		}
+
+		payloads[i] = contents
+	}
+
+	return payloads, nil
+}
+
+// align copies the results of a chunk into dst, which Salesforce returns in the order of the
+// records of the request
+func align(dst []*SaveResult, chunk []*SaveResult) error {
+	if len(chunk) != len(dst) {
+		return fmt.Errorf("expected %d results but received %d", len(dst), len(chunk))
+	}
+
+	copy(dst, chunk)
+	return nil
+}
+
+// eachChunk calls fn with the bounds of each chunk of MaxCollectionRecords of n records, calling
+// at most concurrency at once. It returns the first error of fn annotated with its chunk.
+func eachChunk(n int, concurrency int, fn func(start, end int) error) error {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+	semaphore := make(chan struct{}, concurrency)
+
+	for start := 0; start < n; start += MaxCollectionRecords {
+		end := start + MaxCollectionRecords
+		if end > n {
+			end = n
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(start, end int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			if err := fn(start, end); err != nil {
+				once.Do(func() {
+					first = fmt.Errorf("records %d to %d: %w", start, end-1, err)
+				})
+			}
+		}(start, end)
+	}
+
+	wg.Wait()
+	return first
+}
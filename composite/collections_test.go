@@ -0,0 +1,224 @@
+package composite_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/composite"
+	"github.com/beeekind/go-salesforce-sdk/requests"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSender sends requests to a fakeCollections server
+type fakeSender struct {
+	url string
+}
+
+func (s *fakeSender) Do(req *http.Request) (*http.Response, error) {
+	return http.DefaultClient.Do(req)
+}
+
+func (s *fakeSender) QueryMore(builder soql.Builder, dst interface{}, includeSoftDelete bool) error {
+	return nil
+}
+
+func (s *fakeSender) URL(path string) string {
+	return s.url + "/" + strings.TrimPrefix(path, "/")
+}
+
+// fakeCollections imitates the sObject Collections resource. Records with a LastName of "fail"
+// are rejected and every other record is saved with an ID derived from its Name or LastName.
+type fakeCollections struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	requests []*http.Request
+	bodies   []map[string]interface{}
+}
+
+func newFakeCollections(t *testing.T) *fakeCollections {
+	f := &fakeCollections{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeCollections) builder() requests.Builder {
+	return requests.Sender(&fakeSender{url: f.server.URL})
+}
+
+func (f *fakeCollections) handle(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if r.Method != http.MethodDelete {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body)
+	f.mu.Unlock()
+
+	var response []interface{}
+	switch {
+	case r.Method == http.MethodDelete:
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			response = append(response, &composite.SaveResult{ID: id, Success: true})
+		}
+	case r.Method == http.MethodPost && body["ids"] != nil:
+		for _, id := range body["ids"].([]interface{}) {
+			if id == "missing" {
+				response = append(response, nil)
+				continue
+			}
+			response = append(response, map[string]interface{}{"Id": id, "LastName": "name " + id.(string)})
+		}
+	default:
+		for _, elem := range body["records"].([]interface{}) {
+			record := elem.(map[string]interface{})
+			if record["LastName"] == "fail" {
+				response = append(response, &composite.SaveResult{Errors: composite.Errors{{StatusCode: "REQUIRED_FIELD_MISSING", Message: "Required fields are missing: [Company]", Fields: []string{"Company"}}}})
+				continue
+			}
+			response = append(response, &composite.SaveResult{ID: fmt.Sprintf("00Q%v", record["LastName"]), Success: true, Created: r.Method == http.MethodPatch && record["Id"] == nil})
+		}
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func leads(n int) []map[string]interface{} {
+	records := make([]map[string]interface{}, n)
+	for i := range records {
+		records[i] = map[string]interface{}{"LastName": fmt.Sprint(i), "Company": "ACME inc"}
+	}
+	return records
+}
+
+func TestCreateRecordsChunksAndAligns(t *testing.T) {
+	fake := newFakeCollections(t)
+	records := leads(450)
+	records[201]["LastName"] = "fail"
+
+	results, err := composite.CreateRecords(fake.builder(), "Lead", records, &composite.CollectionOptions{AllOrNone: true, Concurrency: 2})
+	require.Nil(t, err)
+	require.Len(t, results, 450)
+	require.Len(t, fake.requests, 3)
+
+	for i, result := range results {
+		if i == 201 {
+			require.False(t, result.Success)
+			require.Equal(t, "REQUIRED_FIELD_MISSING", result.Errors[0].StatusCode)
+			require.NotNil(t, result.Err())
+			continue
+		}
+		require.Nil(t, result.Err())
+		require.Equal(t, fmt.Sprintf("00Q%d", i), result.ID)
+	}
+
+	for i, r := range fake.requests {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/composite/sobjects", r.URL.Path)
+		require.Equal(t, true, fake.bodies[i]["allOrNone"])
+		record := fake.bodies[i]["records"].([]interface{})[0].(map[string]interface{})
+		require.Equal(t, map[string]interface{}{"type": "Lead"}, record["attributes"])
+	}
+}
+
+func TestUpsertRecords(t *testing.T) {
+	type lead struct {
+		Attributes *composite.Attributes `json:"attributes,omitempty"`
+		LastName   string                `json:"LastName"`
+		ExternalID string                `json:"External_Id__c"`
+	}
+
+	fake := newFakeCollections(t)
+	results, err := composite.UpsertRecords(fake.builder(), "Lead", "External_Id__c", []*lead{
+		{LastName: "smith", ExternalID: "a"},
+		{Attributes: &composite.Attributes{Type: "Contact"}, LastName: "jones", ExternalID: "b"},
+	}, nil)
+
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	require.True(t, results[0].Created)
+	require.Equal(t, "00Qjones", results[1].ID)
+	require.Equal(t, http.MethodPatch, fake.requests[0].Method)
+	require.Equal(t, "/composite/sobjects/Lead/External_Id__c", fake.requests[0].URL.Path)
+	require.Equal(t, false, fake.bodies[0]["allOrNone"])
+
+	records := fake.bodies[0]["records"].([]interface{})
+	require.Equal(t, "Lead", records[0].(map[string]interface{})["attributes"].(map[string]interface{})["type"])
+	require.Equal(t, "Contact", records[1].(map[string]interface{})["attributes"].(map[string]interface{})["type"])
+}
+
+func TestDeleteRecords(t *testing.T) {
+	fake := newFakeCollections(t)
+	ids := make([]string, 201)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("00Q%d", i)
+	}
+
+	results, err := composite.DeleteRecords(fake.builder(), ids, &composite.CollectionOptions{AllOrNone: true})
+	require.Nil(t, err)
+	require.Len(t, results, 201)
+	require.Len(t, fake.requests, 2)
+
+	for i, result := range results {
+		require.Equal(t, ids[i], result.ID)
+	}
+
+	for _, r := range fake.requests {
+		require.Equal(t, http.MethodDelete, r.Method)
+		require.Equal(t, "true", r.URL.Query().Get("allOrNone"))
+	}
+}
+
+func TestRetrieveRecords(t *testing.T) {
+	type lead struct {
+		ID       string `json:"Id"`
+		LastName string `json:"LastName"`
+	}
+
+	fake := newFakeCollections(t)
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("00Q%d", i)
+	}
+	ids[210] = "missing"
+
+	var records []*lead
+	err := composite.RetrieveRecords(fake.builder(), "Lead", ids, []string{"Id", "LastName"}, &records, nil)
+	require.Nil(t, err)
+	require.Len(t, records, 250)
+	require.Nil(t, records[210])
+	require.Equal(t, "name 00Q249", records[249].LastName)
+	require.Equal(t, "/composite/sobjects/Lead", fake.requests[0].URL.Path)
+}
+
+func TestCollectionsRejectNonSlice(t *testing.T) {
+	fake := newFakeCollections(t)
+	_, err := composite.CreateRecords(fake.builder(), "Lead", map[string]interface{}{"LastName": "smith"}, nil)
+	require.ErrorIs(t, err, composite.ErrRecordsNotSlice)
+}
+
+func TestCollectionsRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `[{"errorCode":"INVALID_SESSION_ID"}]`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	results, err := composite.CreateRecords(requests.Sender(&fakeSender{url: server.URL}), "Lead", leads(2), nil)
+	require.Len(t, results, 2)
+	require.Nil(t, results[0])
+
+	var requestError *requests.RequestError
+	require.ErrorAs(t, err, &requestError)
+	require.Equal(t, http.StatusUnauthorized, requestError.Code)
+}
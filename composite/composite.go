@@ -192,11 +192,11 @@ func (r Request) request() (*http.Request, error) {
 
 	// normalize urls
 	for i := 0; i < len(r.CompositeRequest); i++ {
-		// https://placeholder-dev-ed.my.salesforce.com/services/data/v51.0/composite/sobjects/Lead
-		// =>
-		// /services/data/v51.0/composite/sobjects/Lead
-		parts := strings.Split(r.Client.URL(r.CompositeRequest[i].URL), "/")
-		r.CompositeRequest[i].URL = "/" + strings.Join(parts[3:], "/")
+		uri, err := normalizeURL(r.Client, r.CompositeRequest[i].URL)
+		if err != nil {
+			return nil, fmt.Errorf("subrequest %d: %w", i, err)
+		}
+		r.CompositeRequest[i].URL = uri
 	}
 
 	payload, err := json.Marshal(r)
@@ -216,6 +216,20 @@ func (r Request) request() (*http.Request, error) {
 	return req, nil
 }
 
+// normalizeURL returns the path of the absolute URL of uri as given by c
+func normalizeURL(c client, uri string) (string, error) {
+	// https://placeholder-dev-ed.my.salesforce.com/services/data/v51.0/composite/sobjects/Lead
+	// =>
+	// /services/data/v51.0/composite/sobjects/Lead
+	absolute := c.URL(uri)
+	parts := strings.Split(absolute, "/")
+	if len(parts) < 4 || parts[2] == "" {
+		return "", fmt.Errorf("%s is not an absolute url", absolute)
+	}
+
+	return "/" + strings.Join(parts[3:], "/"), nil
+}
+
 // Response ...
 func (b Builder) Response() (*http.Response, error) {
 	data := builder.GetStruct(b).(Request)
@@ -1,12 +1,14 @@
 package salesforce
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/beeekind/go-salesforce-sdk/client"
@@ -15,6 +17,7 @@ import (
 	"github.com/beeekind/go-salesforce-sdk/metadata"
 	"github.com/beeekind/go-salesforce-sdk/requests"
 	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/beeekind/go-salesforce-sdk/sosl"
 	"github.com/beeekind/go-salesforce-sdk/types"
 	"github.com/beeekind/ratelimit"
 	"github.com/beeekind/ratelimit/memory"
@@ -134,6 +137,64 @@ func Describe(objectName string) (describe *metadata.Describe, err error) {
 	return describe, nil
 }
 
+// DescribeCache is a soql.Schema which calls Describe once per object and reuses the result
+type DescribeCache struct {
+	mu        sync.Mutex
+	describes map[string]*metadata.Describe
+}
+
+// NewDescribeCache returns an empty DescribeCache
+func NewDescribeCache() *DescribeCache {
+	return &DescribeCache{describes: make(map[string]*metadata.Describe)}
+}
+
+// Describe returns the cached description of objectName, describing it if necessary
+func (c *DescribeCache) Describe(objectName string) (*metadata.Describe, error) {
+	key := strings.ToLower(objectName)
+
+	c.mu.Lock()
+	describe, ok := c.describes[key]
+	c.mu.Unlock()
+	if ok {
+		return describe, nil
+	}
+
+	describe, err := Describe(objectName)
+	if err != nil {
+		return nil, fmt.Errorf("describing %s: %w", objectName, err)
+	}
+
+	c.mu.Lock()
+	c.describes[key] = describe
+	c.mu.Unlock()
+	return describe, nil
+}
+
+// defaultDescribeCache is used by Validate and ExpandFields
+var defaultDescribeCache = NewDescribeCache()
+
+// Validate checks the fields, relationships, and operators of query against the describe
+// metadata of each object it references. Describe results are cached for the lifetime of the
+// process. See soql.Validate.
+func Validate(query soql.Builder) error {
+	return soql.Validate(query, defaultDescribeCache)
+}
+
+// ExpandFields replaces FIELDS() within query by the explicit fields of its object wherever
+// Salesforce would reject it: in bulk queries, API versions of DefaultClient prior to 51.0, and
+// queries using FIELDS(ALL) or FIELDS(CUSTOM) without a LIMIT of at most 200. Compound fields
+// such as BillingAddress are handled as compound specifies. See soql.FieldsExpander.
+func ExpandFields(query soql.Builder, bulk bool, compound soql.CompoundFields) (soql.Builder, error) {
+	expander := &soql.FieldsExpander{
+		Schema:     defaultDescribeCache,
+		Compound:   compound,
+		APIVersion: DefaultClient.Version(),
+		Bulk:       bulk,
+	}
+
+	return expander.Expand(query)
+}
+
 // AllEntities ...
 // "If the data can't be found here, check out what's behind API endpoint number 5"
 // ~~ B. Bonnette
@@ -201,6 +262,67 @@ func Count(objectName string) (int, error) {
 	return response.TotalSize, nil
 }
 
+// Aggregate executes an aggregate query, such as one using GROUP BY or soql.Count, and
+// decodes the AggregateResult records into dst by alias. See soql.DecodeAggregateResults.
+//
+// The parameter dst should be a pointer value to a slice of structs.
+func Aggregate(query soql.Builder, dst interface{}) error {
+	var response types.QueryParts
+	_, err := requests.
+		Sender(DefaultClient).
+		URL(metadata.QueryEndpoint).
+		SQLizer(query).
+		JSON(&response)
+
+	if err != nil {
+		return err
+	}
+
+	return soql.DecodeAggregateResults(response.Records, dst)
+}
+
+// Explain returns the query plans Salesforce considered for query without executing it. A
+// plan is non-selective, and likely to time out against large objects, when its RelativeCost
+// is over 1 or its LeadingOperationType is TableScan.
+func Explain(ctx context.Context, query soql.Builder) (*client.QueryPlans, error) {
+	return DefaultClient.Explain(ctx, query)
+}
+
+// Search executes a SOSL query on the search endpoint. Use the methods of sosl.Response to
+// decode the records of each returned object.
+func Search(query sosl.Builder) (*sosl.Response, error) {
+	var response sosl.Response
+	_, err := requests.
+		Sender(DefaultClient).
+		URL(metadata.SearchEndpoint).
+		SQLizer(query).
+		JSON(&response)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ParameterizedSearch executes a search on the parameterizedSearch endpoint
+func ParameterizedSearch(params *sosl.Parameters) (*sosl.Response, error) {
+	var response sosl.Response
+	_, err := requests.
+		Sender(DefaultClient).
+		URL(metadata.ParameterizedSearchEndpoint).
+		Method(http.MethodPost).
+		Header("Content-Type", "application/json").
+		Marshal(params).
+		JSON(&response)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 // Find returns all paginated resources for a given query. If there
 // are many results and/or many fields this method will take longer
 // to execute and use more of your org's API limit.
@@ -226,6 +348,23 @@ func FindAll(query string, dst interface{}) error {
 		QueryMore(soql.String(query), dst, true)
 }
 
+// FindStruct is akin to Find but derives its query from the element type of dst via
+// soql.SelectStruct, so that the fields selected always match those being decoded. Each
+// predicate of where is added as with soql.Builder.Where.
+//
+// The parameter dst should be a pointer value to a slice of structs.
+func FindStruct(dst interface{}, where ...interface{}) error {
+	query := soql.SelectStruct(dst)
+	for _, predicate := range where {
+		query = query.Where(predicate)
+	}
+
+	return requests.
+		Sender(DefaultClient).
+		URL("query").
+		QueryMore(query, dst, false)
+}
+
 // FindByID returns a single result filtered by Id.
 //
 // The parameter dst should be a pointer to a type matching the
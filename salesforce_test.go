@@ -3,6 +3,7 @@
 package salesforce_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/beeekind/go-salesforce-sdk"
@@ -117,6 +118,17 @@ func TestCount(t *testing.T) {
 	require.Greater(t, count, 0)
 }
 
+func TestExplain(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	plans, err := salesforce.Explain(context.Background(), soql.Select("Id").From("Lead").Where(soql.Eq{"Id": "00Q000000000000"}))
+	require.Nil(t, err)
+	require.Greater(t, len(plans.Plans), 0)
+	require.Equal(t, "Lead", plans.Best().SobjectType)
+}
+
 func TestFind(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -0,0 +1,242 @@
+package soql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// aggregate.go contains the aggregate functions of SOQL and a decoder for the AggregateResult
+// records they return.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_agg_functions.htm
+
+// Aggregate is a function applied to a field such as COUNT(Id) or SUM(Amount). It may be used
+// as a column, optionally aliased via As, or compared against a value within Having:
+//
+//	soql.
+//		Select("LeadSource").
+//		Column(soql.Count("Id").As("cnt")).
+//		From("Lead").
+//		GroupBy("LeadSource").
+//		Having(soql.Count("Id").Gt(10))
+type Aggregate struct {
+	name  string
+	field SQLizer
+	alias string
+}
+
+// newAggregate accepts a field as either a string or a SQLizer, such as another Aggregate
+func newAggregate(name string, field interface{}) Aggregate {
+	return Aggregate{name: name, field: newBaseSQLizer(field)}
+}
+
+// Count returns the number of rows matching the query. An empty field produces COUNT()
+// which may not be aliased or combined with other columns.
+func Count(field interface{}) Aggregate { return newAggregate("COUNT", field) }
+
+// CountDistinct returns the number of distinct non-null values of field
+func CountDistinct(field interface{}) Aggregate { return newAggregate("COUNT_DISTINCT", field) }
+
+// Sum returns the total sum of a numeric field
+func Sum(field interface{}) Aggregate { return newAggregate("SUM", field) }
+
+// Avg returns the average value of a numeric field
+func Avg(field interface{}) Aggregate { return newAggregate("AVG", field) }
+
+// Min returns the minimum value of a field
+func Min(field interface{}) Aggregate { return newAggregate("MIN", field) }
+
+// Max returns the maximum value of a field
+func Max(field interface{}) Aggregate { return newAggregate("MAX", field) }
+
+// Grouping reports whether a row of a GROUP BY ROLLUP or CUBE query is a subtotal for field.
+// Decode it into a bool field to distinguish subtotal rows from grouped rows.
+func Grouping(field interface{}) Aggregate { return newAggregate("GROUPING", field) }
+
+// Format applies localized formatting to a standard or custom number, date, time, or currency
+// field. The field may be another Aggregate i.e. soql.Format(soql.Sum("Amount"))
+func Format(field interface{}) Aggregate { return newAggregate("FORMAT", field) }
+
+// ConvertCurrency converts a currency field to the user's currency in multi-currency orgs
+func ConvertCurrency(field interface{}) Aggregate { return newAggregate("convertCurrency", field) }
+
+// As aliases the result of the aggregate. The alias is the key of the value within the
+// returned AggregateResult records, otherwise Salesforce names them expr0, expr1, etc.
+func (a Aggregate) As(alias string) Aggregate {
+	a.alias = alias
+	return a
+}
+
+// Alias returns the alias set by As
+func (a Aggregate) Alias() string {
+	return a.alias
+}
+
+// function returns the unaliased form of the aggregate i.e. COUNT(Id)
+func (a Aggregate) function() (string, error) {
+	field, err := a.field.ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	if field == "" && a.name != "COUNT" {
+		return "", fmt.Errorf("soql aggregate %s requires a field", a.name)
+	}
+
+	return fmt.Sprintf("%s(%s)", a.name, field), nil
+}
+
+// ToSQL ...
+func (a Aggregate) ToSQL() (string, error) {
+	sql, err := a.function()
+	if err != nil {
+		return "", err
+	}
+
+	if a.alias == "" {
+		return sql, nil
+	}
+
+	if sql == "COUNT()" {
+		return "", errors.New("soql aggregate COUNT() cannot be aliased")
+	}
+
+	return fmt.Sprintf("%s %s", sql, a.alias), nil
+}
+
+// unaliased drops the alias so the aggregate may be used within an expression
+func (a Aggregate) unaliased() Aggregate {
+	a.alias = ""
+	return a
+}
+
+// Eq returns a "a = value" expression
+func (a Aggregate) Eq(value interface{}) SQLizer { return comparison{a.unaliased(), "=", value} }
+
+// NotEq returns a "a != value" expression
+func (a Aggregate) NotEq(value interface{}) SQLizer { return comparison{a.unaliased(), "!=", value} }
+
+// Lt returns a "a < value" expression
+func (a Aggregate) Lt(value interface{}) SQLizer { return comparison{a.unaliased(), "<", value} }
+
+// LtOrEq returns a "a <= value" expression
+func (a Aggregate) LtOrEq(value interface{}) SQLizer { return comparison{a.unaliased(), "<=", value} }
+
+// Gt returns a "a > value" expression
+func (a Aggregate) Gt(value interface{}) SQLizer { return comparison{a.unaliased(), ">", value} }
+
+// GtOrEq returns a "a >= value" expression
+func (a Aggregate) GtOrEq(value interface{}) SQLizer { return comparison{a.unaliased(), ">=", value} }
+
+// DecodeAggregateResults decodes the records of an aggregate query into dst, a pointer to a
+// slice of structs or struct pointers. Each key of an AggregateResult record is matched, case
+// insensitively, to a struct field by:
+//
+// 1. a `soql:"alias"` struct tag
+// 2. the name within a `json:"name"` struct tag
+// 3. the name of the field
+//
+// Unmatched keys, such as attributes, are ignored. Numbers are converted to integers when the
+// destination is an integer type and to true/false when the destination is a bool, which is
+// how the 1 or 0 returned by GROUPING() identifies the subtotal rows of a ROLLUP or CUBE.
+func DecodeAggregateResults(records []json.RawMessage, dst interface{}) error {
+	slice := reflect.ValueOf(dst)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("DecodeAggregateResults: dst must be a pointer to a slice, not %T", dst)
+	}
+
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("DecodeAggregateResults: dst must be a slice of structs, not %s", slice.Type())
+	}
+
+	fields := aggregateFields(elemType)
+	results := reflect.MakeSlice(slice.Type(), 0, len(records))
+	for i, record := range records {
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(record, &row); err != nil {
+			return fmt.Errorf("DecodeAggregateResults: record %d: %w", i, err)
+		}
+
+		elem := reflect.New(elemType)
+		for key, raw := range row {
+			index, ok := fields[strings.ToLower(key)]
+			if !ok {
+				continue
+			}
+
+			if err := decodeAggregateValue(raw, elem.Elem().FieldByIndex(index)); err != nil {
+				return fmt.Errorf("DecodeAggregateResults: record %d: %s: %w", i, key, err)
+			}
+		}
+
+		if isPtr {
+			results = reflect.Append(results, elem)
+		} else {
+			results = reflect.Append(results, elem.Elem())
+		}
+	}
+
+	slice.Set(results)
+	return nil
+}
+
+// aggregateFields maps the lowercase alias of each exported field to its index
+func aggregateFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+
+		if tag := field.Tag.Get("soql"); tag != "" {
+			name = tag
+		}
+
+		fields[strings.ToLower(name)] = field.Index
+	}
+
+	return fields
+}
+
+// decodeAggregateValue unmarshals raw into field, converting numbers into integers and
+// booleans where the destination requires it
+func decodeAggregateValue(raw json.RawMessage, field reflect.Value) error {
+	if bytes.Equal(raw, []byte("null")) {
+		return json.Unmarshal(raw, field.Addr().Interface())
+	}
+
+	switch field.Kind() {
+	case reflect.Bool:
+		var number float64
+		if err := json.Unmarshal(raw, &number); err == nil {
+			field.SetBool(number != 0)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var number float64
+		if err := json.Unmarshal(raw, &number); err == nil && number == math.Trunc(number) {
+			field.SetInt(int64(number))
+			return nil
+		}
+	}
+
+	return json.Unmarshal(raw, field.Addr().Interface())
+}
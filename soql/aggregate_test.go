@@ -0,0 +1,104 @@
+package soql_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/beeekind/go-salesforce-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+var aggregateTests = map[*selectInput]*selectOutput{
+	{soql.
+		Select("LeadSource").
+		Column(soql.Count("Id").As("cnt")).
+		Column(soql.CountDistinct("Company").As("companies")).
+		From("Lead").
+		GroupBy("LeadSource").
+		Having(soql.Count("Id").As("cnt").Gt(10)),
+	}: {"SELECT LeadSource, COUNT(Id) cnt, COUNT_DISTINCT(Company) companies FROM Lead GROUP BY LeadSource HAVING COUNT(Id) > 10", nil, "count with alias and having"},
+	{soql.
+		Select().
+		Column(soql.Sum("Amount")).
+		Column(soql.Avg("Amount").As("average")).
+		Column(soql.Min("CloseDate").As("earliest")).
+		Column(soql.Max("CloseDate").As("latest")).
+		From("Opportunity"),
+	}: {"SELECT SUM(Amount), AVG(Amount) average, MIN(CloseDate) earliest, MAX(CloseDate) latest FROM Opportunity", nil, "sum, avg, min, max"},
+	{soql.
+		Select("Id").
+		Column(soql.ConvertCurrency("Amount").As("converted")).
+		Column(soql.Format(soql.Sum("Amount")).As("formatted")).
+		From("Opportunity"),
+	}: {"SELECT Id, convertCurrency(Amount) converted, FORMAT(SUM(Amount)) formatted FROM Opportunity", nil, "format and convertCurrency"},
+	{soql.
+		Select("LeadSource").
+		Column(soql.Count("Name").As("cnt")).
+		Column(soql.Grouping("LeadSource").As("grpLS")).
+		From("Lead").
+		GroupByRollup("LeadSource"),
+	}: {"SELECT LeadSource, COUNT(Name) cnt, GROUPING(LeadSource) grpLS FROM Lead GROUP BY ROLLUP(LeadSource)", nil, "grouping"},
+	{soql.
+		Select().
+		Column(soql.Count("")).
+		From("Lead"),
+	}: {"SELECT COUNT() FROM Lead", nil, "count()"},
+}
+
+func TestAggregates(t *testing.T) {
+	for in, out := range aggregateTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := in.builder.ToSQL()
+			require.Equal(t, out.expectedErr, err)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	for description, a := range map[string]soql.Aggregate{
+		"sum without field":    soql.Sum(""),
+		"aliased count()":      soql.Count("").As("cnt"),
+		"nested without field": soql.Format(soql.Max("")),
+	} {
+		t.Run(description, func(t *testing.T) {
+			_, err := a.ToSQL()
+			require.NotNil(t, err)
+		})
+	}
+}
+
+type leadSourceSummary struct {
+	LeadSource types.NullableString `json:"LeadSource"`
+	Count      int                  `soql:"cnt"`
+	Total      float64              `soql:"expr0"`
+	IsSubtotal bool                 `soql:"grpLS"`
+}
+
+func TestDecodeAggregateResults(t *testing.T) {
+	records := []json.RawMessage{
+		json.RawMessage(`{"attributes":{"type":"AggregateResult"},"LeadSource":"Web","cnt":7,"expr0":1250.5,"grpLS":0}`),
+		json.RawMessage(`{"attributes":{"type":"AggregateResult"},"LeadSource":null,"cnt":9.0,"expr0":2000,"grpLS":1}`),
+	}
+
+	var results []leadSourceSummary
+	require.Nil(t, soql.DecodeAggregateResults(records, &results))
+	require.Len(t, results, 2)
+
+	require.Equal(t, "Web", results[0].LeadSource.Value)
+	require.Equal(t, 7, results[0].Count)
+	require.Equal(t, 1250.5, results[0].Total)
+	require.False(t, results[0].IsSubtotal)
+
+	require.True(t, results[1].LeadSource.IsNull)
+	require.Equal(t, 9, results[1].Count)
+	require.True(t, results[1].IsSubtotal)
+
+	var pointers []*leadSourceSummary
+	require.Nil(t, soql.DecodeAggregateResults(records, &pointers))
+	require.Equal(t, 7, pointers[0].Count)
+
+	require.NotNil(t, soql.DecodeAggregateResults(records, results))
+	require.NotNil(t, soql.DecodeAggregateResults([]json.RawMessage{json.RawMessage(`{"cnt":"seven"}`)}, &results))
+}
@@ -0,0 +1,238 @@
+package soql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// clauses.go contains the less common components of the SOQL grammar which are set on a
+// Builder via UsingScope, With*, GroupByRollup/GroupByCube, OrderByClause, and For*.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select.htm
+
+// FilterScope is the set of records a USING SCOPE clause filters for
+type FilterScope string
+
+const (
+	// ScopeDelegated filters for records delegated to another user for action
+	ScopeDelegated FilterScope = "delegated"
+	// ScopeEverything filters for all records
+	ScopeEverything FilterScope = "everything"
+	// ScopeMine filters for records owned by the user running the query
+	ScopeMine FilterScope = "mine"
+	// ScopeMineAndMyGroups filters for records assigned to the user running the query and the user's queues
+	ScopeMineAndMyGroups FilterScope = "mineAndMyGroups"
+	// ScopeMyTerritory filters for records in the territory of the user running the query
+	ScopeMyTerritory FilterScope = "my_territory"
+	// ScopeMyTeamTerritory filters for records in the territory of the team of the user running the query
+	ScopeMyTeamTerritory FilterScope = "my_team_territory"
+	// ScopeTeam filters for records assigned to a team, such as an Account team
+	ScopeTeam FilterScope = "team"
+)
+
+// CategoryOperator selects categories relative to a category of a WITH DATA CATEGORY clause
+type CategoryOperator string
+
+const (
+	// CategoryAt selects the category and all of its parent and child categories
+	CategoryAt CategoryOperator = "AT"
+	// CategoryAbove selects the category and all of its parent categories
+	CategoryAbove CategoryOperator = "ABOVE"
+	// CategoryBelow selects the category and all of its child categories
+	CategoryBelow CategoryOperator = "BELOW"
+	// CategoryAboveOrBelow selects the category, its parent categories, and its child categories
+	CategoryAboveOrBelow CategoryOperator = "ABOVE_OR_BELOW"
+)
+
+// DataCategory is a single filter of a WITH DATA CATEGORY clause i.e. Geography__c AT usa__c
+type DataCategory struct {
+	Group      string
+	Operator   CategoryOperator
+	Categories []string
+}
+
+// ToSQL ...
+func (c DataCategory) ToSQL() (string, error) {
+	if c.Group == "" || c.Operator == "" || len(c.Categories) == 0 {
+		return "", errors.New("soql.DataCategory requires a group, an operator, and at least one category")
+	}
+
+	if len(c.Categories) == 1 {
+		return fmt.Sprintf("%s %s %s", c.Group, c.Operator, c.Categories[0]), nil
+	}
+
+	return fmt.Sprintf("%s %s (%s)", c.Group, c.Operator, strings.Join(c.Categories, ", ")), nil
+}
+
+// dataCategories joins the filters of a WITH DATA CATEGORY clause
+type dataCategories conj
+
+// ToSQL ...
+func (c dataCategories) ToSQL() (string, error) {
+	if len(c) == 0 {
+		return "", errors.New("WITH DATA CATEGORY requires at least one filter")
+	}
+
+	parts := make([]string, 0, len(c))
+	for _, filter := range c {
+		sql, err := filter.ToSQL()
+		if err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
+		parts = append(parts, sql)
+	}
+
+	return "DATA CATEGORY " + strings.Join(parts, " AND "), nil
+}
+
+// groupingExpr is a GROUP BY ROLLUP(...) or GROUP BY CUBE(...) clause
+type groupingExpr struct {
+	name   string
+	fields []string
+}
+
+// ToSQL ...
+func (g groupingExpr) ToSQL() (string, error) {
+	if len(g.fields) == 0 {
+		return "", fmt.Errorf("GROUP BY %s requires at least one field", g.name)
+	}
+
+	return fmt.Sprintf("%s(%s)", g.name, strings.Join(g.fields, ", ")), nil
+}
+
+// OrderExpr is a single ORDER BY field with an optional direction and null ordering. Use it
+// with Builder.OrderByClause:
+//
+// soql.Select("Id").From("Lead").OrderByClause(soql.Desc("CreatedDate").NullsLast())
+type OrderExpr struct {
+	field     SQLizer
+	direction string
+	nulls     string
+}
+
+// Asc orders field in ascending order. The field may be a string or a SQLizer
+func Asc(field interface{}) OrderExpr {
+	return OrderExpr{field: newBaseSQLizer(field), direction: "ASC"}
+}
+
+// Desc orders field in descending order. The field may be a string or a SQLizer
+func Desc(field interface{}) OrderExpr {
+	return OrderExpr{field: newBaseSQLizer(field), direction: "DESC"}
+}
+
+// NullsFirst places null values at the beginning of the results
+func (o OrderExpr) NullsFirst() OrderExpr {
+	o.nulls = "FIRST"
+	return o
+}
+
+// NullsLast places null values at the end of the results
+func (o OrderExpr) NullsLast() OrderExpr {
+	o.nulls = "LAST"
+	return o
+}
+
+// ToSQL ...
+func (o OrderExpr) ToSQL() (string, error) {
+	field, err := o.field.ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	if field == "" {
+		return "", errors.New("soql.OrderExpr requires a field")
+	}
+
+	sql := field
+	if o.direction != "" {
+		sql += " " + o.direction
+	}
+
+	if o.nulls != "" {
+		sql += " NULLS " + o.nulls
+	}
+
+	return sql, nil
+}
+
+// TypeOfExpr selects fields from a polymorphic relationship depending on the type of the
+// referenced object. Use it with Builder.Column:
+//
+// soql.Select("Id").Column(soql.TypeOf("What").When("Account", "Phone").Else("Name")).From("Event")
+// => SELECT Id, TYPEOF What WHEN Account THEN Phone ELSE Name END FROM Event
+type TypeOfExpr struct {
+	field string
+	whens []typeOfWhen
+	elses []string
+}
+
+type typeOfWhen struct {
+	objectType string
+	fields     []string
+}
+
+// TypeOf returns a TYPEOF expression for the given polymorphic relationship name
+func TypeOf(field string) TypeOfExpr {
+	return TypeOfExpr{field: field}
+}
+
+// When selects fields when the relationship references an object of objectType
+func (t TypeOfExpr) When(objectType string, fields ...string) TypeOfExpr {
+	whens := make([]typeOfWhen, len(t.whens), len(t.whens)+1)
+	copy(whens, t.whens)
+	t.whens = append(whens, typeOfWhen{objectType, fields})
+	return t
+}
+
+// Else selects fields when the relationship references an object not named by When
+func (t TypeOfExpr) Else(fields ...string) TypeOfExpr {
+	t.elses = fields
+	return t
+}
+
+// ToSQL ...
+func (t TypeOfExpr) ToSQL() (string, error) {
+	if t.field == "" {
+		return "", errors.New("TYPEOF requires a polymorphic relationship field")
+	}
+
+	if len(t.whens) == 0 {
+		return "", fmt.Errorf("TYPEOF %s requires at least one WHEN clause", t.field)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("TYPEOF ")
+	sql.WriteString(t.field)
+
+	for _, when := range t.whens {
+		if when.objectType == "" || len(when.fields) == 0 {
+			return "", fmt.Errorf("TYPEOF %s WHEN requires an object type and at least one field", t.field)
+		}
+
+		sql.WriteString(fmt.Sprintf(" WHEN %s THEN %s", when.objectType, strings.Join(when.fields, ", ")))
+	}
+
+	if len(t.elses) > 0 {
+		sql.WriteString(" ELSE ")
+		sql.WriteString(strings.Join(t.elses, ", "))
+	}
+
+	sql.WriteString(" END")
+	return sql.String(), nil
+}
+
+// validateClauses enforces the restrictions between clauses of a single query
+func (d *selectData) validateClauses() error {
+	for _, groupBy := range d.GroupBys {
+		if _, ok := groupBy.(groupingExpr); ok && len(d.GroupBys) > 1 {
+			return errors.New("GROUP BY ROLLUP and GROUP BY CUBE can't be combined with other group by clauses")
+		}
+	}
+
+	if d.ForUpdate && len(d.OrderByParts) > 0 {
+		return errors.New("FOR UPDATE can't be combined with ORDER BY")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package soql_test
+
+import (
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+var clauseTests = map[*selectInput]*selectOutput{
+	{soql.
+		Select("Id").
+		Column(soql.TypeOf("What").When("Account", "Phone", "NumberOfEmployees").When("Opportunity", "Amount").Else("Name")).
+		From("Event"),
+	}: {"SELECT Id, TYPEOF What WHEN Account THEN Phone, NumberOfEmployees WHEN Opportunity THEN Amount ELSE Name END FROM Event", nil, "typeof"},
+	{soql.
+		Select("Id").
+		From("Account").
+		UsingScope(soql.ScopeMine).
+		Where(soql.Eq{"Name": "a"}).
+		WithSecurityEnforced().
+		OrderByClause(soql.Desc("CreatedDate").NullsLast()).
+		OrderByClause(soql.Asc("Name").NullsFirst()).
+		Limit(10).
+		Offset(5).
+		ForView(),
+	}: {"SELECT Id FROM Account USING SCOPE mine WHERE Name = 'a' WITH SECURITY_ENFORCED ORDER BY CreatedDate DESC NULLS LAST, Name ASC NULLS FIRST LIMIT 10 OFFSET 5 FOR VIEW", nil, "clauses in grammar order"},
+	{soql.
+		Select("Title").
+		From("KnowledgeArticleVersion").
+		Where(soql.Eq{"PublishStatus": "online"}).
+		WithDataCategory(
+			soql.DataCategory{Group: "Geography__c", Operator: soql.CategoryAbove, Categories: []string{"usa__c"}},
+			soql.DataCategory{Group: "Product__c", Operator: soql.CategoryAt, Categories: []string{"mobile_phones__c", "laptops__c"}},
+		).
+		UpdateViewstat(),
+	}: {"SELECT Title FROM KnowledgeArticleVersion WHERE PublishStatus = 'online' WITH DATA CATEGORY Geography__c ABOVE usa__c AND Product__c AT (mobile_phones__c, laptops__c) UPDATE VIEWSTAT", nil, "with data category"},
+	{soql.
+		Select("Title").
+		From("KnowledgeArticleVersion").
+		UsingScope(soql.FilterScope("everything")).
+		WithDataCategory(soql.DataCategory{Group: "Geography__c", Operator: soql.CategoryOperator("BELOW"), Categories: []string{"europe__c"}}),
+	}: {"SELECT Title FROM KnowledgeArticleVersion USING SCOPE everything WITH DATA CATEGORY Geography__c BELOW europe__c", nil, "scope and category from strings"},
+	{soql.
+		Select("LeadSource", "Rating", "COUNT(Name) cnt").
+		From("Lead").
+		WithUserMode().
+		GroupByRollup("LeadSource", "Rating"),
+	}: {"SELECT LeadSource, Rating, COUNT(Name) cnt FROM Lead WITH USER_MODE GROUP BY ROLLUP(LeadSource, Rating)", nil, "group by rollup"},
+	{soql.
+		Select("Type", "COUNT(Id)").
+		From("Account").
+		GroupByCube("Type"),
+	}: {"SELECT Type, COUNT(Id) FROM Account GROUP BY CUBE(Type)", nil, "group by cube"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Limit(1).
+		ForReference().
+		UpdateTracking().
+		ForUpdate(),
+	}: {"SELECT Id FROM Account LIMIT 1 FOR REFERENCE UPDATE TRACKING FOR UPDATE", nil, "for reference, update tracking, and for update"},
+}
+
+func TestClauses(t *testing.T) {
+	for in, out := range clauseTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := in.builder.ToSQL()
+			require.Equal(t, out.expectedErr, err)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+}
+
+func TestClauseErrors(t *testing.T) {
+	for description, b := range map[string]soql.Builder{
+		"rollup with group by":     soql.Select("a").From("Lead").GroupByRollup("a").GroupBy("b"),
+		"for update with order by": soql.Select("a").From("Lead").OrderBy("a").ForUpdate(),
+		"typeof without when":      soql.Select("a").Column(soql.TypeOf("What")).From("Event"),
+		"empty data category":      soql.Select("a").From("KnowledgeArticleVersion").WithDataCategory(soql.DataCategory{}),
+	} {
+		t.Run(description, func(t *testing.T) {
+			_, err := b.ToSQL()
+			require.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package soql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dates.go contains the relative date literals and date functions supported by SOQL. Each is
+// a SQLizer so they may be used as values within expressions, such as Eq or Gt, without being
+// quoted as strings.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_dateformats.htm
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_date_functions.htm
+
+// DateLiteral is a relative date range such as TODAY or THIS_FISCAL_QUARTER
+type DateLiteral string
+
+// ToSQL ...
+func (d DateLiteral) ToSQL() (string, error) {
+	return string(d), nil
+}
+
+const (
+	// Yesterday starts 00:00:00 the day before and continues for 24 hours
+	Yesterday DateLiteral = "YESTERDAY"
+	// Today starts 00:00:00 of the current day and continues for 24 hours
+	Today DateLiteral = "TODAY"
+	// Tomorrow starts 00:00:00 after the current day and continues for 24 hours
+	Tomorrow DateLiteral = "TOMORROW"
+	// LastWeek starts 00:00:00 on the first day of the week before the most recent first day of the week
+	// and continues for seven full days
+	LastWeek DateLiteral = "LAST_WEEK"
+	// ThisWeek starts 00:00:00 on the most recent first day of the week on or before the current day
+	// and continues for seven full days
+	ThisWeek DateLiteral = "THIS_WEEK"
+	// NextWeek starts 00:00:00 on the most recent first day of the week after the current day
+	// and continues for seven full days
+	NextWeek DateLiteral = "NEXT_WEEK"
+	// LastMonth starts 00:00:00 on the first day of the month before the current day and continues
+	// for all the days of that month
+	LastMonth DateLiteral = "LAST_MONTH"
+	// ThisMonth starts 00:00:00 on the first day of the month that the current day is in and continues
+	// for all the days of that month
+	ThisMonth DateLiteral = "THIS_MONTH"
+	// NextMonth starts 00:00:00 on the first day of the month after the month that the current day is in
+	// and continues for all the days of that month
+	NextMonth DateLiteral = "NEXT_MONTH"
+	// Last90Days starts with the current day and continues for the past 90 days
+	Last90Days DateLiteral = "LAST_90_DAYS"
+	// Next90Days starts 00:00:00 of the next day and continues for the next 90 days
+	Next90Days DateLiteral = "NEXT_90_DAYS"
+	// ThisQuarter starts 00:00:00 of the current quarter and continues to the end of the current quarter
+	ThisQuarter DateLiteral = "THIS_QUARTER"
+	// LastQuarter starts 00:00:00 of the previous quarter and continues to the end of that quarter
+	LastQuarter DateLiteral = "LAST_QUARTER"
+	// NextQuarter starts 00:00:00 of the next quarter and continues to the end of that quarter
+	NextQuarter DateLiteral = "NEXT_QUARTER"
+	// ThisYear starts 00:00:00 on January 1 of the current year and continues through the end of
+	// December 31 of the current year
+	ThisYear DateLiteral = "THIS_YEAR"
+	// LastYear starts 00:00:00 on January 1 of the previous year and continues through the end of
+	// December 31 of that year
+	LastYear DateLiteral = "LAST_YEAR"
+	// NextYear starts 00:00:00 on January 1 of the following year and continues through the end of
+	// December 31 of that year
+	NextYear DateLiteral = "NEXT_YEAR"
+	// ThisFiscalQuarter starts 00:00:00 on the first day of the current fiscal quarter and continues
+	// through the end of the last day of the fiscal quarter
+	ThisFiscalQuarter DateLiteral = "THIS_FISCAL_QUARTER"
+	// LastFiscalQuarter starts 00:00:00 on the first day of the last fiscal quarter and continues
+	// through the end of the last day of that fiscal quarter
+	LastFiscalQuarter DateLiteral = "LAST_FISCAL_QUARTER"
+	// NextFiscalQuarter starts 00:00:00 on the first day of the next fiscal quarter and continues
+	// through the end of the last day of that fiscal quarter
+	NextFiscalQuarter DateLiteral = "NEXT_FISCAL_QUARTER"
+	// ThisFiscalYear starts 00:00:00 on the first day of the current fiscal year and continues
+	// through the end of the last day of the fiscal year
+	ThisFiscalYear DateLiteral = "THIS_FISCAL_YEAR"
+	// LastFiscalYear starts 00:00:00 on the first day of the last fiscal year and continues
+	// through the end of the last day of that fiscal year
+	LastFiscalYear DateLiteral = "LAST_FISCAL_YEAR"
+	// NextFiscalYear starts 00:00:00 on the first day of the next fiscal year and continues
+	// through the end of the last day of that fiscal year
+	NextFiscalYear DateLiteral = "NEXT_FISCAL_YEAR"
+)
+
+// nDateLiteral is a parameterized relative date range such as LAST_N_DAYS:30
+type nDateLiteral struct {
+	name string
+	n    int
+}
+
+// ToSQL ...
+func (d nDateLiteral) ToSQL() (string, error) {
+	if d.n < 0 {
+		return "", fmt.Errorf("soql date literal %s requires a non-negative integer, not %d", d.name, d.n)
+	}
+
+	return fmt.Sprintf("%s:%d", d.name, d.n), nil
+}
+
+// LastNDays starts 00:00:00 of the current day and continues for the past n days
+func LastNDays(n int) SQLizer { return nDateLiteral{"LAST_N_DAYS", n} }
+
+// NextNDays starts 00:00:00 of the next day and continues for the next n days
+func NextNDays(n int) SQLizer { return nDateLiteral{"NEXT_N_DAYS", n} }
+
+// NDaysAgo starts 00:00:00 of the day n days before the current day and continues for 24 hours
+func NDaysAgo(n int) SQLizer { return nDateLiteral{"N_DAYS_AGO", n} }
+
+// LastNWeeks starts 00:00:00 of the first day of the week that started n weeks before the
+// current week and continues through the end of the day before the current week
+func LastNWeeks(n int) SQLizer { return nDateLiteral{"LAST_N_WEEKS", n} }
+
+// NextNWeeks starts 00:00:00 of the first day of the next week and continues for n weeks
+func NextNWeeks(n int) SQLizer { return nDateLiteral{"NEXT_N_WEEKS", n} }
+
+// NWeeksAgo starts 00:00:00 of the first day of the week that started n weeks before the
+// current week and continues for seven days
+func NWeeksAgo(n int) SQLizer { return nDateLiteral{"N_WEEKS_AGO", n} }
+
+// LastNMonths starts 00:00:00 of the first day of the month that started n months before the
+// current month and continues through the end of the month before the current month
+func LastNMonths(n int) SQLizer { return nDateLiteral{"LAST_N_MONTHS", n} }
+
+// NextNMonths starts 00:00:00 of the first day of the next month and continues for n months
+func NextNMonths(n int) SQLizer { return nDateLiteral{"NEXT_N_MONTHS", n} }
+
+// NMonthsAgo starts 00:00:00 of the first day of the month that started n months before the
+// current month and continues through all the days of that month
+func NMonthsAgo(n int) SQLizer { return nDateLiteral{"N_MONTHS_AGO", n} }
+
+// LastNQuarters starts 00:00:00 of the quarter that started n quarters ago and continues
+// through the end of the quarter before the current quarter
+func LastNQuarters(n int) SQLizer { return nDateLiteral{"LAST_N_QUARTERS", n} }
+
+// NextNQuarters starts 00:00:00 of the next quarter and continues for n quarters
+func NextNQuarters(n int) SQLizer { return nDateLiteral{"NEXT_N_QUARTERS", n} }
+
+// NQuartersAgo starts 00:00:00 of the quarter that started n quarters ago and continues to
+// the end of that quarter
+func NQuartersAgo(n int) SQLizer { return nDateLiteral{"N_QUARTERS_AGO", n} }
+
+// LastNYears starts 00:00:00 on January 1 n years ago and continues through the end of
+// December 31 of the previous year
+func LastNYears(n int) SQLizer { return nDateLiteral{"LAST_N_YEARS", n} }
+
+// NextNYears starts 00:00:00 on January 1 of the following year and continues for n years
+func NextNYears(n int) SQLizer { return nDateLiteral{"NEXT_N_YEARS", n} }
+
+// NYearsAgo starts 00:00:00 on January 1 n years ago and continues through all the days of
+// that year
+func NYearsAgo(n int) SQLizer { return nDateLiteral{"N_YEARS_AGO", n} }
+
+// LastNFiscalQuarters starts 00:00:00 of the fiscal quarter that started n fiscal quarters ago
+// and continues through the end of the previous fiscal quarter
+func LastNFiscalQuarters(n int) SQLizer { return nDateLiteral{"LAST_N_FISCAL_QUARTERS", n} }
+
+// NextNFiscalQuarters starts 00:00:00 on the first day of the next fiscal quarter and continues
+// for n fiscal quarters
+func NextNFiscalQuarters(n int) SQLizer { return nDateLiteral{"NEXT_N_FISCAL_QUARTERS", n} }
+
+// NFiscalQuartersAgo starts 00:00:00 of the fiscal quarter that started n fiscal quarters ago
+// and continues through the end of that fiscal quarter
+func NFiscalQuartersAgo(n int) SQLizer { return nDateLiteral{"N_FISCAL_QUARTERS_AGO", n} }
+
+// LastNFiscalYears starts 00:00:00 of the fiscal year that started n fiscal years ago and
+// continues through the end of the previous fiscal year
+func LastNFiscalYears(n int) SQLizer { return nDateLiteral{"LAST_N_FISCAL_YEARS", n} }
+
+// NextNFiscalYears starts 00:00:00 on the first day of the next fiscal year and continues for
+// n fiscal years
+func NextNFiscalYears(n int) SQLizer { return nDateLiteral{"NEXT_N_FISCAL_YEARS", n} }
+
+// NFiscalYearsAgo starts 00:00:00 of the fiscal year that started n fiscal years ago and
+// continues through the end of that fiscal year
+func NFiscalYearsAgo(n int) SQLizer { return nDateLiteral{"N_FISCAL_YEARS_AGO", n} }
+
+// DateFunction applies a SOQL date function such as CALENDAR_YEAR() to a date or datetime
+// field. It may be used as a column, a GroupByClause, an OrderByClause, or compared against
+// a value within Where and Having via its comparison methods:
+//
+// soql.Select("Id").From("Opportunity").Where(soql.CalendarYear("CloseDate").Eq(2024))
+type DateFunction struct {
+	name  string
+	field SQLizer
+}
+
+// ToSQL ...
+func (f DateFunction) ToSQL() (string, error) {
+	field, err := f.field.ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	if field == "" {
+		return "", fmt.Errorf("soql date function %s requires a field", f.name)
+	}
+
+	return fmt.Sprintf("%s(%s)", f.name, field), nil
+}
+
+// newDateFunction accepts a field as either a string or a SQLizer, such as ConvertTimezone
+func newDateFunction(name string, field interface{}) DateFunction {
+	return DateFunction{name, newBaseSQLizer(field)}
+}
+
+// CalendarMonth returns a number representing the calendar month of a date field
+func CalendarMonth(field interface{}) DateFunction { return newDateFunction("CALENDAR_MONTH", field) }
+
+// CalendarQuarter returns a number representing the calendar quarter of a date field
+func CalendarQuarter(field interface{}) DateFunction {
+	return newDateFunction("CALENDAR_QUARTER", field)
+}
+
+// CalendarYear returns a number representing the calendar year of a date field
+func CalendarYear(field interface{}) DateFunction { return newDateFunction("CALENDAR_YEAR", field) }
+
+// DayInMonth returns a number representing the day in the month of a date field
+func DayInMonth(field interface{}) DateFunction { return newDateFunction("DAY_IN_MONTH", field) }
+
+// DayInWeek returns a number representing the day of the week for a date field, 1 for Sunday
+func DayInWeek(field interface{}) DateFunction { return newDateFunction("DAY_IN_WEEK", field) }
+
+// DayInYear returns a number representing the day in the year for a date field
+func DayInYear(field interface{}) DateFunction { return newDateFunction("DAY_IN_YEAR", field) }
+
+// DayOnly returns a date representing the day portion of a datetime field
+func DayOnly(field interface{}) DateFunction { return newDateFunction("DAY_ONLY", field) }
+
+// FiscalMonth returns a number representing the fiscal month of a date field
+func FiscalMonth(field interface{}) DateFunction { return newDateFunction("FISCAL_MONTH", field) }
+
+// FiscalQuarter returns a number representing the fiscal quarter of a date field
+func FiscalQuarter(field interface{}) DateFunction { return newDateFunction("FISCAL_QUARTER", field) }
+
+// FiscalYear returns a number representing the fiscal year of a date field
+func FiscalYear(field interface{}) DateFunction { return newDateFunction("FISCAL_YEAR", field) }
+
+// HourInDay returns a number representing the hour in the day for a datetime field
+func HourInDay(field interface{}) DateFunction { return newDateFunction("HOUR_IN_DAY", field) }
+
+// WeekInMonth returns a number representing the week in the month for a date field
+func WeekInMonth(field interface{}) DateFunction { return newDateFunction("WEEK_IN_MONTH", field) }
+
+// WeekInYear returns a number representing the week in the year for a date field
+func WeekInYear(field interface{}) DateFunction { return newDateFunction("WEEK_IN_YEAR", field) }
+
+// ConvertTimezone converts a datetime field to the user's time zone. It is only valid as the
+// argument of another date function i.e. soql.HourInDay(soql.ConvertTimezone("CreatedDate"))
+func ConvertTimezone(field interface{}) DateFunction {
+	return newDateFunction("convertTimezone", field)
+}
+
+// Eq returns a "f = value" expression
+func (f DateFunction) Eq(value interface{}) SQLizer { return comparison{f, "=", value} }
+
+// NotEq returns a "f != value" expression
+func (f DateFunction) NotEq(value interface{}) SQLizer { return comparison{f, "!=", value} }
+
+// Lt returns a "f < value" expression
+func (f DateFunction) Lt(value interface{}) SQLizer { return comparison{f, "<", value} }
+
+// LtOrEq returns a "f <= value" expression
+func (f DateFunction) LtOrEq(value interface{}) SQLizer { return comparison{f, "<=", value} }
+
+// Gt returns a "f > value" expression
+func (f DateFunction) Gt(value interface{}) SQLizer { return comparison{f, ">", value} }
+
+// GtOrEq returns a "f >= value" expression
+func (f DateFunction) GtOrEq(value interface{}) SQLizer { return comparison{f, ">=", value} }
+
+// In returns a "f IN (values...)" expression
+func (f DateFunction) In(values ...interface{}) SQLizer { return comparison{f, "IN", values} }
+
+// NotIn returns a "f NOT IN (values...)" expression
+func (f DateFunction) NotIn(values ...interface{}) SQLizer { return comparison{f, "NOT IN", values} }
+
+// comparison compares an arbitrary SQLizer, such as a function call, against a value
+type comparison struct {
+	left  SQLizer
+	opr   string
+	value interface{}
+}
+
+// ToSQL ...
+func (c comparison) ToSQL() (string, error) {
+	left, err := c.left.ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	if isListType(c.value) {
+		items, err := listLiterals(c.value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", left, err)
+		}
+
+		return fmt.Sprintf("%s %s (%s)", left, c.opr, strings.Join(items, ", ")), nil
+	}
+
+	right, err := Literal(c.value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", left, err)
+	}
+
+	return fmt.Sprintf("%s %s %s", left, c.opr, right), nil
+}
@@ -0,0 +1,52 @@
+package soql_test
+
+import (
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+var dateTests = map[*exprInput]*exprOutput{
+	{soql.Eq{"CloseDate": soql.ThisFiscalQuarter}}: {"CloseDate = THIS_FISCAL_QUARTER", nil, "date literal"},
+	{soql.Eq{"CreatedDate": soql.LastNDays(30)}}:   {"CreatedDate = LAST_N_DAYS:30", nil, "parameterized date literal"},
+	{soql.GtOrEq{"CreatedDate": soql.NFiscalYearsAgo(2)}}: {
+		"CreatedDate >= N_FISCAL_YEARS_AGO:2", nil, "parameterized date literal with range operator",
+	},
+	{soql.CalendarYear("CreatedDate").Eq(2024)}: {"CALENDAR_YEAR(CreatedDate) = 2024", nil, "date function comparison"},
+	{soql.HourInDay(soql.ConvertTimezone("CreatedDate")).Gt(12)}: {
+		"HOUR_IN_DAY(convertTimezone(CreatedDate)) > 12", nil, "nested convertTimezone",
+	},
+	{soql.CalendarMonth("CloseDate").In(1, 2, 3)}: {"CALENDAR_MONTH(CloseDate) IN (1, 2, 3)", nil, "date function in list"},
+	{soql.Select("Id").From("Lead").Where(soql.Eq{"CreatedDate": soql.Today})}: {
+		"SELECT Id FROM Lead WHERE CreatedDate = TODAY", nil, "date literal within where",
+	},
+	{soql.
+		Select("COUNT(Id)").
+		Column(soql.CalendarYear("CloseDate")).
+		From("Opportunity").
+		Where(soql.DayOnly("CreatedDate").Gt(soql.Yesterday)).
+		GroupByClause(soql.CalendarYear("CloseDate")).
+		Having(soql.CalendarYear("CloseDate").GtOrEq(2020))}: {
+		"SELECT COUNT(Id), CALENDAR_YEAR(CloseDate) FROM Opportunity WHERE DAY_ONLY(CreatedDate) > YESTERDAY GROUP BY CALENDAR_YEAR(CloseDate) HAVING CALENDAR_YEAR(CloseDate) >= 2020",
+		nil, "date functions within select, where, group by and having",
+	},
+}
+
+func TestDates(t *testing.T) {
+	for in, out := range dateTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := in.expr.ToSQL()
+			require.Equal(t, out.expectedErr, err)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+}
+
+func TestDateErrors(t *testing.T) {
+	_, err := soql.LastNDays(-1).ToSQL()
+	require.NotNil(t, err)
+
+	_, err = soql.CalendarYear("").ToSQL()
+	require.NotNil(t, err)
+}
@@ -5,20 +5,74 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/types"
 )
 
 type expr struct {
-	sql string
+	sql  string
+	args []interface{}
 }
 
-// Expr represents a sql expression that is already fully formed 
-func Expr(sql string) SQLizer {
-	return expr{sql}
+// Expr represents a sql expression that is already fully formed. Any args are bound to the
+// ? placeholders within sql, in order, after being converted by Literal. Placeholders within
+// quoted strings are left untouched.
+//
+// soql.Expr("CreatedDate > ? AND IsDeleted = ?", time.Now(), false)
+func Expr(sql string, args ...interface{}) SQLizer {
+	return expr{sql, args}
 }
 
 // ToSQL ... 
 func (e expr) ToSQL() (sql string, err error) {
-	return e.sql, nil
+	if len(e.args) == 0 {
+		return e.sql, nil
+	}
+
+	return bindArgs(e.sql, e.args)
+}
+
+// bindArgs replaces each ? placeholder outside of a quoted string with the literal form of
+// the matching argument
+func bindArgs(sql string, args []interface{}) (string, error) {
+	var (
+		buff     strings.Builder
+		argIndex int
+		inQuote  bool
+		escaped  bool
+	)
+
+	for _, char := range sql {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\' && inQuote:
+			escaped = true
+		case char == '\'':
+			inQuote = !inQuote
+		case char == '?' && !inQuote:
+			if argIndex >= len(args) {
+				return "", fmt.Errorf("soql.Expr: %q has more placeholders than the %d args given", sql, len(args))
+			}
+
+			literal, err := Literal(args[argIndex])
+			if err != nil {
+				return "", fmt.Errorf("soql.Expr: binding arg %d: %w", argIndex, err)
+			}
+
+			buff.WriteString(literal)
+			argIndex++
+			continue
+		}
+
+		buff.WriteRune(char)
+	}
+
+	if argIndex != len(args) {
+		return "", fmt.Errorf("soql.Expr: %q has %d placeholders but %d args were given", sql, argIndex, len(args))
+	}
+
+	return buff.String(), nil
 }
 
 type aliasExpr struct {
@@ -58,16 +112,18 @@ func (sq subquery) ToSQL()(sql string, err error){
 }
 
 // Eq produces a complex clause for use by Where/Having methods. It may contain nested values
-// and lists which are converted into IN queries. 
+// and lists which are converted into IN queries. A Builder value is converted into a semi-join:
+//
+// soql.Eq{"Id": soql.Select("AccountId").From("Opportunity")}
+// => Id IN (SELECT AccountId FROM Opportunity)
 type Eq map[string]interface{}
 
 // toSql converts a map[string]interface{} into a set of sql expressions. It is re-used by
 // NotEq by inverting the comparison operators via the usueNotOperator argument. 
 //
-// Note that we escapeSingleQuote() properties and values where applicable. This will use the SOQL
-// escape character '\' when a single quote that is not at the beginning or end of a string is identified. 
-// it is not a silver bullet to SQL injection within a Where clause and you should still validate your
-// user input based on your own threat model. 
+// Note that values are converted by Literal which escapes quotes, backslashes, and control
+// characters within strings. Keys are not escaped. It is not a silver bullet to SQL injection
+// within a Where clause and you should still validate your user input based on your own threat model. 
 func (eq Eq) toSQL(useNotOperator bool) (sql string, err error) {
 	if len(eq) == 0 {
 		return "", nil
@@ -101,31 +157,38 @@ func (eq Eq) toSQL(useNotOperator bool) (sql string, err error) {
 				val = nil
 			} else {
 				val = r.Elem().Interface()
+				r = reflect.ValueOf(val)
 			}
 		}
 
-		// 2: if val is nil return a "key is/is not NULL" clause
-		if val == nil {
+		// 2: if val is nil, or a types.Nullable* marked IsNull, return a "key is/is not NULL" clause
+		if isNull(val) {
 			expr = fmt.Sprintf("%s %s null", key, nullOpr)
 			exprs = append(exprs, expr)
 			continue 
 		} 
 		
-		// 3: if val is list prepare an "in/not in ()" clause"
+		// 3: if val is a Builder prepare an "in/not in (SELECT ...)" semi-join or anti-join clause
+		if query, ok := val.(Builder); ok {
+			expr, err := semiJoin{key, query, useNotOperator}.ToSQL()
+			if err != nil {
+				return "", err
+			}
+
+			exprs = append(exprs, expr)
+			continue
+		}
+
+		// 4: if val is list prepare an "in/not in ()" clause"
 		if isListType(val) {
 			valVal := reflect.ValueOf(val)
 			if valVal.Len() == 0 {
 				// append empty array notation
 				expr = inEmptyExpr
 			} else {
-				var items []string
-				for i := 0; i < r.Len(); i++ {
-					v := reflect.ValueOf(r.Index(i).Interface())
-					if v.Kind() == reflect.String {
-						items = append(items, fmt.Sprintf("'%s'", r.Index(i).Interface().(string)))
-					} else {
-						items = append(items, fmt.Sprintf("%v", r.Index(i)))
-					}
+				items, err := listLiterals(val)
+				if err != nil {
+					return "", fmt.Errorf("%s: %w", key, err)
 				}
 				// append list notation
 				expr = fmt.Sprintf("%s %s (%s)", key, inOpr, strings.Join(items, ", "))
@@ -135,16 +198,13 @@ func (eq Eq) toSQL(useNotOperator bool) (sql string, err error) {
 			continue 
 		} 
 		
-		// 4: if val is a string escape any single quotes not at the beginning or end of the string 
-		if r.Kind() == reflect.String {
-			value := reflect.ValueOf(val).String()
-			expr = fmt.Sprintf("%s %s '%s'", key, equalOpr, value)
-			exprs = append(exprs, expr)
-			continue 	
-		} 
+		// 5: else prepare an "key =/!= val" clause where val is formatted as a SOQL literal
+		literal, err := Literal(val)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", key, err)
+		}
 
-		// 5: else prepare an "key =/!= val" claue
-		expr = fmt.Sprintf("%s %s %v", key, equalOpr, val)
+		expr = fmt.Sprintf("%s %s %s", key, equalOpr, literal)
 		exprs = append(exprs, expr)
 	}
 
@@ -158,7 +218,8 @@ func (eq Eq) ToSQL() (string, error) {
 	return eq.toSQL(false)
 }
 
-// NotEq is the inverse of Eq. See the documentation for Eq.
+// NotEq is the inverse of Eq. See the documentation for Eq. A Builder value is converted into
+// an anti-join i.e. Id NOT IN (SELECT AccountId FROM Opportunity)
 type NotEq Eq
 
 // ToSQL reuses Eq.toSQL() to convert NotEq to an sql string 
@@ -166,7 +227,8 @@ func (neq NotEq) ToSQL() (sql string, err error) {
 	return Eq(neq).toSQL(true)
 }
 
-// Like prepares a LIKE clause. 
+// Like prepares a LIKE clause. Quotes and backslashes within the pattern are escaped while
+// the wildcards %, _, and their escaped forms \% and \_ are preserved.
 type Like map[string]string
 
 // toSQL ...
@@ -174,7 +236,7 @@ func (lk Like) toSQL(opr string) (sql string, err error) {
 	var exprs []string
 	sortedKeys := getSortedStringKeys(lk)
 	for _, key := range sortedKeys {
-		expr := fmt.Sprintf("%s %s '%s'", key, opr, lk[key])
+		expr := fmt.Sprintf("%s %s '%s'", key, opr, escapeLike(lk[key]))
 		exprs = append(exprs, expr)
 	}
 
@@ -216,7 +278,7 @@ func (lt Lt) toSQL(opposite bool, orEqual bool) (sql string, err error) {
 		var expr string
 		v := lt[k]
 
-		if v == nil {
+		if isNull(v) {
 			return "", fmt.Errorf("cannot use null with Lt or Gt operators")
 		}
 
@@ -224,12 +286,12 @@ func (lt Lt) toSQL(opposite bool, orEqual bool) (sql string, err error) {
 			return "", fmt.Errorf("cannot use array or slice with Gt or Lt operators")
 		}
 
-		if reflect.ValueOf(v).Kind() == reflect.String {
-			expr = fmt.Sprintf("%s %s '%s'", k, opr, v)
-		} else {
-			expr = fmt.Sprintf("%s %s %v", k, opr, v)
+		literal, err := Literal(v)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", k, err)
 		}
 
+		expr = fmt.Sprintf("%s %s %s", k, opr, literal)
 		exprs = append(exprs, expr)
 	}
 
@@ -266,6 +328,74 @@ func (gtOrEq GtOrEq) ToSQL() (sql string, err error) {
 	return Lt(gtOrEq).toSQL(true, true)
 }
 
+// Includes prepares an INCLUDES clause for multi-select picklist fields. Each value may be a
+// []string of terms, a single types.MultiPicklist term, or a []types.MultiPicklist of terms. A
+// term containing several semicolon delimited values matches records with all of those values
+// selected:
+//
+// soql.Includes{"Colors__c": []string{"Red;Green", "Blue"}}
+// => Colors__c INCLUDES ('Red;Green', 'Blue')
+type Includes map[string]interface{}
+
+// toSQL is a reusable method for Includes and Excludes
+func (inc Includes) toSQL(opr string) (sql string, err error) {
+	var exprs []string
+	sortedKeys := getSortedKeys(inc)
+	for _, key := range sortedKeys {
+		terms, err := multiPicklistTerms(inc[key])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", key, err)
+		}
+
+		if len(terms) == 0 {
+			return "", fmt.Errorf("%s: cannot use an empty list with %s", key, opr)
+		}
+
+		items := make([]string, 0, len(terms))
+		for _, term := range terms {
+			items = append(items, quote(term))
+		}
+
+		exprs = append(exprs, fmt.Sprintf("%s %s (%s)", key, opr, strings.Join(items, ", ")))
+	}
+
+	sql = strings.Join(exprs, " AND ")
+	return sql, nil
+}
+
+// ToSQL ...
+func (inc Includes) ToSQL() (sql string, err error) {
+	return inc.toSQL("INCLUDES")
+}
+
+// Excludes is the inverse of Includes.
+type Excludes Includes
+
+// ToSQL ...
+func (exc Excludes) ToSQL() (sql string, err error) {
+	return Includes(exc).toSQL("EXCLUDES")
+}
+
+// multiPicklistTerms converts the accepted value types of Includes into a list of terms
+func multiPicklistTerms(val interface{}) ([]string, error) {
+	switch v := val.(type) {
+	case types.MultiPicklist:
+		return []string{v.String()}, nil
+	case []types.MultiPicklist:
+		terms := make([]string, 0, len(v))
+		for _, term := range v {
+			terms = append(terms, term.String())
+		}
+		return terms, nil
+	case []string:
+		return v, nil
+	case string:
+		return []string{v}, nil
+	}
+
+	return nil, fmt.Errorf("expected []string or types.MultiPicklist, not %T", val)
+}
+
 // conj short for conjugation is a reusable data structure for preparing AND and OR queries which
 // can be used to great effect to form deeply nested complex queries
 type conj []SQLizer
@@ -310,6 +440,22 @@ func (o Or) ToSQL() (sql string, err error) {
 	return conj(o).join(" OR ", "")
 }
 
+// Not negates the given expressions. Multiple expressions are joined by AND before being negated.
+//
+// soql.Not{soql.Eq{"Status": "Closed"}, soql.Like{"Name": "Test%"}}
+// => NOT (Status = 'Closed' AND Name LIKE 'Test%')
+type Not conj
+
+// ToSQL ...
+func (n Not) ToSQL() (sql string, err error) {
+	sql, err = conj(n).join(" AND ", "")
+	if err != nil || sql == "" {
+		return sql, err
+	}
+
+	return "NOT " + sql, nil
+}
+
 func getSortedKeys(exp map[string]interface{}) []string {
 	sortedKeys := make([]string, 0, len(exp))
 	for k := range exp {
@@ -328,36 +474,13 @@ func getSortedStringKeys(exp map[string]string) []string {
 	return sortedKeys
 }
 
+// isListType reports whether val should be expanded into a list of values. A types.MultiPicklist
+// is a single value despite being a slice.
 func isListType(val interface{}) bool {
-	valVal := reflect.ValueOf(val)
-	return valVal.Kind() == reflect.Array || valVal.Kind() == reflect.Slice
-}
-
-// I'm putting this method on ice until I have time to write a proper parser to do this well
-/**
-// escapeSingleQuote is a naive escape mechanism for single quotes which are not 
-// at the beginning or end of the given string 
-func escapeSingleQuote(str string) string {
-	return str 
-	
-	var singleQuote rune = '\''
-	var escapeKey rune = '\\'
-
-	var runes []rune
-	var lastChar rune
-	for idx, char := range str {
-		// if there is a singleQuote that is not at the beginning or end
-		// and lastChar is not an escapeKey
-		// then append an escapeKey as the preceding character
-		if idx != 0 && idx != len(str)-1 && char == singleQuote {
-			if lastChar != escapeKey {
-				runes = append(runes, escapeKey)
-			}
-		}
-		runes = append(runes, char)
-		lastChar = char
+	if _, ok := val.(types.MultiPicklist); ok {
+		return false
 	}
 
-	return string(runes)
+	valVal := reflect.ValueOf(val)
+	return valVal.Kind() == reflect.Array || valVal.Kind() == reflect.Slice
 }
-*/
\ No newline at end of file
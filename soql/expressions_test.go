@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/beeekind/go-salesforce-sdk/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -209,3 +210,54 @@ func TestSubQuery(t *testing.T) {
 		})
 	}
 }
+
+var includesTests = map[*exprInput]*exprOutput{
+	{soql.Includes{"Colors__c": []string{"Red;Green", "Blue"}}}:       {"Colors__c INCLUDES ('Red;Green', 'Blue')", nil, "includes with string slice"},
+	{soql.Includes{"Colors__c": types.MultiPicklist{"Red", "Green"}}}: {"Colors__c INCLUDES ('Red;Green')", nil, "includes with multi picklist"},
+	{soql.Includes{"b": []types.MultiPicklist{{"x"}, {"y", "z"}}, "a": "it's"}}: {
+		`a INCLUDES ('it\'s') AND b INCLUDES ('x', 'y;z')`, nil, "includes with sorted keys",
+	},
+	{soql.Excludes{"Colors__c": []string{"Red"}}}: {"Colors__c EXCLUDES ('Red')", nil, "excludes"},
+	{soql.Includes{}}: {"", nil, "empty includes clause"},
+	{soql.Eq{"Colors__c": types.MultiPicklist{"Red", "Blue"}}}: {"Colors__c = 'Red;Blue'", nil, "eq with multi picklist is an exact match"},
+}
+
+func TestIncludes(t *testing.T) {
+	for in, out := range includesTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := in.expr.ToSQL()
+			require.Equal(t, out.expectedErr, err)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+
+	_, err := soql.Includes{"Colors__c": []string{}}.ToSQL()
+	require.NotNil(t, err)
+
+	_, err = soql.Includes{"Colors__c": 1}.ToSQL()
+	require.NotNil(t, err)
+}
+
+var notTests = map[*exprInput]*exprOutput{
+	{soql.Not{soql.Eq{"Status": "Closed"}}}: {"NOT (Status = 'Closed')", nil, "not with a single expression"},
+	{soql.Not{soql.Eq{"Status": "Closed"}, soql.Like{"Name": "Test%"}}}: {
+		"NOT (Status = 'Closed' AND Name LIKE 'Test%')", nil, "not with multiple expressions",
+	},
+	{soql.And{soql.Eq{"a": 1}, soql.Not{soql.Or{soql.Eq{"b": 2}, soql.Eq{"c": 3}}}}}: {
+		"(a = 1 AND NOT ((b = 2 OR c = 3)))", nil, "nested not",
+	},
+	{soql.Not{}}: {"", nil, "empty not clause"},
+}
+
+func TestNot(t *testing.T) {
+	for in, out := range notTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := in.expr.ToSQL()
+			require.Equal(t, out.expectedErr, err)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+
+	_, err := soql.Select("Id").From("Account").Where(soql.Not{soql.Eq{"Id": soql.Select("AccountId").From("Contact")}}).ToSQL()
+	require.True(t, errors.Is(err, soql.ErrSemiJoinNot))
+}
@@ -0,0 +1,294 @@
+package soql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/metadata"
+	"github.com/lann/builder"
+)
+
+// fields.go implements the FIELDS() function of a select list and its expansion into explicit
+// columns for contexts which don't support it.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_fields.htm
+
+// FieldsGroup is the set of fields selected by FIELDS()
+type FieldsGroup string
+
+const (
+	// FieldsAll selects every field of an object
+	FieldsAll FieldsGroup = "ALL"
+	// FieldsStandard selects the standard fields of an object
+	FieldsStandard FieldsGroup = "STANDARD"
+	// FieldsCustom selects the custom fields of an object
+	FieldsCustom FieldsGroup = "CUSTOM"
+)
+
+// FieldsMinVersion is the first API version supporting FIELDS()
+const FieldsMinVersion = 51.0
+
+// FieldsMaxLimit is the greatest LIMIT of a query using FIELDS(ALL) or FIELDS(CUSTOM)
+const FieldsMaxLimit = 200
+
+var (
+	// ErrFieldsNotSupported is returned by contexts which don't support FIELDS(), such as bulk queries
+	ErrFieldsNotSupported = errors.New("FIELDS() is not supported here, expand it with a soql.FieldsExpander")
+	// ErrInvalidFieldsGroup is returned for a FIELDS() group other than ALL, STANDARD, or CUSTOM
+	ErrInvalidFieldsGroup = errors.New("FIELDS() group must be ALL, STANDARD, or CUSTOM")
+)
+
+// fieldsCall matches FIELDS() given as a raw column string
+var fieldsCall = regexp.MustCompile(`(?i)^FIELDS\(\s*(ALL|STANDARD|CUSTOM)\s*\)$`)
+
+// FieldsExpr is the FIELDS() function of a select list
+type FieldsExpr struct {
+	group FieldsGroup
+}
+
+// Fields returns the FIELDS() function for use as a column:
+//
+// soql.Select("Id").Column(soql.Fields(soql.FieldsCustom)).From("Account").Limit(200)
+// => SELECT Id, FIELDS(CUSTOM) FROM Account LIMIT 200
+func Fields(group FieldsGroup) FieldsExpr {
+	return FieldsExpr{group}
+}
+
+// Group returns ALL, STANDARD, or CUSTOM
+func (f FieldsExpr) Group() string {
+	return string(f.group)
+}
+
+// ToSQL ...
+func (f FieldsExpr) ToSQL() (string, error) {
+	switch f.group {
+	case FieldsAll, FieldsStandard, FieldsCustom:
+		return fmt.Sprintf("FIELDS(%s)", f.group), nil
+	}
+
+	return "", fmt.Errorf("%s: %w", f.group, ErrInvalidFieldsGroup)
+}
+
+// Fields adds the FIELDS() function to the select list
+func (b Builder) Fields(group FieldsGroup) Builder {
+	return b.Column(Fields(group))
+}
+
+// fieldsColumn returns the FIELDS() function of a column, if it is one
+func fieldsColumn(column interface{}) (FieldsExpr, bool) {
+	switch c := unwrap(column).(type) {
+	case FieldsExpr:
+		return c, true
+	case string:
+		if match := fieldsCall.FindStringSubmatch(strings.TrimSpace(c)); match != nil {
+			return FieldsExpr{FieldsGroup(strings.ToUpper(match[1]))}, true
+		}
+	}
+
+	return FieldsExpr{}, false
+}
+
+// ContainsFields reports whether query, or any of its child subqueries, selects FIELDS()
+func ContainsFields(query Builder) bool {
+	data := builder.GetStruct(query).(selectData)
+	for _, column := range data.Columns {
+		if child, ok := unwrap(column).(childQuery); ok && ContainsFields(child.query) {
+			return true
+		}
+
+		for _, term := range columnTerms(column) {
+			if _, ok := fieldsColumn(term); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CompoundFields controls how compound address and geolocation fields, such as BillingAddress,
+// are expanded
+type CompoundFields int
+
+const (
+	// CompoundComponents selects the components of a compound field, such as BillingStreet and
+	// BillingCity, but not the compound field itself. Bulk queries don't support compound fields.
+	CompoundComponents CompoundFields = iota
+	// CompoundOnly selects a compound field but not its components
+	CompoundOnly
+	// CompoundAndComponents selects a compound field and its components
+	CompoundAndComponents
+)
+
+// FieldsExpander replaces FIELDS() with the explicit fields of an object, as listed by its describe
+// metadata, wherever Salesforce would reject it: in bulk queries, in API versions prior to
+// FieldsMinVersion, and for FIELDS(ALL) or FIELDS(CUSTOM) in a query without a LIMIT of at most
+// FieldsMaxLimit. Elsewhere FIELDS() is left in place.
+type FieldsExpander struct {
+	// Schema provides the describe metadata of each object
+	Schema Schema
+	// Compound controls the handling of compound fields
+	Compound CompoundFields
+	// APIVersion such as "50.0" is the version the query will be executed against, the latest if empty
+	APIVersion string
+	// Bulk is true for queries executed by the Bulk API
+	Bulk bool
+}
+
+// Expand returns query with FIELDS() replaced wherever it isn't supported
+func (e *FieldsExpander) Expand(query Builder) (Builder, error) {
+	force := e.Bulk
+	if e.APIVersion != "" {
+		version, err := strconv.ParseFloat(strings.TrimPrefix(e.APIVersion, "v"), 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid api version %s: %w", e.APIVersion, err)
+		}
+		force = force || version < FieldsMinVersion
+	}
+
+	return e.expand(query, nil, force)
+}
+
+// ExpandFields replaces every FIELDS() of query with explicit fields
+func ExpandFields(query Builder, schema Schema, compound CompoundFields) (Builder, error) {
+	e := &FieldsExpander{Schema: schema, Compound: compound}
+	return e.expand(query, nil, true)
+}
+
+// expand replaces the FIELDS() of a query and its child subqueries. The object of query is looked
+// up via the schema, or for a child subquery via the child relationships of parent.
+func (e *FieldsExpander) expand(query Builder, parent *metadata.Describe, force bool) (Builder, error) {
+	data := builder.GetStruct(query).(selectData)
+	if !ContainsFields(query) {
+		return query, nil
+	}
+
+	from, ok := rawString(data.From)
+	if !ok {
+		return query, errors.New("query must select FROM an object name to expand FIELDS()")
+	}
+
+	var object *metadata.Describe
+	var err error
+	if parent == nil {
+		object, err = e.Schema.Describe(from)
+	} else {
+		object, err = (&validator{schema: e.Schema}).childObject(parent, from)
+	}
+
+	if err != nil {
+		return query, fmt.Errorf("%s: %w", from, err)
+	}
+
+	limit, err := strconv.Atoi(data.Limit)
+	bounded := err == nil && limit <= FieldsMaxLimit
+
+	// explicitly selected fields are not selected twice
+	selected := make(map[string]bool)
+	for _, column := range data.Columns {
+		for _, term := range columnTerms(column) {
+			if str, ok := term.(string); ok {
+				selected[strings.ToLower(strings.TrimSpace(str))] = true
+			}
+		}
+	}
+
+	columns := make([]SQLizer, 0, len(data.Columns))
+	for _, column := range data.Columns {
+		if child, ok := unwrap(column).(childQuery); ok {
+			expanded, err := e.expand(child.query, object, force)
+			if err != nil {
+				return query, err
+			}
+
+			columns = append(columns, childQuery{expanded})
+			continue
+		}
+
+		terms := columnTerms(column)
+		if len(terms) == 1 {
+			if _, ok := fieldsColumn(column); !ok {
+				columns = append(columns, column)
+				continue
+			}
+		}
+
+		for _, term := range terms {
+			f, ok := fieldsColumn(term)
+			if !ok {
+				columns = append(columns, newBaseSQLizer(term))
+				continue
+			}
+
+			if !force && (bounded || f.group == FieldsStandard) {
+				columns = append(columns, newBaseSQLizer(f))
+				continue
+			}
+
+			if _, err := f.ToSQL(); err != nil {
+				return query, err
+			}
+
+			for _, name := range e.fieldNames(object, f.group) {
+				if !selected[strings.ToLower(name)] {
+					selected[strings.ToLower(name)] = true
+					columns = append(columns, newBaseSQLizer(name))
+				}
+			}
+		}
+	}
+
+	return builder.Set(query, "Columns", columns).(Builder), nil
+}
+
+// fieldNames lists the fields of object belonging to group in describe order
+func (e *FieldsExpander) fieldNames(object *metadata.Describe, group FieldsGroup) []string {
+	compound := make(map[string]bool)
+	for _, field := range object.Fields {
+		if isCompoundField(field) {
+			compound[field.Name] = true
+		}
+	}
+
+	names := make([]string, 0, len(object.Fields))
+	for _, field := range object.Fields {
+		switch {
+		case field.DeprecatedAndHidden:
+			continue
+		case group == FieldsStandard && field.Custom, group == FieldsCustom && !field.Custom:
+			continue
+		case e.Compound == CompoundComponents && compound[field.Name]:
+			continue
+		case e.Compound == CompoundOnly && compound[field.CompoundFieldName]:
+			continue
+		}
+
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+// isCompoundField reports whether field is a compound address or geolocation field. Name fields
+// are compound as well but are queryable everywhere and so are treated as regular fields.
+func isCompoundField(field *metadata.Field) bool {
+	return field.Type == "address" || field.Type == "location"
+}
+
+// columnTerms splits a raw column string such as "Id, FIELDS(ALL)" into its comma separated terms
+func columnTerms(column interface{}) []interface{} {
+	str, ok := rawString(column)
+	if !ok || !strings.Contains(str, ",") {
+		return []interface{}{unwrap(column)}
+	}
+
+	var terms []interface{}
+	for _, term := range strings.Split(str, ",") {
+		terms = append(terms, strings.TrimSpace(term))
+	}
+
+	return terms
+}
@@ -0,0 +1,128 @@
+package soql_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/metadata"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+func customField(name string, fieldType string) *metadata.Field {
+	f := field(name, fieldType)
+	f.Custom = true
+	return f
+}
+
+func component(name string, compoundFieldName string) *metadata.Field {
+	f := field(name, "string")
+	f.CompoundFieldName = compoundFieldName
+	return f
+}
+
+var fieldsSchema = soql.DescribeMap{
+	"Account": {
+		Name: "Account",
+		Fields: []*metadata.Field{
+			field("Id", "id"),
+			field("Name", "string"),
+			field("BillingAddress", "address"),
+			component("BillingStreet", "BillingAddress"),
+			component("BillingCity", "BillingAddress"),
+			customField("Tier__c", "picklist"),
+			{Name: "Hidden", Type: "string", DeprecatedAndHidden: true},
+		},
+		ChildRelationships: []*metadata.ChildRelationship{
+			{ChildSObject: "Contact", Field: "AccountId", RelationshipName: "Contacts"},
+		},
+	},
+	"Contact": {
+		Name: "Contact",
+		Fields: []*metadata.Field{
+			field("Id", "id"),
+			field("Name", "string"),
+			component("FirstName", "Name"),
+			component("LastName", "Name"),
+		},
+	},
+}
+
+func TestFields(t *testing.T) {
+	sql, err := soql.Select("Id").Fields(soql.FieldsCustom).From("Account").Limit(200).ToSQL()
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id, FIELDS(CUSTOM) FROM Account LIMIT 200", sql)
+
+	require.True(t, soql.ContainsFields(soql.Select("Id, fields(all)").From("Account")))
+	require.True(t, soql.ContainsFields(soql.Select("Id").ChildQuery(soql.Select().Fields(soql.FieldsAll).From("Contacts")).From("Account")))
+	require.False(t, soql.ContainsFields(soql.Select("Id", "FieldsCount__c").From("Account")))
+
+	_, err = soql.Select().Fields(soql.FieldsGroup("SOME")).From("Account").ToSQL()
+	require.True(t, errors.Is(err, soql.ErrInvalidFieldsGroup))
+
+	require.Nil(t, soql.Validate(soql.Select("Id").Fields(soql.FieldsAll).Columns("FIELDS(CUSTOM)").From("Account").Limit(1), testSchema))
+}
+
+func TestFieldsExpander(t *testing.T) {
+	tests := map[string]struct {
+		expander *soql.FieldsExpander
+		query    soql.Builder
+		expected string
+	}{
+		"bounded query is unchanged": {
+			&soql.FieldsExpander{},
+			soql.Select("Id").Fields(soql.FieldsAll).From("Account").Limit(200),
+			"SELECT Id, FIELDS(ALL) FROM Account LIMIT 200",
+		},
+		"standard fields need no limit": {
+			&soql.FieldsExpander{},
+			soql.Select().Fields(soql.FieldsStandard).From("Account"),
+			"SELECT FIELDS(STANDARD) FROM Account",
+		},
+		"unbounded query": {
+			&soql.FieldsExpander{},
+			soql.Select("Id", "Name").Fields(soql.FieldsAll).From("Account").Limit(201),
+			"SELECT Id, Name, BillingStreet, BillingCity, Tier__c FROM Account LIMIT 201",
+		},
+		"raw column in bulk query": {
+			&soql.FieldsExpander{Bulk: true},
+			soql.Select("Id, FIELDS(custom)").From("Account").Limit(1),
+			"SELECT Id, Tier__c FROM Account LIMIT 1",
+		},
+		"compound only in old api version": {
+			&soql.FieldsExpander{APIVersion: "50.0", Compound: soql.CompoundOnly},
+			soql.Select().Fields(soql.FieldsStandard).From("Account"),
+			"SELECT Id, Name, BillingAddress FROM Account",
+		},
+		"compound and components": {
+			&soql.FieldsExpander{Bulk: true, Compound: soql.CompoundAndComponents},
+			soql.Select().Fields(soql.FieldsStandard).From("Account"),
+			"SELECT Id, Name, BillingAddress, BillingStreet, BillingCity FROM Account",
+		},
+		"child subquery and name components": {
+			&soql.FieldsExpander{},
+			soql.Select("Id").ChildQuery(soql.Select().Fields(soql.FieldsAll).From("Contacts")).From("Account").Limit(10),
+			"SELECT Id, (SELECT Id, Name, FirstName, LastName FROM Contacts) FROM Account LIMIT 10",
+		},
+	}
+
+	for description, tc := range tests {
+		t.Run(description, func(t *testing.T) {
+			tc.expander.Schema = fieldsSchema
+			expanded, err := tc.expander.Expand(tc.query)
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, expanded.MustSQL())
+		})
+	}
+}
+
+func TestExpandFieldsErrors(t *testing.T) {
+	_, err := soql.ExpandFields(soql.Select().Fields(soql.FieldsAll).From("Lead"), fieldsSchema, soql.CompoundComponents)
+	require.True(t, errors.Is(err, soql.ErrUnknownObject))
+
+	_, err = soql.ExpandFields(soql.Select().ChildQuery(soql.Select().Fields(soql.FieldsAll).From("Cases")).From("Account"), fieldsSchema, soql.CompoundComponents)
+	require.True(t, errors.Is(err, soql.ErrUnknownChildRelationship))
+
+	_, err = (&soql.FieldsExpander{Schema: fieldsSchema, APIVersion: "latest"}).Expand(soql.Select("Id").From("Account"))
+	require.NotNil(t, err)
+}
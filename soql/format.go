@@ -0,0 +1,443 @@
+package soql
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// format.go prints SOQL queries, either a Builder or a SOQL string, in a multi-line indented
+// form for logs and code review or a canonical single-line form for cache keys and metrics.
+// Both forms uppercase keywords, collapse whitespace, and order clauses as the grammar does.
+// Object, relationship, and field names are left as written, even those which are also keywords
+// such as the Order and Group objects.
+
+// formatIndent is the indentation of each nesting level of Pretty
+const formatIndent = "    "
+
+// keywords are uppercased by Pretty and Canonical
+var keywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AND": true, "OR": true, "NOT": true, "IN": true, "IS": true,
+	"LIKE": true, "INCLUDES": true, "EXCLUDES": true, "GROUP": true, "BY": true, "HAVING": true,
+	"ORDER": true, "ASC": true, "DESC": true, "NULLS": true, "FIRST": true, "LAST": true,
+	"LIMIT": true, "OFFSET": true, "FOR": true, "VIEW": true, "REFERENCE": true, "UPDATE": true,
+	"TRACKING": true, "VIEWSTAT": true, "WITH": true, "USING": true, "SCOPE": true, "TYPEOF": true,
+	"WHEN": true, "THEN": true, "ELSE": true, "END": true, "ROLLUP": true, "CUBE": true,
+	"DATA": true, "CATEGORY": true, "AT": true, "ABOVE": true, "BELOW": true, "ABOVE_OR_BELOW": true,
+	"SECURITY_ENFORCED": true, "USER_MODE": true, "SYSTEM_MODE": true,
+}
+
+// literals are lowercased to match the output of Literal
+var literals = map[string]bool{"NULL": true, "TRUE": true, "FALSE": true}
+
+// namesAfter are the tokens followed by an object, relationship, or field name rather than a
+// keyword, other than the keywords of listKeywords which may begin an item of a list
+var namesAfter = map[string]bool{
+	"FROM": true, "TYPEOF": true, "WHEN": true, "SELECT": true, ",": true, "BY": true, "THEN": true, "ELSE": true,
+}
+
+// listKeywords may begin an item of a select list or a GROUP BY clause
+var listKeywords = map[string]bool{"TYPEOF": true, "ROLLUP": true, "CUBE": true}
+
+// clauseOrder is the position of each clause within the SOQL grammar, where FOR is FOR VIEW or
+// FOR REFERENCE and UPDATE is UPDATE TRACKING or UPDATE VIEWSTAT
+var clauseOrder = map[string]int{
+	"SELECT": 0, "FROM": 1, "USING SCOPE": 2, "WHERE": 3, "WITH": 4, "GROUP BY": 5, "HAVING": 6,
+	"ORDER BY": 7, "LIMIT": 8, "OFFSET": 9, "FOR": 10, "UPDATE": 11, "FOR UPDATE": 12,
+}
+
+// Pretty returns a multi-line, indented form of query which may be a Builder, or any other
+// SQLizer, or a SOQL string:
+//
+//	SELECT
+//	    Id,
+//	    (
+//	        SELECT Id FROM Contacts
+//	    )
+//	FROM Account
+//	WHERE
+//	    Name LIKE 'Acme%'
+//	    AND CreatedDate = LAST_N_DAYS:30
+//	LIMIT 10
+func Pretty(query interface{}) (string, error) {
+	nodes, err := parseFormatNodes(query)
+	if err != nil {
+		return "", err
+	}
+
+	return formatQuery(nodes, 0), nil
+}
+
+// Canonical returns query, a Builder or SOQL string, on a single line with consistent keyword
+// casing, whitespace, and clause order. Queries which differ only in those respects produce the
+// same canonical form.
+func Canonical(query interface{}) (string, error) {
+	nodes, err := parseFormatNodes(query)
+	if err != nil {
+		return "", err
+	}
+
+	return canonicalQuery(nodes), nil
+}
+
+// formatNode is a token or a parenthesized group of nodes
+type formatNode struct {
+	token   string
+	group   []formatNode
+	isGroup bool
+}
+
+// clause is a top level clause of a query such as "ORDER BY" and the nodes that follow it
+type clause struct {
+	keyword string
+	body    []formatNode
+}
+
+func parseFormatNodes(query interface{}) ([]formatNode, error) {
+	var sql string
+	switch q := query.(type) {
+	case string:
+		sql = q
+	case SQLizer:
+		s, err := q.ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+		sql = s
+	default:
+		return nil, fmt.Errorf("soql.Pretty: expected string or SQLizer, not %T", query)
+	}
+
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, rest, err := groupTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 0 {
+		return nil, errors.New("soql.Pretty: unbalanced parentheses")
+	}
+
+	normalizeCase(nodes)
+	return nodes, nil
+}
+
+// tokenize splits sql into string literals, parentheses, commas, operators, and words
+func tokenize(sql string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			j := i + 1
+			for ; j < len(sql); j++ {
+				if sql[j] == '\\' {
+					j++
+					continue
+				}
+				if sql[j] == '\'' {
+					break
+				}
+			}
+
+			if j >= len(sql) {
+				return nil, errors.New("soql.Pretty: unterminated string literal")
+			}
+
+			tokens = append(tokens, sql[i:j+1])
+			i = j + 1
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.IndexByte("=<>!", c) >= 0:
+			j := i
+			for j < len(sql) && strings.IndexByte("=<>!", sql[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, sql[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(sql) && strings.IndexByte(" \t\n\r'(),=<>!", sql[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, sql[i:j])
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// groupTokens nests the tokens between parentheses. It returns the tokens remaining after the
+// closing parenthesis of the current group.
+func groupTokens(tokens []string) ([]formatNode, []string, error) {
+	var nodes []formatNode
+	for len(tokens) > 0 {
+		token := tokens[0]
+		tokens = tokens[1:]
+
+		switch token {
+		case "(":
+			group, rest, err := groupTokens(tokens)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if len(rest) == 0 || rest[0] != ")" {
+				return nil, nil, errors.New("soql.Pretty: unbalanced parentheses")
+			}
+
+			nodes = append(nodes, formatNode{group: group, isGroup: true})
+			tokens = rest[1:]
+		case ")":
+			return nodes, append([]string{")"}, tokens...), nil
+		default:
+			nodes = append(nodes, formatNode{token: token})
+		}
+	}
+
+	return nodes, nil, nil
+}
+
+// normalizeCase uppercases keywords and lowercases null, true, and false within nodes. A keyword
+// is a name, and left as written, where the grammar expects a name or when it is compared.
+func normalizeCase(nodes []formatNode) {
+	previous := ""
+	for i := range nodes {
+		if nodes[i].isGroup {
+			normalizeCase(nodes[i].group)
+			previous = ""
+			continue
+		}
+
+		upper := strings.ToUpper(nodes[i].token)
+		switch {
+		case literals[upper]:
+			nodes[i].token = strings.ToLower(nodes[i].token)
+		case keywords[upper] && !isName(nodes, i, previous, upper):
+			nodes[i].token = upper
+		}
+		previous = nodes[i].token
+	}
+}
+
+// isName reports whether the keyword upper at nodes[i], following the token previous, is a name
+func isName(nodes []formatNode, i int, previous string, upper string) bool {
+	if namesAfter[previous] && !listKeywords[upper] {
+		return true
+	}
+
+	// a name compared by an operator such as Group = 'a'
+	return i+1 < len(nodes) && !nodes[i+1].isGroup && strings.IndexByte("=<>!", nodes[i+1].token[0]) >= 0
+}
+
+// splitClauses divides the top level nodes of a query into clauses in grammar order
+func splitClauses(nodes []formatNode) []clause {
+	var clauses []clause
+	for i := 0; i < len(nodes); i++ {
+		keyword := clauseKeyword(nodes, i)
+		if keyword == "" || (len(clauses) > 0 && clauses[len(clauses)-1].keyword == "FOR" && len(clauses[len(clauses)-1].body) == 0) {
+			if len(clauses) == 0 {
+				clauses = append(clauses, clause{})
+			}
+			clauses[len(clauses)-1].body = append(clauses[len(clauses)-1].body, nodes[i])
+			continue
+		}
+
+		clauses = append(clauses, clause{keyword: keyword})
+		i += len(strings.Fields(keyword)) - 1
+	}
+
+	sort.SliceStable(clauses, func(i, j int) bool {
+		return clauseOrder[clauses[i].keyword] < clauseOrder[clauses[j].keyword]
+	})
+
+	return clauses
+}
+
+// clauseKeyword returns the clause keyword starting at nodes[i], if any
+func clauseKeyword(nodes []formatNode, i int) string {
+	if nodes[i].isGroup {
+		return ""
+	}
+
+	next := ""
+	if i+1 < len(nodes) && !nodes[i+1].isGroup {
+		next = nodes[i+1].token
+	}
+
+	switch token := nodes[i].token; token {
+	case "SELECT", "FROM", "WHERE", "WITH", "HAVING", "LIMIT", "OFFSET", "UPDATE":
+		return token
+	case "FOR":
+		if next == "UPDATE" {
+			return "FOR UPDATE"
+		}
+		return token
+	case "GROUP", "ORDER":
+		if next == "BY" {
+			return token + " BY"
+		}
+	case "USING":
+		if next == "SCOPE" {
+			return "USING SCOPE"
+		}
+	}
+
+	return ""
+}
+
+// isSubquery reports whether a group holds a query
+func isSubquery(nodes []formatNode) bool {
+	return len(nodes) > 0 && !nodes[0].isGroup && nodes[0].token == "SELECT"
+}
+
+// canonicalQuery renders the clauses of a query on a single line
+func canonicalQuery(nodes []formatNode) string {
+	if !isSubquery(nodes) {
+		return inline(nodes, -1)
+	}
+
+	parts := make([]string, 0, len(nodes))
+	for _, c := range splitClauses(nodes) {
+		parts = append(parts, strings.TrimSpace(c.keyword+" "+inline(c.body, -1)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatQuery renders the clauses of a query on separate lines at the given indentation
+func formatQuery(nodes []formatNode, indent int) string {
+	pad := strings.Repeat(formatIndent, indent)
+	if !isSubquery(nodes) {
+		return pad + inline(nodes, indent)
+	}
+
+	var lines []string
+	for _, c := range splitClauses(nodes) {
+		switch c.keyword {
+		case "SELECT":
+			items := splitItems(c.body)
+			if len(items) == 1 && !containsSubquery(items[0]) {
+				lines = append(lines, pad+"SELECT "+inline(items[0], indent))
+				continue
+			}
+
+			lines = append(lines, pad+"SELECT")
+			for i, item := range items {
+				line := pad + formatIndent + inline(item, indent+1)
+				if i < len(items)-1 {
+					line += ","
+				}
+				lines = append(lines, line)
+			}
+		case "WHERE", "HAVING":
+			conditions := splitConditions(c.body)
+			if len(conditions) == 1 && !containsSubquery(conditions[0]) {
+				lines = append(lines, pad+c.keyword+" "+inline(conditions[0], indent))
+				continue
+			}
+
+			lines = append(lines, pad+c.keyword)
+			for _, condition := range conditions {
+				lines = append(lines, pad+formatIndent+inline(condition, indent+1))
+			}
+		default:
+			lines = append(lines, strings.TrimRight(pad+c.keyword+" "+inline(c.body, indent), " "))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// inline joins nodes with single spaces. Subqueries are rendered by formatQuery on their own
+// lines unless indent is negative, in which case they are rendered by canonicalQuery.
+func inline(nodes []formatNode, indent int) string {
+	var sql strings.Builder
+	for i, node := range nodes {
+		if i > 0 && !(node.token == "," && !node.isGroup) && !(node.isGroup && isFunctionName(nodes[i-1])) {
+			sql.WriteString(" ")
+		}
+
+		if !node.isGroup {
+			sql.WriteString(node.token)
+			continue
+		}
+
+		switch {
+		case isSubquery(node.group) && indent < 0:
+			sql.WriteString("(" + canonicalQuery(node.group) + ")")
+		case isSubquery(node.group):
+			pad := strings.Repeat(formatIndent, indent)
+			sql.WriteString("(\n" + formatQuery(node.group, indent+1) + "\n" + pad + ")")
+		default:
+			sql.WriteString("(" + inline(node.group, indent) + ")")
+		}
+	}
+
+	return sql.String()
+}
+
+// isFunctionName reports whether node is a word immediately followed by its arguments
+func isFunctionName(node formatNode) bool {
+	if node.isGroup || node.token == "," || strings.IndexByte("=<>!'", node.token[0]) >= 0 {
+		return false
+	}
+
+	return !keywords[node.token] || node.token == "ROLLUP" || node.token == "CUBE"
+}
+
+// splitItems splits a select list at its top level commas, ignoring those within TYPEOF
+func splitItems(nodes []formatNode) [][]formatNode {
+	var items [][]formatNode
+	var current []formatNode
+	var typeOf bool
+	for _, node := range nodes {
+		switch {
+		case !node.isGroup && node.token == "TYPEOF":
+			typeOf = true
+		case !node.isGroup && node.token == "END":
+			typeOf = false
+		case !node.isGroup && node.token == "," && !typeOf:
+			items = append(items, current)
+			current = nil
+			continue
+		}
+		current = append(current, node)
+	}
+
+	return append(items, current)
+}
+
+// splitConditions splits a WHERE or HAVING clause before each top level AND or OR
+func splitConditions(nodes []formatNode) [][]formatNode {
+	var conditions [][]formatNode
+	var current []formatNode
+	for _, node := range nodes {
+		if !node.isGroup && (node.token == "AND" || node.token == "OR") && len(current) > 0 {
+			conditions = append(conditions, current)
+			current = nil
+		}
+		current = append(current, node)
+	}
+
+	return append(conditions, current)
+}
+
+// containsSubquery reports whether any group within nodes holds a query
+func containsSubquery(nodes []formatNode) bool {
+	for _, node := range nodes {
+		if node.isGroup && (isSubquery(node.group) || containsSubquery(node.group)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package soql_test
+
+import (
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPretty(t *testing.T) {
+	query := soql.Select("Id", "Name").
+		Column(soql.SubQuery(soql.Select("Id").From("Contacts").Limit(5))).
+		From("Account").
+		Where(soql.Like{"Name": "Acme%"}).
+		Where(soql.Or{soql.Eq{"Type": "Customer"}, soql.Eq{"Type": "Partner"}}).
+		OrderBy("Name").
+		Limit(10)
+
+	pretty, err := soql.Pretty(query)
+	require.Nil(t, err)
+	require.Equal(t, `SELECT
+    Id,
+    Name,
+    (
+        SELECT Id
+        FROM Contacts
+        LIMIT 5
+    )
+FROM Account
+WHERE
+    Name LIKE 'Acme%'
+    AND (Type = 'Customer' OR Type = 'Partner')
+ORDER BY Name
+LIMIT 10`, pretty)
+}
+
+func TestPrettyString(t *testing.T) {
+	pretty, err := soql.Pretty("select id, count(name) cnt from lead where status != 'a  b' and createddate = LAST_N_DAYS:5 or isdeleted = TRUE group by rollup(id) having count(name) > 1")
+	require.Nil(t, err)
+	require.Equal(t, `SELECT
+    id,
+    count(name) cnt
+FROM lead
+WHERE
+    status != 'a  b'
+    AND createddate = LAST_N_DAYS:5
+    OR isdeleted = true
+GROUP BY ROLLUP(id)
+HAVING count(name) > 1`, pretty)
+}
+
+func TestCanonical(t *testing.T) {
+	expected := "SELECT Id, (SELECT Id FROM Contacts) FROM Account WHERE Name IN ('a', 'b\\'c') ORDER BY Name DESC NULLS LAST LIMIT 1"
+	for _, sql := range []string{
+		expected,
+		"select  Id ,( select Id from Contacts )\n\tfrom Account where Name in('a','b\\'c') order by Name desc nulls last limit 1",
+		"SELECT Id, (SELECT Id FROM Contacts) FROM Account LIMIT 1 ORDER BY Name DESC NULLS LAST WHERE Name IN ('a', 'b\\'c')",
+	} {
+		canonical, err := soql.Canonical(sql)
+		require.Nil(t, err)
+		require.Equal(t, expected, canonical)
+	}
+
+	canonical, err := soql.Canonical(soql.Select("Id").From("Lead").Where(soql.Eq{"Email": nil}).ForUpdate())
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id FROM Lead WHERE Email IS null FOR UPDATE", canonical)
+
+	lower, err := soql.Canonical("select Id from Lead where Email is null for update")
+	require.Nil(t, err)
+	require.Equal(t, canonical, lower)
+
+	expected = "SELECT Id FROM Account LIMIT 1 FOR REFERENCE UPDATE TRACKING FOR UPDATE"
+	for _, sql := range []string{
+		expected,
+		"SELECT Id FROM Account FOR UPDATE LIMIT 1 UPDATE TRACKING FOR REFERENCE",
+		"select Id from Account limit 1 for reference for update update tracking",
+	} {
+		canonical, err := soql.Canonical(sql)
+		require.Nil(t, err)
+		require.Equal(t, expected, canonical)
+	}
+}
+
+func TestCanonicalKeepsNames(t *testing.T) {
+	for sql, expected := range map[string]string{
+		"select Id from Order where Status = 'Draft' order by OrderNumber":      "SELECT Id FROM Order WHERE Status = 'Draft' ORDER BY OrderNumber",
+		"select Id, (select Id from Group) from User":                           "SELECT Id, (SELECT Id FROM Group) FROM User",
+		"select Id, typeof What when Order then Status else Name end from Task": "SELECT Id, TYPEOF What WHEN Order THEN Status ELSE Name END FROM Task",
+		"select Id from Account group by rollup(Type), Data where Scope = 'a'":  "SELECT Id FROM Account WHERE Scope = 'a' GROUP BY ROLLUP(Type), Data",
+		"select Id from order": "SELECT Id FROM order",
+	} {
+		canonical, err := soql.Canonical(sql)
+		require.Nil(t, err)
+		require.Equal(t, expected, canonical)
+	}
+
+	pretty, err := soql.Pretty("select Id from Group where Type = 'Queue'")
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id\nFROM Group\nWHERE Type = 'Queue'", pretty)
+}
+
+func TestPrettyErrors(t *testing.T) {
+	for description, query := range map[string]interface{}{
+		"unterminated string": "SELECT Id FROM Lead WHERE Name = 'a",
+		"unclosed group":      "SELECT Id FROM Lead WHERE (Name = 'a'",
+		"unopened group":      "SELECT Id FROM Lead WHERE Name = 'a')",
+		"unsupported type":    42,
+		"invalid builder":     soql.Select().From("Lead"),
+	} {
+		t.Run(description, func(t *testing.T) {
+			_, err := soql.Pretty(query)
+			require.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package soql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/types"
+)
+
+// literal.go converts go values into SOQL literals. Every expression in this package routes
+// its values through Literal so that strings are escaped consistently and values from the
+// types package render the way the Salesforce query parser expects them to.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_quotedstringescapes.htm
+
+const (
+	// DateLiteralFormat is the format of a SOQL date literal i.e. 2020-12-31
+	DateLiteralFormat = "2006-01-02"
+	// DatetimeLiteralFormat is the format of a SOQL datetime literal. Datetimes are always
+	// converted to UTC before formatting i.e. 2020-12-31T23:59:59Z
+	DatetimeLiteralFormat = "2006-01-02T15:04:05Z"
+)
+
+// nullLiteral is the SOQL representation of a null value
+const nullLiteral = "null"
+
+// stringEscaper escapes the characters which SOQL requires to be escaped within a quoted string.
+// Backslashes are replaced first (strings.Replacer never re-processes replaced output) so an
+// input such as \' can't be used to terminate the string early.
+var stringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"\b", `\b`,
+	"\f", `\f`,
+)
+
+// likeEscaper is identical to stringEscaper except that the LIKE wildcard escapes \% and \_
+// are preserved so that callers may still match literal percent and underscore characters.
+var likeEscaper = strings.NewReplacer(
+	`\%`, `\%`,
+	`\_`, `\_`,
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"\b", `\b`,
+	"\f", `\f`,
+)
+
+// EscapeString escapes the reserved characters of a SOQL string literal. The result does not
+// include the surrounding single quotes.
+func EscapeString(str string) string {
+	return stringEscaper.Replace(str)
+}
+
+// escapeLike escapes the value of a LIKE pattern while leaving its wildcards intact
+func escapeLike(pattern string) string {
+	return likeEscaper.Replace(pattern)
+}
+
+// quote escapes and single quotes the given string
+func quote(str string) string {
+	return "'" + EscapeString(str) + "'"
+}
+
+// Literal converts value into a SOQL literal:
+//
+// * nil, nil pointers, and types.Nullable* values where IsNull is true become null
+// * strings (and types with an underlying string kind) are escaped and single quoted
+// * booleans become unquoted true or false
+// * integers and floats are unquoted and never use exponent notation
+// * time.Time and types.Datetime become unquoted ISO-8601 datetimes in UTC
+// * types.Date becomes an unquoted YYYY-MM-DD date
+// * a SQLizer, such as a date literal or date function, is inlined as-is
+//
+// Slices and arrays are not accepted here as they are only valid within an IN clause. See
+// Eq for how lists are handled.
+func Literal(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nullLiteral, nil
+	case SQLizer:
+		return v.ToSQL()
+	case string:
+		return quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return formatDatetime(v), nil
+	case types.Date:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return v.Value.Format(DateLiteralFormat), nil
+	case types.Datetime:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return formatDatetime(v.Value), nil
+	case types.NullableString:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return quote(v.Value), nil
+	case types.NullableBool:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return strconv.FormatBool(v.Value), nil
+	case types.AlmostBool:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return strconv.FormatBool(v.Value), nil
+	case types.NullableInt:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return strconv.Itoa(v.Value), nil
+	case types.NullableInt64:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return strconv.FormatInt(v.Value, 10), nil
+	case types.NullableFloat64:
+		if v.IsNull {
+			return nullLiteral, nil
+		}
+		return strconv.FormatFloat(v.Value, 'f', -1, 64), nil
+	case types.MultiPicklist:
+		return quote(v.String()), nil
+	case types.Address, *types.Address:
+		return "", fmt.Errorf("soql.Literal: compound address fields cannot be used as a literal")
+	}
+
+	// handle pointers to any of the above as well as named types such as `type status string`
+	r := reflect.ValueOf(value)
+	switch r.Kind() {
+	case reflect.Ptr:
+		if r.IsNil() {
+			return nullLiteral, nil
+		}
+		return Literal(r.Elem().Interface())
+	case reflect.String:
+		return quote(r.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(r.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(r.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(r.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(r.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(r.Float(), 'f', -1, 64), nil
+	case reflect.Slice, reflect.Array:
+		return "", fmt.Errorf("soql.Literal: %T is a list and may only be used within an IN clause", value)
+	}
+
+	return "", fmt.Errorf("soql.Literal: unsupported type %T", value)
+}
+
+// isNull reports whether value should be rendered as a SOQL null
+func isNull(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case types.Date:
+		return v.IsNull
+	case types.Datetime:
+		return v.IsNull
+	case types.NullableString:
+		return v.IsNull
+	case types.NullableBool:
+		return v.IsNull
+	case types.AlmostBool:
+		return v.IsNull
+	case types.NullableInt:
+		return v.IsNull
+	case types.NullableInt64:
+		return v.IsNull
+	case types.NullableFloat64:
+		return v.IsNull
+	}
+
+	r := reflect.ValueOf(value)
+	if r.Kind() == reflect.Ptr {
+		return r.IsNil() || isNull(r.Elem().Interface())
+	}
+
+	return false
+}
+
+// listLiterals converts each element of a slice or array into a SOQL literal
+func listLiterals(list interface{}) ([]string, error) {
+	r := reflect.ValueOf(list)
+	items := make([]string, 0, r.Len())
+	for i := 0; i < r.Len(); i++ {
+		item, err := Literal(r.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// formatDatetime formats t as a SOQL datetime literal
+func formatDatetime(t time.Time) string {
+	return t.UTC().Format(DatetimeLiteralFormat)
+}
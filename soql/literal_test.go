@@ -0,0 +1,96 @@
+package soql_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/beeekind/go-salesforce-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type literalOutput struct {
+	expectedSQL string
+	expectedErr bool
+	description string
+}
+
+var (
+	datetime = time.Date(2020, 12, 31, 18, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	date     = time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
+	nullStr  = types.NullableString{IsNull: true}
+	str      = "ptr"
+)
+
+type status string
+
+var literalTests = map[*struct{ value interface{} }]*literalOutput{
+	{nil}:                             {"null", false, "nil"},
+	{"foo"}:                           {"'foo'", false, "string"},
+	{`it's a \' trap`}:                {`'it\'s a \\\' trap'`, false, "string with quotes and backslashes"},
+	{"line\nbreak\t"}:                 {`'line\nbreak\t'`, false, "string with control characters"},
+	{status("Open")}:                  {"'Open'", false, "named string type"},
+	{&str}:                            {"'ptr'", false, "pointer to string"},
+	{(*string)(nil)}:                  {"null", false, "nil pointer"},
+	{true}:                            {"true", false, "bool"},
+	{42}:                              {"42", false, "int"},
+	{uint8(7)}:                        {"7", false, "uint8"},
+	{1234567.5}:                       {"1234567.5", false, "float64 without exponent"},
+	{datetime}:                        {"2020-12-31T23:30:00Z", false, "time.Time converted to UTC"},
+	{types.NewDatetime(datetime)}:     {"2020-12-31T23:30:00Z", false, "types.Datetime"},
+	{types.Datetime{IsNull: true}}:    {"null", false, "null types.Datetime"},
+	{types.NewDate(date)}:             {"2020-12-31", false, "types.Date"},
+	{types.NewString("it's")}:         {`'it\'s'`, false, "types.NullableString"},
+	{nullStr}:                         {"null", false, "null types.NullableString"},
+	{types.NullableBool{Value: true}}: {"true", false, "types.NullableBool"},
+	{types.NewInt(3)}:                 {"3", false, "types.NullableInt"},
+	{types.NewInt64(3000000000)}:      {"3000000000", false, "types.NullableInt64"},
+	{types.NewFloat64(0.000001)}:      {"0.000001", false, "types.NullableFloat64"},
+	{types.AlmostBool{Value: false}}:  {"false", false, "types.AlmostBool"},
+	{soql.Expr("LAST_N_DAYS:30")}:     {"LAST_N_DAYS:30", false, "SQLizer is inlined"},
+	{[]string{"a"}}:                   {"", true, "slices are rejected"},
+	{types.Address{}}:                 {"", true, "compound address is rejected"},
+	{struct{}{}}:                      {"", true, "unsupported type"},
+}
+
+func TestLiteral(t *testing.T) {
+	for in, out := range literalTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := soql.Literal(in.value)
+			require.Equal(t, out.expectedErr, err != nil)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+}
+
+var typedExprTests = map[*exprInput]*exprOutput{
+	{soql.Eq{"IsDeleted": false}}:                                 {"IsDeleted = false", nil, "eq with bool"},
+	{soql.Eq{"CloseDate": types.NewDate(date)}}:                   {"CloseDate = 2020-12-31", nil, "eq with types.Date"},
+	{soql.Eq{"Title": types.NullableString{IsNull: true}}}:        {"Title IS null", nil, "eq with null types.NullableString"},
+	{soql.Eq{"Name": []interface{}{"a'b", types.NewString("c")}}}: {`Name IN ('a\'b', 'c')`, nil, "eq with escaped list"},
+	{soql.Gt{"CreatedDate": datetime}}:                            {"CreatedDate > 2020-12-31T23:30:00Z", nil, "gt with time.Time"},
+	{soql.Lt{"Amount": types.NullableFloat64{IsNull: true}}}:      {"", errors.New("cannot use null with Lt or Gt operators"), "lt with null types.NullableFloat64"},
+	{soql.Like{"Name": `50\% off 'sale'%`}}:                       {`Name LIKE '50\% off \'sale\'%'`, nil, "like preserves wildcard escapes"},
+	{soql.Expr("CreatedDate > ? AND Name = '?' AND IsDeleted = ?", datetime, false)}: {
+		"CreatedDate > 2020-12-31T23:30:00Z AND Name = '?' AND IsDeleted = false", nil, "expr with bind args",
+	},
+}
+
+func TestTypedExpressions(t *testing.T) {
+	for in, out := range typedExprTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := in.expr.ToSQL()
+			require.Equal(t, out.expectedErr, err)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+}
+
+func TestExprArgMismatch(t *testing.T) {
+	_, err := soql.Expr("a = ? AND b = ?", 1).ToSQL()
+	require.NotNil(t, err)
+
+	_, err = soql.Expr("a = ?", 1, 2).ToSQL()
+	require.NotNil(t, err)
+}
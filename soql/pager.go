@@ -0,0 +1,293 @@
+package soql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/types"
+	"github.com/lann/builder"
+)
+
+// pager.go implements keyset pagination. Salesforce limits OFFSET to 2,000 rows so rather than
+// skipping rows a Pager orders a query by a unique key and filters for the rows after (or
+// before) the last key seen, which works for any number of pages.
+
+// ErrInvalidCursor is returned when a cursor token is malformed or belongs to a different Pager
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Pager pages through the results of a query by a unique sort key, Id by default:
+//
+//	pager := soql.NewPager(soql.Select("Id", "Name").From("Lead"), 50)
+//	query, err := pager.Query(cursor)
+//	// execute query and decode the records into leads
+//	page, err := pager.Page(cursor, &leads)
+//	// hand page.Next and page.Previous to the client
+type Pager struct {
+	query Builder
+	key   string
+	size  int
+}
+
+// Page holds the cursor tokens of the pages adjacent to the current page. A token is empty
+// when there is no page in that direction.
+type Page struct {
+	Next     string
+	Previous string
+}
+
+// cursor is the decoded form of a cursor token
+type cursor struct {
+	Key       string `json:"k"`
+	Value     string `json:"v"`
+	Kind      string `json:"t"`
+	Backwards bool   `json:"b,omitempty"`
+}
+
+// kinds of cursor values
+const (
+	cursorString   = "s"
+	cursorNumber   = "n"
+	cursorDate     = "d"
+	cursorDatetime = "dt"
+)
+
+// cursorDatetimeFormat renders the datetime of a cursor with the millisecond precision of
+// Salesforce, as rows created within the same second would otherwise be skipped or repeated
+const cursorDatetimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// cursorDatetimeLiteral is a datetime rendered with cursorDatetimeFormat
+type cursorDatetimeLiteral time.Time
+
+// ToSQL ...
+func (t cursorDatetimeLiteral) ToSQL() (string, error) {
+	return time.Time(t).UTC().Format(cursorDatetimeFormat), nil
+}
+
+// NewPager returns a Pager of pageSize rows over query. Any ORDER BY, LIMIT, or OFFSET clauses
+// of query are replaced.
+func NewPager(query Builder, pageSize int) Pager {
+	return Pager{query: query, key: "Id", size: pageSize}
+}
+
+// OrderKey sets the field the pages are ordered by. The field must be unique and non-null for
+// every row, such as Id or an external id field.
+func (p Pager) OrderKey(field string) Pager {
+	p.key = field
+	return p
+}
+
+// Query returns the query for the page identified by token, or the first page if token is
+// empty. One row more than the page size is requested so that Page can tell whether another
+// page follows.
+func (p Pager) Query(token string) (Builder, error) {
+	if p.size <= 0 {
+		return p.query, fmt.Errorf("soql.Pager: page size must be greater than 0, not %d", p.size)
+	}
+
+	query := builder.Delete(builder.Delete(p.query, "OrderByParts"), "Offset").(Builder)
+	query = query.Limit(p.size + 1)
+
+	if token == "" {
+		return query.OrderByClause(Asc(p.key)), nil
+	}
+
+	c, err := p.decode(token)
+	if err != nil {
+		return p.query, err
+	}
+
+	value, err := c.value()
+	if err != nil {
+		return p.query, err
+	}
+
+	if c.Backwards {
+		return query.Where(Lt{p.key: value}).OrderByClause(Desc(p.key)), nil
+	}
+
+	return query.Where(Gt{p.key: value}).OrderByClause(Asc(p.key)), nil
+}
+
+// Page trims and orders records, a pointer to the slice of structs or struct pointers decoded
+// from the results of Query(token), and returns the cursor tokens of the adjacent pages. The
+// struct field holding the order key is found by its soql or json tag, or its name.
+func (p Pager) Page(token string, records interface{}) (Page, error) {
+	var page Page
+
+	var backwards bool
+	if token != "" {
+		c, err := p.decode(token)
+		if err != nil {
+			return page, err
+		}
+		backwards = c.Backwards
+	}
+
+	slice := reflect.ValueOf(records)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return page, fmt.Errorf("soql.Pager: records must be a pointer to a slice, not %T", records)
+	}
+	slice = slice.Elem()
+
+	more := slice.Len() > p.size
+	if more {
+		slice.Set(slice.Slice(0, p.size))
+	}
+
+	// a page retrieved backwards is in descending order
+	if backwards {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if slice.Len() == 0 {
+		return page, nil
+	}
+
+	first, err := p.encode(slice.Index(0), true)
+	if err != nil {
+		return page, err
+	}
+
+	last, err := p.encode(slice.Index(slice.Len()-1), false)
+	if err != nil {
+		return page, err
+	}
+
+	switch {
+	case token == "":
+		if more {
+			page.Next = last
+		}
+	case backwards:
+		page.Next = last
+		if more {
+			page.Previous = first
+		}
+	default:
+		page.Previous = first
+		if more {
+			page.Next = last
+		}
+	}
+
+	return page, nil
+}
+
+// encode creates the cursor token for the key of record
+func (p Pager) encode(record reflect.Value, backwards bool) (string, error) {
+	for record.Kind() == reflect.Ptr || record.Kind() == reflect.Interface {
+		if record.IsNil() {
+			return "", errors.New("soql.Pager: records must not contain nil values")
+		}
+		record = record.Elem()
+	}
+
+	if record.Kind() != reflect.Struct {
+		return "", fmt.Errorf("soql.Pager: records must be structs, not %s", record.Type())
+	}
+
+	index, ok := aggregateFields(record.Type())[strings.ToLower(p.key)]
+	if !ok {
+		return "", fmt.Errorf("soql.Pager: %s has no field for order key %s", record.Type(), p.key)
+	}
+
+	c := cursor{Key: p.key, Backwards: backwards}
+	switch v := record.FieldByIndex(index).Interface().(type) {
+	case string:
+		c.Kind, c.Value = cursorString, v
+	case types.NullableString:
+		c.Kind, c.Value = cursorString, v.Value
+	case time.Time:
+		c.Kind, c.Value = cursorDatetime, v.UTC().Format(time.RFC3339Nano)
+	case types.Datetime:
+		c.Kind, c.Value = cursorDatetime, v.Value.UTC().Format(time.RFC3339Nano)
+	case types.Date:
+		c.Kind, c.Value = cursorDate, v.Value.Format(DateLiteralFormat)
+	case types.NullableInt:
+		c.Kind, c.Value = cursorNumber, strconv.Itoa(v.Value)
+	case types.NullableInt64:
+		c.Kind, c.Value = cursorNumber, strconv.FormatInt(v.Value, 10)
+	case types.NullableFloat64:
+		c.Kind, c.Value = cursorNumber, strconv.FormatFloat(v.Value, 'f', -1, 64)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		c.Kind, c.Value = cursorNumber, fmt.Sprint(v)
+	default:
+		return "", fmt.Errorf("soql.Pager: unsupported order key type %T", v)
+	}
+
+	if c.Value == "" || isNull(record.FieldByIndex(index).Interface()) {
+		return "", fmt.Errorf("soql.Pager: order key %s must not be empty", p.key)
+	}
+
+	contents, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(contents), nil
+}
+
+// decode parses a cursor token created by a Pager with the same order key
+func (p Pager) decode(token string) (c cursor, err error) {
+	contents, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+	}
+
+	if err := json.Unmarshal(contents, &c); err != nil {
+		return c, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+	}
+
+	if c.Key != p.key {
+		return c, fmt.Errorf("%w: cursor is ordered by %s not %s", ErrInvalidCursor, c.Key, p.key)
+	}
+
+	return c, nil
+}
+
+// value converts the cursor value back into a go value. Tokens come from untrusted clients so
+// the value is always parsed into its kind and rendered by Literal, never inlined as-is.
+func (c cursor) value() (interface{}, error) {
+	switch c.Kind {
+	case cursorString:
+		return c.Value, nil
+	case cursorNumber:
+		if n, err := strconv.ParseInt(c.Value, 10, 64); err == nil {
+			return n, nil
+		}
+
+		n, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+		}
+
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, fmt.Errorf("%w: %s is not a number", ErrInvalidCursor, c.Value)
+		}
+		return n, nil
+	case cursorDatetime:
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+		}
+		return cursorDatetimeLiteral(t), nil
+	case cursorDate:
+		t, err := time.Parse(DateLiteralFormat, c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+		}
+		return types.Date{Value: t, IsHydrated: true}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown value kind %q", ErrInvalidCursor, c.Kind)
+}
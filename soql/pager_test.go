@@ -0,0 +1,131 @@
+package soql_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/beeekind/go-salesforce-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type pagedLead struct {
+	ID          string         `json:"Id"`
+	Name        string         `json:"Name"`
+	CreatedDate types.Datetime `json:"CreatedDate"`
+}
+
+func leadsWithIDs(ids ...string) []*pagedLead {
+	leads := make([]*pagedLead, 0, len(ids))
+	for _, id := range ids {
+		leads = append(leads, &pagedLead{ID: id})
+	}
+	return leads
+}
+
+func ids(leads []*pagedLead) []string {
+	ids := make([]string, 0, len(leads))
+	for _, lead := range leads {
+		ids = append(ids, lead.ID)
+	}
+	return ids
+}
+
+func TestPager(t *testing.T) {
+	pager := soql.NewPager(soql.Select("Id", "Name").From("Lead").Where(soql.Eq{"Status": "Open"}).OrderBy("Name").Offset(10), 2)
+
+	// first page
+	query, err := pager.Query("")
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id, Name FROM Lead WHERE Status = 'Open' ORDER BY Id ASC LIMIT 3", query.MustSQL())
+
+	records := leadsWithIDs("a", "b", "c")
+	page, err := pager.Page("", &records)
+	require.Nil(t, err)
+	require.Equal(t, []string{"a", "b"}, ids(records))
+	require.Empty(t, page.Previous)
+	require.NotEmpty(t, page.Next)
+
+	// second page
+	query, err = pager.Query(page.Next)
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id, Name FROM Lead WHERE Status = 'Open' AND Id > 'b' ORDER BY Id ASC LIMIT 3", query.MustSQL())
+
+	records = leadsWithIDs("c", "d")
+	next := page.Next
+	page, err = pager.Page(next, &records)
+	require.Nil(t, err)
+	require.Equal(t, []string{"c", "d"}, ids(records))
+	require.NotEmpty(t, page.Previous)
+	require.Empty(t, page.Next)
+
+	// back to the first page, which is retrieved in descending order
+	query, err = pager.Query(page.Previous)
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id, Name FROM Lead WHERE Status = 'Open' AND Id < 'c' ORDER BY Id DESC LIMIT 3", query.MustSQL())
+
+	records = leadsWithIDs("b", "a")
+	previous := page.Previous
+	page, err = pager.Page(previous, &records)
+	require.Nil(t, err)
+	require.Equal(t, []string{"a", "b"}, ids(records))
+	require.Empty(t, page.Previous)
+	require.Equal(t, next, page.Next)
+}
+
+func TestPagerOrderKey(t *testing.T) {
+	pager := soql.NewPager(soql.Select("Id").From("Lead"), 1).OrderKey("CreatedDate")
+
+	records := []pagedLead{{ID: "a"}, {ID: "b"}}
+	records[0].CreatedDate.Value = records[0].CreatedDate.Value.AddDate(2020, 0, 0)
+	page, err := pager.Page("", &records)
+	require.Nil(t, err)
+	require.Len(t, records, 1)
+
+	query, err := pager.Query(page.Next)
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id FROM Lead WHERE CreatedDate > 2021-01-01T00:00:00.000Z ORDER BY CreatedDate ASC LIMIT 2", query.MustSQL())
+}
+
+func TestPagerDatetimeWithinSecond(t *testing.T) {
+	pager := soql.NewPager(soql.Select("Id").From("Lead"), 1).OrderKey("CreatedDate")
+	second := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	// two rows created within the same second
+	records := []pagedLead{
+		{ID: "a", CreatedDate: types.NewDatetime(second.Add(120 * time.Millisecond))},
+		{ID: "b", CreatedDate: types.NewDatetime(second.Add(480 * time.Millisecond))},
+	}
+	page, err := pager.Page("", &records)
+	require.Nil(t, err)
+
+	query, err := pager.Query(page.Next)
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id FROM Lead WHERE CreatedDate > 2021-03-04T05:06:07.120Z ORDER BY CreatedDate ASC LIMIT 2", query.MustSQL())
+
+	records = []pagedLead{{ID: "b", CreatedDate: types.NewDatetime(second.Add(480 * time.Millisecond))}}
+	page, err = pager.Page(page.Next, &records)
+	require.Nil(t, err)
+
+	query, err = pager.Query(page.Previous)
+	require.Nil(t, err)
+	require.Equal(t, "SELECT Id FROM Lead WHERE CreatedDate < 2021-03-04T05:06:07.480Z ORDER BY CreatedDate DESC LIMIT 2", query.MustSQL())
+}
+
+func TestPagerInvalidCursor(t *testing.T) {
+	pager := soql.NewPager(soql.Select("Id").From("Lead"), 10)
+
+	for description, token := range map[string]string{
+		"not base64":   "!!!",
+		"not json":     "bm90IGpzb24",
+		"other key":    "eyJrIjoiTmFtZSIsInYiOiJhIiwidCI6InMifQ",
+		"bad number":   "eyJrIjoiSWQiLCJ2IjoiMSBPUiBJZCAhPSBudWxsIiwidCI6Im4ifQ",
+		"unknown kind": "eyJrIjoiSWQiLCJ2IjoiYSIsInQiOiJ4In0",
+	} {
+		t.Run(description, func(t *testing.T) {
+			_, err := pager.Query(token)
+			require.True(t, errors.Is(err, soql.ErrInvalidCursor), err)
+		})
+	}
+}
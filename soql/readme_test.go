@@ -20,7 +20,7 @@ func TestReadMeExamples(t *testing.T) {
 		Where(soql.And{
 			soql.Eq{"FirstName": "Benjamin"},
 			// salesforce datetime's use a custom format which types.Datetime accomodates
-			soql.Gt{"CreatedDate": types.NewDatetime(time.Now().Add(time.Hour)).String()},
+			soql.Gt{"CreatedDate": types.NewDatetime(time.Now().Add(time.Hour))},
 		}).
 		Limit(1).
 		ToSQL()
@@ -19,12 +19,17 @@ type selectData struct {
 	Options      []string
 	Columns      []SQLizer
 	From         SQLizer
+	Scope        FilterScope
 	WhereParts   []SQLizer
-	GroupBys     []string
+	With         SQLizer
+	GroupBys     []SQLizer
 	HavingParts  []SQLizer
 	OrderByParts []SQLizer
 	Limit        string
 	Offset       string
+	For          string
+	UpdateStat   string
+	ForUpdate    bool
 	Suffixes     []SQLizer
 }
 
@@ -69,6 +74,10 @@ func (d *selectData) toSQL() (string, error) {
 		return "", fmt.Errorf("select statements must have at least one column")
 	}
 
+	if err := d.validate(); err != nil {
+		return "", err
+	}
+
 	sql := &bytes.Buffer{}
 
 	if len(d.Prefixes) > 0 {
@@ -101,6 +110,11 @@ func (d *selectData) toSQL() (string, error) {
 		}
 	}
 
+	if d.Scope != "" {
+		sql.WriteString(" USING SCOPE ")
+		sql.WriteString(string(d.Scope))
+	}
+
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
 		if err := appendToSQL(d.WhereParts, sql, " AND "); err != nil {
@@ -108,9 +122,18 @@ func (d *selectData) toSQL() (string, error) {
 		}
 	}
 
+	if d.With != nil {
+		sql.WriteString(" WITH ")
+		if err := appendToSQL([]SQLizer{d.With}, sql, ""); err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
+	}
+
 	if len(d.GroupBys) > 0 {
 		sql.WriteString(" GROUP BY ")
-		sql.WriteString(strings.Join(d.GroupBys, ", "))
+		if err := appendToSQL(d.GroupBys, sql, ", "); err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
 	}
 
 	if len(d.HavingParts) > 0 {
@@ -137,6 +160,20 @@ func (d *selectData) toSQL() (string, error) {
 		sql.WriteString(d.Offset)
 	}
 
+	if len(d.For) > 0 {
+		sql.WriteString(" FOR ")
+		sql.WriteString(d.For)
+	}
+
+	if len(d.UpdateStat) > 0 {
+		sql.WriteString(" UPDATE ")
+		sql.WriteString(d.UpdateStat)
+	}
+
+	if d.ForUpdate {
+		sql.WriteString(" FOR UPDATE")
+	}
+
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
 
@@ -192,6 +229,11 @@ func (b Builder) From(from string) Builder {
 	return builder.Set(b, "From", newBaseSQLizer(from)).(Builder)
 }
 
+// UsingScope limits the results of the query to the given filter scope i.e. soql.ScopeMine
+func (b Builder) UsingScope(scope FilterScope) Builder {
+	return builder.Set(b, "Scope", scope).(Builder)
+}
+
 // FromSelect sets a subquery into the FROM clause of the query.
 func (b Builder) FromSelect(from Builder, alias string) Builder {
 	// Prevent misnumbered parameters in nested selects (#183).
@@ -223,9 +265,57 @@ func (b Builder) Where(predicate interface{}) Builder {
 	return builder.Append(b, "WhereParts", newWhereSQLizer(predicate)).(Builder)
 }
 
+// WithSecurityEnforced enables field and object level security permission checks
+func (b Builder) WithSecurityEnforced() Builder {
+	return builder.Set(b, "With", Expr("SECURITY_ENFORCED")).(Builder)
+}
+
+// WithUserMode runs the query with the sharing rules and permissions of the current user
+func (b Builder) WithUserMode() Builder {
+	return builder.Set(b, "With", Expr("USER_MODE")).(Builder)
+}
+
+// WithSystemMode runs the query with the permissions of the system, the default
+func (b Builder) WithSystemMode() Builder {
+	return builder.Set(b, "With", Expr("SYSTEM_MODE")).(Builder)
+}
+
+// WithDataCategory filters knowledge articles and questions by data category. Multiple
+// filters are joined by AND.
+func (b Builder) WithDataCategory(filters ...DataCategory) Builder {
+	parts := make(conj, 0, len(filters))
+	for _, filter := range filters {
+		parts = append(parts, filter)
+	}
+
+	return builder.Set(b, "With", dataCategories(parts)).(Builder)
+}
+
+// GroupByClause appends a group by clause to selectData. The clause may be a string
+// or a SQLizer such as soql.CalendarYear("CreatedDate")
+func (b Builder) GroupByClause(pred interface{}) Builder {
+	return builder.Append(b, "GroupBys", newBaseSQLizer(pred)).(Builder)
+}
+
 // GroupBy appends group by claus(es) to selectData
 func (b Builder) GroupBy(groupBys ...string) Builder {
-	return builder.Extend(b, "GroupBys", groupBys).(Builder)
+	for _, groupBy := range groupBys {
+		b = b.GroupByClause(groupBy)
+	}
+
+	return b
+}
+
+// GroupByRollup sets a GROUP BY ROLLUP(fields...) clause which adds subtotals to aggregated
+// results. It can't be combined with other group by clauses.
+func (b Builder) GroupByRollup(fields ...string) Builder {
+	return builder.Append(builder.Delete(b, "GroupBys"), "GroupBys", groupingExpr{"ROLLUP", fields}).(Builder)
+}
+
+// GroupByCube sets a GROUP BY CUBE(fields...) clause which adds subtotals for every combination
+// of grouped fields. It can't be combined with other group by clauses.
+func (b Builder) GroupByCube(fields ...string) Builder {
+	return builder.Append(builder.Delete(b, "GroupBys"), "GroupBys", groupingExpr{"CUBE", fields}).(Builder)
 }
 
 // Having appends a having clause to selectData
@@ -267,6 +357,32 @@ func (b Builder) RemoveOffset() Builder {
 	return builder.Delete(b, "Offset").(Builder)
 }
 
+// ForView updates the LastViewedDate of the returned records
+func (b Builder) ForView() Builder {
+	return builder.Set(b, "For", "VIEW").(Builder)
+}
+
+// ForReference updates the LastReferencedDate of the returned records
+func (b Builder) ForReference() Builder {
+	return builder.Set(b, "For", "REFERENCE").(Builder)
+}
+
+// UpdateTracking tracks the keywords used to search Salesforce Knowledge articles
+func (b Builder) UpdateTracking() Builder {
+	return builder.Set(b, "UpdateStat", "TRACKING").(Builder)
+}
+
+// UpdateViewstat updates the view statistics of the returned Salesforce Knowledge articles
+func (b Builder) UpdateViewstat() Builder {
+	return builder.Set(b, "UpdateStat", "VIEWSTAT").(Builder)
+}
+
+// ForUpdate locks the returned records from being updated by another request. It can't be
+// combined with ORDER BY.
+func (b Builder) ForUpdate() Builder {
+	return builder.Set(b, "ForUpdate", true).(Builder)
+}
+
 // Suffix adds an expression to the end of the query
 func (b Builder) Suffix(sql string) Builder {
 	return builder.Append(b, "Suffixes", Expr(sql)).(Builder)
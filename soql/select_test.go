@@ -38,7 +38,7 @@ var selectBuilderTests = map[*selectInput]*selectOutput{
 		Where(soql.Eq{
 			"foo": "bar' AND zar = 'lar",
 		}),
-	}: {"SELECT a, b, c FROM Account WHERE foo = 'bar' AND zar = 'lar'", nil, "sql injection in where clause is escaped"},
+	}: {`SELECT a, b, c FROM Account WHERE foo = 'bar\' AND zar = \'lar'`, nil, "sql injection in where clause is escaped"},
 }
 
 func TestSelectBuilder(t *testing.T) {
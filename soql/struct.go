@@ -0,0 +1,283 @@
+package soql
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// struct.go derives the select list of a query from the fields of a struct, such as the types
+// generated by the codegen package, so that a query and the type its records are decoded into
+// stay in sync.
+
+const (
+	// MaxChildSubqueries is the number of child relationship subqueries Salesforce allows in a query
+	MaxChildSubqueries = 20
+	// MaxParentRelationships is the number of parent relationships Salesforce allows in a query
+	MaxParentRelationships = 55
+)
+
+var (
+	// ErrTooManyChildSubqueries is returned when a struct has more child relationships than
+	// MaxChildSubqueries
+	ErrTooManyChildSubqueries = fmt.Errorf("a query may contain no more than %d child subqueries", MaxChildSubqueries)
+	// ErrTooManyRelationships is returned when a struct selects more parent relationships than
+	// MaxParentRelationships
+	ErrTooManyRelationships = fmt.Errorf("a query may contain no more than %d parent relationships", MaxParentRelationships)
+)
+
+// StructOptions configures SelectStructWith
+type StructOptions struct {
+	// ParentDepth is the number of parent relationships followed from the object, i.e. a depth
+	// of 1 selects Owner.Name but not Owner.Manager.Name. It may not exceed MaxRelationshipDepth.
+	ParentDepth int
+	// Children selects child relationship fields as subqueries
+	Children bool
+}
+
+// DefaultStructOptions are used by SelectStruct. They select only the fields of the object itself,
+// as the relationships of a generated type exceed the limits of a single query.
+var DefaultStructOptions = StructOptions{ParentDepth: 0, Children: false}
+
+// SelectStruct returns a query selecting the fields of v, a struct or a slice of structs or a
+// pointer to either, from the object named by its type using DefaultStructOptions. Field names are taken from the soql
+// tag, the json tag, or the name of each field:
+//
+//	type Lead struct {
+//		ID       string `json:"Id"`
+//		Owner    *User  `json:"Owner"`
+//		Contacts struct {
+//			Records []*Contact `json:"records"`
+//		} `json:"Contacts"`
+//	}
+//
+//	soql.SelectStructWith(&Lead{}, soql.StructOptions{ParentDepth: 1, Children: true})
+//	=> SELECT Id, Owner.Id, Owner.Name, (SELECT Id, LastName FROM Contacts) FROM Lead
+//
+// Fields whose types implement json.Unmarshaler or encoding.TextUnmarshaler, such as
+// types.Datetime, are selected as values. Other struct fields are parent relationships, unless
+// they contain a records slice in which case they are child relationships. Fields tagged
+// `soql:"-"` or `json:"-"` are skipped. Use From to override the object name.
+//
+// The query fails to render with ErrTooManyChildSubqueries or ErrTooManyRelationships if the
+// options select more relationships than Salesforce allows.
+func SelectStruct(v interface{}) Builder {
+	return SelectStructWith(v, DefaultStructOptions)
+}
+
+// SelectStructWith is SelectStruct with the given options
+func SelectStructWith(v interface{}, options StructOptions) Builder {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return Select().Column(invalidColumn{fmt.Errorf("soql.SelectStruct: expected struct or slice of structs, not %T", v)})
+	}
+
+	if options.ParentDepth < 0 || options.ParentDepth > MaxRelationshipDepth {
+		return Select().Column(invalidColumn{fmt.Errorf("soql.SelectStruct: %d: %w", options.ParentDepth, ErrRelationshipDepth)})
+	}
+
+	limits := &structLimits{relationships: make(map[string]bool)}
+	b := structColumns(Select(), t, "", options, MaxChildQueryDepth, limits).From(t.Name())
+	if err := limits.err(); err != nil {
+		return Select().Column(invalidColumn{fmt.Errorf("soql.SelectStruct: %w", err)})
+	}
+
+	return b
+}
+
+// structLimits counts the relationships of a query derived from a struct
+type structLimits struct {
+	children      int
+	relationships map[string]bool
+}
+
+// add counts the parent relationships traversed by paths, which are relative to the child
+// relationship named child or to the object itself if child is empty
+func (l *structLimits) add(child string, paths []string) {
+	for _, path := range paths {
+		parts := strings.Split(path, ".")
+		for i := 1; i < len(parts); i++ {
+			l.relationships[child+"/"+strings.Join(parts[:i], ".")] = true
+		}
+	}
+}
+
+// err returns an error if the counted relationships exceed the limits of a query
+func (l *structLimits) err() error {
+	if l.children > MaxChildSubqueries {
+		return fmt.Errorf("%w: %d", ErrTooManyChildSubqueries, l.children)
+	}
+
+	if len(l.relationships) > MaxParentRelationships {
+		return fmt.Errorf("%w: %d", ErrTooManyRelationships, len(l.relationships))
+	}
+
+	return nil
+}
+
+// invalidColumn reports an error once the query is rendered
+type invalidColumn struct {
+	err error
+}
+
+// ToSQL ...
+func (c invalidColumn) ToSQL() (string, error) {
+	return "", c.err
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// structColumns adds the fields of t to b as columns and child subqueries, counting their
+// relationships in limits. child is the name of the child relationship of t, or empty for the
+// object itself.
+func structColumns(b Builder, t reflect.Type, child string, options StructOptions, childDepth int, limits *structLimits) Builder {
+	paths := structFields(t, "", options.ParentDepth)
+	limits.add(child, paths)
+	b = b.Columns(paths...)
+
+	if !options.Children || childDepth == 0 {
+		return b
+	}
+
+	for _, field := range exportedFields(t) {
+		name, ok := structFieldName(field)
+		if !ok {
+			continue
+		}
+
+		if records, ok := childRecords(field.Type); ok {
+			limits.children++
+			b = b.ChildQuery(structColumns(Select(), records, name, options, childDepth-1, limits).From(name))
+		}
+	}
+
+	return b
+}
+
+// structFields returns the paths of the value fields of t and of its parent relationships to
+// the given depth
+func structFields(t reflect.Type, prefix string, depth int) []string {
+	var paths []string
+	for _, field := range exportedFields(t) {
+		name, ok := structFieldName(field)
+		if !ok {
+			continue
+		}
+
+		ft := indirect(field.Type)
+		switch {
+		case isValueType(field.Type):
+			paths = append(paths, prefix+name)
+		case isChildRelationship(ft):
+			continue
+		case depth > 0:
+			paths = append(paths, structFields(ft, prefix+name+".", depth-1)...)
+		}
+	}
+
+	return paths
+}
+
+// exportedFields returns the exported fields of t, flattening embedded structs as encoding/json does
+func exportedFields(t reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && indirect(field.Type).Kind() == reflect.Struct {
+			fields = append(fields, exportedFields(indirect(field.Type))...)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// structFieldName returns the API name of field, or false if it is skipped
+func structFieldName(field reflect.StructField) (string, bool) {
+	name := field.Name
+	if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+		return "", false
+	} else if tag != "" {
+		name = tag
+	}
+
+	if tag := field.Tag.Get("soql"); tag == "-" {
+		return "", false
+	} else if tag != "" {
+		name = tag
+	}
+
+	// the attributes of a record are metadata rather than a field
+	if strings.EqualFold(name, "attributes") {
+		return "", false
+	}
+
+	return name, true
+}
+
+// isValueType reports whether t is selected as a single field rather than a relationship
+func isValueType(t reflect.Type) bool {
+	if t.Implements(jsonUnmarshaler) || t.Implements(textUnmarshaler) {
+		return true
+	}
+
+	if reflect.PtrTo(t).Implements(jsonUnmarshaler) || reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return true
+	}
+
+	t = indirect(t)
+	return t == timeType || t.Kind() != reflect.Struct
+}
+
+// isChildRelationship reports whether t is a query result containing records
+func isChildRelationship(t reflect.Type) bool {
+	_, ok := childRecords(t)
+	return ok
+}
+
+// childRecords returns the record type of a child relationship such as
+// struct { Records []*Contact `json:"records"` }
+func childRecords(t reflect.Type) (reflect.Type, bool) {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct || isValueType(t) {
+		return nil, false
+	}
+
+	for _, field := range exportedFields(t) {
+		name, _ := structFieldName(field)
+		if !strings.EqualFold(name, "records") || field.Type.Kind() != reflect.Slice {
+			continue
+		}
+
+		if records := indirect(field.Type.Elem()); records.Kind() == reflect.Struct {
+			return records, true
+		}
+	}
+
+	return nil, false
+}
+
+// indirect dereferences pointer types
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
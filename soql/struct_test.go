@@ -0,0 +1,128 @@
+package soql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/examples/leads"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/beeekind/go-salesforce-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type structUser struct {
+	Attributes types.Attributes     `json:"attributes"`
+	ID         string               `json:"Id"`
+	Name       types.NullableString `json:"Name"`
+	Manager    *structUser          `json:"Manager"`
+}
+
+type structContact struct {
+	ID       string      `json:"Id"`
+	LastName string      `json:"LastName"`
+	Owner    *structUser `json:"Owner"`
+}
+
+type structBase struct {
+	ID string `json:"Id"`
+}
+
+type Account struct {
+	structBase
+	Name        string              `json:"Name"`
+	Colors      types.MultiPicklist `soql:"Colors__c"`
+	CreatedDate types.Datetime      `json:"CreatedDate"`
+	Address     types.Address       `json:"BillingAddress"`
+	Owner       *structUser         `json:"Owner"`
+	Ignored     string              `json:"-"`
+	Transient   string              `json:"Transient" soql:"-"`
+	Contacts    struct {
+		Done    bool             `json:"done"`
+		Records []*structContact `json:"records"`
+	} `json:"Contacts"`
+	unexported string
+}
+
+func TestSelectStruct(t *testing.T) {
+	tests := map[string]struct {
+		query    soql.Builder
+		expected string
+	}{
+		"default options": {
+			soql.SelectStruct(&Account{}),
+			"SELECT Id, Name, Colors__c, CreatedDate, BillingAddress FROM Account",
+		},
+		"parents and children": {
+			soql.SelectStructWith(&Account{}, soql.StructOptions{ParentDepth: 1, Children: true}),
+			"SELECT Id, Name, Colors__c, CreatedDate, BillingAddress, Owner.Id, Owner.Name, (SELECT Id, LastName, Owner.Id, Owner.Name FROM Contacts) FROM Account",
+		},
+		"deeper parents without children": {
+			soql.SelectStructWith([]Account{}, soql.StructOptions{ParentDepth: 2}),
+			"SELECT Id, Name, Colors__c, CreatedDate, BillingAddress, Owner.Id, Owner.Name, Owner.Manager.Id, Owner.Manager.Name FROM Account",
+		},
+		"no parents": {
+			soql.SelectStructWith(&[]*structContact{}, soql.StructOptions{}).From("Contact").Where(soql.Eq{"LastName": "Smith"}),
+			"SELECT Id, LastName FROM Contact WHERE LastName = 'Smith'",
+		},
+	}
+
+	for description, tc := range tests {
+		t.Run(description, func(t *testing.T) {
+			sql, err := tc.query.ToSQL()
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, sql)
+		})
+	}
+}
+
+func TestSelectStructGenerated(t *testing.T) {
+	sql, err := soql.SelectStructWith(&leads.Lead{}, soql.StructOptions{}).ToSQL()
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(sql, "SELECT "))
+	require.True(t, strings.HasSuffix(sql, " FROM Lead"))
+	require.Contains(t, sql, "CreatedById, CreatedDate")
+	require.NotContains(t, sql, "CreatedBy.")
+
+	sql, err = soql.SelectStruct(&leads.Lead{}).ToSQL()
+	require.Nil(t, err)
+	require.NotContains(t, sql, "CreatedBy.")
+	require.NotContains(t, sql, "(SELECT")
+
+	sql, err = soql.SelectStructWith(&leads.Lead{}, soql.StructOptions{ParentDepth: 1}).ToSQL()
+	require.Nil(t, err)
+	require.Contains(t, sql, "CreatedBy.Username")
+
+	_, err = soql.SelectStructWith(&leads.Lead{}, soql.StructOptions{Children: true}).ToSQL()
+	require.ErrorIs(t, err, soql.ErrTooManyChildSubqueries)
+}
+
+func TestSelectStructErrors(t *testing.T) {
+	_, err := soql.SelectStruct("Lead").ToSQL()
+	require.NotNil(t, err)
+
+	_, err = soql.SelectStructWith(&Account{}, soql.StructOptions{ParentDepth: soql.MaxRelationshipDepth + 1}).ToSQL()
+	require.NotNil(t, err)
+
+	// each level of Owner.Manager counts once towards MaxParentRelationships
+	_, err = soql.SelectStructWith(&Account{}, soql.StructOptions{ParentDepth: soql.MaxRelationshipDepth}).ToSQL()
+	require.Nil(t, err)
+}
+
+type wideAccount struct {
+	ID string `json:"Id"`
+	// 56 distinct parent relationships
+	U00, U01, U02, U03, U04, U05, U06, U07, U08, U09 *structUser
+	U10, U11, U12, U13, U14, U15, U16, U17, U18, U19 *structUser
+	U20, U21, U22, U23, U24, U25, U26, U27, U28, U29 *structUser
+	U30, U31, U32, U33, U34, U35, U36, U37, U38, U39 *structUser
+	U40, U41, U42, U43, U44, U45, U46, U47, U48, U49 *structUser
+	U50, U51, U52, U53, U54, U55                     *structUser
+}
+
+func TestSelectStructRelationshipLimit(t *testing.T) {
+	_, err := soql.SelectStructWith(&wideAccount{}, soql.StructOptions{ParentDepth: 1}).ToSQL()
+	require.ErrorIs(t, err, soql.ErrTooManyRelationships)
+
+	_, err = soql.SelectStruct(&wideAccount{}).ToSQL()
+	require.Nil(t, err)
+}
@@ -0,0 +1,241 @@
+package soql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lann/builder"
+)
+
+// subquery.go implements parent-child relationship subqueries within a select list and
+// semi-join/anti-join subqueries within a WHERE clause, along with the restrictions Salesforce
+// places upon them.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_relationships_query_using.htm
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_soql_select_comparisonoperators.htm
+
+// MaxChildQueryDepth is the number of parent-child subqueries that may be nested within one
+// another. Salesforce allows a single level of nesting prior to API version 58.0 and five levels
+// afterwards, raise this value if your client targets a later API version.
+var MaxChildQueryDepth = 1
+
+// MaxSemiJoins is the number of semi-join or anti-join subqueries allowed in a single WHERE clause
+const MaxSemiJoins = 2
+
+var (
+	// ErrChildQueryNoFrom is returned when a child subquery has no relationship name in its FROM clause
+	ErrChildQueryNoFrom = errors.New("child subquery must select FROM a child relationship name")
+	// ErrChildQueryDepth is returned when child subqueries are nested deeper than MaxChildQueryDepth
+	ErrChildQueryDepth = errors.New("child subqueries are nested too deeply")
+	// ErrSemiJoinColumns is returned when a semi-join subquery does not select exactly one column
+	ErrSemiJoinColumns = errors.New("semi-join subquery must select exactly one ID or reference field")
+	// ErrSemiJoinClause is returned when a semi-join subquery uses ORDER BY, LIMIT, or OFFSET
+	ErrSemiJoinClause = errors.New("semi-join subquery cannot use ORDER BY, LIMIT, or OFFSET")
+	// ErrSemiJoinNested is returned when a semi-join subquery contains another semi-join or a child subquery
+	ErrSemiJoinNested = errors.New("semi-join subquery cannot contain another semi-join or a child subquery")
+	// ErrSemiJoinOr is returned when a semi-join is used within an OR clause
+	ErrSemiJoinOr = errors.New("semi-join subquery cannot be used within an OR clause")
+	// ErrSemiJoinNot is returned when a semi-join is negated by Not, use NotEq to form an anti-join instead
+	ErrSemiJoinNot = errors.New("semi-join subquery cannot be used within a NOT clause, use NotEq instead")
+	// ErrSemiJoinCount is returned when a WHERE clause contains more than MaxSemiJoins semi-joins
+	ErrSemiJoinCount = fmt.Errorf("a WHERE clause may contain no more than %d semi-join subqueries", MaxSemiJoins)
+)
+
+// childQuery is a parent-child relationship subquery used as a column
+type childQuery struct {
+	query Builder
+}
+
+// ToSQL ...
+func (c childQuery) ToSQL() (string, error) {
+	sql, err := c.query.ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return fmt.Sprintf("(%s)", sql), nil
+}
+
+// ChildQuery adds a parent-child relationship subquery to the select list. The FROM clause of
+// query is the child relationship name, i.e. Contacts for Account:
+//
+// soql.Select("Id").ChildQuery(soql.Select("Id").From("Contacts")).From("Account")
+// => SELECT Id, (SELECT Id FROM Contacts) FROM Account
+func (b Builder) ChildQuery(query Builder) Builder {
+	return builder.Append(b, "Columns", childQuery{query}).(Builder)
+}
+
+// semiJoin is an "IN (SELECT ...)" or "NOT IN (SELECT ...)" predicate. It is produced by Eq and
+// NotEq when a value is a Builder.
+type semiJoin struct {
+	field string
+	query Builder
+	not   bool
+}
+
+// ToSQL ...
+func (s semiJoin) ToSQL() (string, error) {
+	if err := validateSemiJoin(s.query); err != nil {
+		return "", fmt.Errorf("%s: %w", s.field, err)
+	}
+
+	sql, err := s.query.ToSQL()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	opr := "IN"
+	if s.not {
+		opr = "NOT IN"
+	}
+
+	return fmt.Sprintf("%s %s (%s)", s.field, opr, sql), nil
+}
+
+// validate enforces the nesting restrictions of subqueries relative to this query
+func (d *selectData) validate() error {
+	if _, err := childQueryDepth(d); err != nil {
+		return err
+	}
+
+	var count int
+	for _, part := range d.WhereParts {
+		n, err := countSemiJoins(part, false)
+		if err != nil {
+			return err
+		}
+		count += n
+	}
+
+	if count > MaxSemiJoins {
+		return ErrSemiJoinCount
+	}
+
+	return d.validateClauses()
+}
+
+// childQueryDepth returns the deepest level of child subqueries within d
+func childQueryDepth(d *selectData) (int, error) {
+	var depth int
+	for _, column := range d.Columns {
+		child, ok := column.(childQuery)
+		if !ok {
+			continue
+		}
+
+		data := builder.GetStruct(child.query).(selectData)
+		if data.From == nil {
+			return 0, ErrChildQueryNoFrom
+		}
+
+		childDepth, err := childQueryDepth(&data)
+		if err != nil {
+			return 0, err
+		}
+
+		if childDepth+1 > depth {
+			depth = childDepth + 1
+		}
+	}
+
+	if depth > MaxChildQueryDepth {
+		return 0, fmt.Errorf("%w: %d > %d", ErrChildQueryDepth, depth, MaxChildQueryDepth)
+	}
+
+	return depth, nil
+}
+
+// validateSemiJoin enforces the restrictions of the subquery within a semi-join or anti-join
+func validateSemiJoin(query Builder) error {
+	data := builder.GetStruct(query).(selectData)
+
+	if len(data.Columns) != 1 {
+		return ErrSemiJoinColumns
+	}
+
+	if _, ok := data.Columns[0].(childQuery); ok {
+		return ErrSemiJoinNested
+	}
+
+	if len(data.OrderByParts) > 0 || data.Limit != "" || data.Offset != "" {
+		return ErrSemiJoinClause
+	}
+
+	for _, part := range data.WhereParts {
+		n, err := countSemiJoins(part, false)
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			return ErrSemiJoinNested
+		}
+	}
+
+	return nil
+}
+
+// countSemiJoins walks the expressions of this package and counts the semi-joins found within.
+// Expressions it doesn't recognize, such as raw strings, are not inspected.
+func countSemiJoins(expr interface{}, withinOr bool) (count int, err error) {
+	switch e := expr.(type) {
+	case *whereSQLizer:
+		return countSemiJoins(e.predicate, withinOr)
+	case baseSQLizer:
+		return countSemiJoins(e.predicate, withinOr)
+	case map[string]interface{}:
+		return countSemiJoins(Eq(e), withinOr)
+	case Eq:
+		return countMapSemiJoins(e, withinOr)
+	case NotEq:
+		return countMapSemiJoins(e, withinOr)
+	case semiJoin:
+		if withinOr {
+			return 0, ErrSemiJoinOr
+		}
+		return 1, nil
+	case And:
+		return countConjSemiJoins(conj(e), withinOr)
+	case Or:
+		return countConjSemiJoins(conj(e), true)
+	case Not:
+		n, err := countConjSemiJoins(conj(e), withinOr)
+		if err != nil {
+			return 0, err
+		}
+
+		if n > 0 {
+			return 0, ErrSemiJoinNot
+		}
+	}
+
+	return 0, nil
+}
+
+func countMapSemiJoins(exp map[string]interface{}, withinOr bool) (count int, err error) {
+	for _, v := range exp {
+		if _, ok := v.(Builder); !ok {
+			continue
+		}
+
+		if withinOr {
+			return 0, ErrSemiJoinOr
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+func countConjSemiJoins(c conj, withinOr bool) (count int, err error) {
+	for _, part := range c {
+		n, err := countSemiJoins(part, withinOr)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, nil
+}
@@ -0,0 +1,81 @@
+package soql_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+var subquerySelectTests = map[*selectInput]*selectOutput{
+	{soql.
+		Select("Id", "Name").
+		ChildQuery(soql.Select("Id", "LastName").From("Contacts").Where(soql.Eq{"IsDeleted": false}).Limit(5)).
+		From("Account"),
+	}: {"SELECT Id, Name, (SELECT Id, LastName FROM Contacts WHERE IsDeleted = false LIMIT 5) FROM Account", nil, "child relationship subquery"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Where(soql.Eq{"Id": soql.Select("AccountId").From("Opportunity").Where(soql.Eq{"StageName": "Closed Won"})}),
+	}: {"SELECT Id FROM Account WHERE Id IN (SELECT AccountId FROM Opportunity WHERE StageName = 'Closed Won')", nil, "semi-join"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Where(soql.And{
+			soql.NotEq{"Id": soql.Select("AccountId").From("Contact")},
+			soql.Eq{"OwnerId": soql.Select("Id").From("User").Where(soql.Eq{"IsActive": true})},
+		}),
+	}: {"SELECT Id FROM Account WHERE (Id NOT IN (SELECT AccountId FROM Contact) AND OwnerId IN (SELECT Id FROM User WHERE IsActive = true))", nil, "anti-join and semi-join"},
+	{soql.
+		Select("Id").
+		ChildQuery(soql.Select("Id").ChildQuery(soql.Select("Id").From("Notes")).From("Contacts")).
+		From("Account"),
+	}: {"", soql.ErrChildQueryDepth, "child subqueries nested too deeply"},
+	{soql.
+		Select("Id").
+		ChildQuery(soql.Select("Id")).
+		From("Account"),
+	}: {"", soql.ErrChildQueryNoFrom, "child subquery without relationship"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Where(soql.Eq{"Id": soql.Select("Id", "AccountId").From("Contact")}),
+	}: {"", soql.ErrSemiJoinColumns, "semi-join with multiple columns"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Where(soql.Eq{"Id": soql.Select("AccountId").From("Contact").Limit(10)}),
+	}: {"", soql.ErrSemiJoinClause, "semi-join with limit"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Where(soql.Eq{"Id": soql.Select("AccountId").From("Contact").Where(soql.Eq{"Id": soql.Select("WhoId").From("Task")})}),
+	}: {"", soql.ErrSemiJoinNested, "nested semi-join"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Where(soql.Or{soql.Eq{"Name": "a"}, soql.Eq{"Id": soql.Select("AccountId").From("Contact")}}),
+	}: {"", soql.ErrSemiJoinOr, "semi-join within or"},
+	{soql.
+		Select("Id").
+		From("Account").
+		Where(soql.Eq{"Id": soql.Select("AccountId").From("Contact")}).
+		Where(soql.Eq{"OwnerId": soql.Select("Id").From("User")}).
+		Where(soql.NotEq{"Id": soql.Select("AccountId").From("Case")}),
+	}: {"", soql.ErrSemiJoinCount, "too many semi-joins"},
+}
+
+func TestSubqueries(t *testing.T) {
+	for in, out := range subquerySelectTests {
+		t.Run(out.description, func(t *testing.T) {
+			sql, err := in.builder.ToSQL()
+			if out.expectedErr != nil {
+				require.True(t, errors.Is(err, out.expectedErr), "expected %v, got %v", out.expectedErr, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, out.expectedSQL, sql)
+		})
+	}
+}
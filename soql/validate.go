@@ -0,0 +1,581 @@
+package soql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/metadata"
+	"github.com/lann/builder"
+)
+
+// validate.go checks the fields, relationships, and operators of a query against the describe
+// metadata of the objects it references so that mistakes surface before the query is sent
+// rather than as an INVALID_FIELD error from Salesforce.
+//
+// Only the structured components of a query are inspected. Raw SOQL given as a string, such as
+// soql.String or a Where clause written by hand, is checked on a best effort basis: plain field
+// paths and simple function calls are validated and everything else is skipped.
+
+// MaxRelationshipDepth is the number of parent relationships a field path may traverse
+const MaxRelationshipDepth = 5
+
+var (
+	// ErrUnknownObject is returned when the schema has no describe for an object
+	ErrUnknownObject = errors.New("unknown object")
+	// ErrUnknownField is returned when an object has no field of the given name
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnknownRelationship is returned when an object has no parent relationship of the given name
+	ErrUnknownRelationship = errors.New("unknown relationship")
+	// ErrUnknownChildRelationship is returned when an object has no child relationship of the given name
+	ErrUnknownChildRelationship = errors.New("unknown child relationship")
+	// ErrRelationshipDepth is returned when a field path traverses more than MaxRelationshipDepth relationships
+	ErrRelationshipDepth = fmt.Errorf("field paths may traverse no more than %d relationships", MaxRelationshipDepth)
+	// ErrNotFilterable is returned when a field which isn't filterable is used in a WHERE clause
+	ErrNotFilterable = errors.New("field is not filterable")
+	// ErrNotSortable is returned when a field which isn't sortable is used in an ORDER BY clause
+	ErrNotSortable = errors.New("field is not sortable")
+	// ErrNotGroupable is returned when a field which isn't groupable is used in a GROUP BY clause
+	ErrNotGroupable = errors.New("field is not groupable")
+	// ErrNotAggregatable is returned when a field which isn't aggregatable is used in an aggregate function
+	ErrNotAggregatable = errors.New("field is not aggregatable")
+	// ErrInvalidOperator is returned when an operator doesn't support the type of a field, such as LIKE on a number
+	ErrInvalidOperator = errors.New("operator is not supported by the field type")
+)
+
+// Schema provides the describe metadata of an object, typically from a cache
+type Schema interface {
+	Describe(objectName string) (*metadata.Describe, error)
+}
+
+// DescribeMap is a Schema of previously retrieved describe results keyed by object name
+type DescribeMap map[string]*metadata.Describe
+
+// Describe returns the describe of objectName, matched case insensitively
+func (m DescribeMap) Describe(objectName string) (*metadata.Describe, error) {
+	if describe, ok := m[objectName]; ok {
+		return describe, nil
+	}
+
+	for name, describe := range m {
+		if strings.EqualFold(name, objectName) {
+			return describe, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", objectName, ErrUnknownObject)
+}
+
+// ValidationError is a single problem found by Validate
+type ValidationError struct {
+	// Clause is the part of the query containing the problem i.e. SELECT, WHERE, or ORDER BY
+	Clause string
+	// Object is the name of the object the Field was resolved against
+	Object string
+	// Field is the field path, relationship name, or expression containing the problem
+	Field string
+	// Err is one of the Err* values of this package
+	Err error
+}
+
+// Error formats e as "<Clause> <Object>.<Field>: <Err>", omitting the parts which are empty
+func (e *ValidationError) Error() string {
+	var location []string
+	for _, part := range []string{e.Object, e.Field} {
+		if part != "" {
+			location = append(location, part)
+		}
+	}
+
+	prefix := strings.TrimSpace(e.Clause + " " + strings.Join(location, "."))
+	if prefix == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", prefix, e.Err)
+}
+
+// Unwrap allows errors.Is(err, soql.ErrUnknownField)
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is every problem found by Validate in the order they appear in the query
+type ValidationErrors []*ValidationError
+
+// Error ...
+func (e ValidationErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, err := range e {
+		parts = append(parts, err.Error())
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// Validate checks every field, relationship path, child relationship, and operator of query
+// against the describe metadata provided by schema. Every problem found is returned as a
+// ValidationErrors:
+//
+// err := soql.Validate(soql.Select("Id", "Owner.Nme").From("Lead"), soql.DescribeMap{"Lead": lead, "User": user})
+// => SELECT Lead.Owner.Nme: unknown field
+func Validate(query Builder, schema Schema) error {
+	data := builder.GetStruct(query).(selectData)
+	v := &validator{schema: schema}
+	v.query(&data, nil)
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+// validator accumulates the problems found while walking a query
+type validator struct {
+	schema Schema
+	errs   ValidationErrors
+}
+
+// add records a problem
+func (v *validator) add(clause string, object string, field string, err error) {
+	v.errs = append(v.errs, &ValidationError{Clause: clause, Object: object, Field: field, Err: err})
+}
+
+// query validates the components of d. The object of d is looked up via the schema, or for a
+// child subquery via the child relationships of parent.
+func (v *validator) query(d *selectData, parent *metadata.Describe) {
+	from, ok := rawString(d.From)
+	if !ok {
+		v.add("FROM", "", "", errors.New("query must select FROM an object name to be validated"))
+		return
+	}
+
+	var object *metadata.Describe
+	if parent == nil {
+		describe, err := v.schema.Describe(from)
+		if err != nil {
+			v.add("FROM", from, "", err)
+			return
+		}
+		object = describe
+	} else {
+		describe, err := v.childObject(parent, from)
+		if err != nil {
+			v.add("SELECT", parent.Name, from, err)
+			return
+		}
+		object = describe
+	}
+
+	for _, column := range d.Columns {
+		v.column(object, column)
+	}
+
+	for _, part := range d.WhereParts {
+		v.condition("WHERE", object, part)
+	}
+
+	for _, groupBy := range d.GroupBys {
+		v.groupBy(object, groupBy)
+	}
+
+	for _, part := range d.HavingParts {
+		v.condition("HAVING", object, part)
+	}
+
+	for _, orderBy := range d.OrderByParts {
+		v.orderBy(object, orderBy)
+	}
+}
+
+// childObject resolves the object of a child relationship subquery
+func (v *validator) childObject(parent *metadata.Describe, relationshipName string) (*metadata.Describe, error) {
+	for _, child := range parent.ChildRelationships {
+		if strings.EqualFold(child.RelationshipName, relationshipName) {
+			return v.schema.Describe(child.ChildSObject)
+		}
+	}
+
+	return nil, ErrUnknownChildRelationship
+}
+
+// column validates a single column of the select list
+func (v *validator) column(object *metadata.Describe, column SQLizer) {
+	switch c := unwrap(column).(type) {
+	case string:
+		for _, term := range strings.Split(c, ",") {
+			if _, ok := fieldsColumn(term); !ok {
+				v.rawTerm("SELECT", object, strings.TrimSpace(term), nil)
+			}
+		}
+	case childQuery:
+		data := builder.GetStruct(c.query).(selectData)
+		v.query(&data, object)
+	case Aggregate:
+		v.aggregate("SELECT", object, c)
+	case DateFunction:
+		v.dateFunction("SELECT", object, c, nil)
+	case TypeOfExpr:
+		v.typeOf(object, c)
+	}
+}
+
+// condition validates the expressions of a WHERE or HAVING clause
+func (v *validator) condition(clause string, object *metadata.Describe, expr interface{}) {
+	check := filterable
+	if clause == "HAVING" {
+		check = groupable
+	}
+
+	switch e := unwrap(expr).(type) {
+	case string:
+		// only a condition consisting of a single field path is inspected
+		v.rawTerm(clause, object, strings.TrimSpace(e), check)
+	case map[string]interface{}:
+		v.condition(clause, object, Eq(e))
+	case Eq:
+		v.comparisons(clause, object, e, check)
+	case NotEq:
+		v.comparisons(clause, object, e, check)
+	case Lt:
+		v.fields(clause, object, getSortedKeys(e), check)
+	case LtOrEq:
+		v.fields(clause, object, getSortedKeys(e), check)
+	case Gt:
+		v.fields(clause, object, getSortedKeys(e), check)
+	case GtOrEq:
+		v.fields(clause, object, getSortedKeys(e), check)
+	case Like:
+		v.fields(clause, object, getSortedStringKeys(e), check, likeable)
+	case NotLike:
+		v.fields(clause, object, getSortedStringKeys(e), check, likeable)
+	case Includes:
+		v.fields(clause, object, getSortedKeys(e), check, multiPicklist)
+	case Excludes:
+		v.fields(clause, object, getSortedKeys(e), check, multiPicklist)
+	case semiJoin:
+		v.fields(clause, object, []string{e.field}, check)
+		v.subquery(e.query)
+	case comparison:
+		v.condition(clause, object, e.left)
+	case Aggregate:
+		v.aggregate(clause, object, e)
+	case DateFunction:
+		v.dateFunction(clause, object, e, check)
+	case And:
+		for _, part := range e {
+			v.condition(clause, object, part)
+		}
+	case Or:
+		for _, part := range e {
+			v.condition(clause, object, part)
+		}
+	case Not:
+		for _, part := range e {
+			v.condition(clause, object, part)
+		}
+	}
+}
+
+// comparisons validates the keys of an Eq or NotEq, along with any semi-join values
+func (v *validator) comparisons(clause string, object *metadata.Describe, exp map[string]interface{}, check fieldCheck) {
+	for _, key := range getSortedKeys(exp) {
+		v.fields(clause, object, []string{key}, check)
+		if query, ok := exp[key].(Builder); ok {
+			v.subquery(query)
+		}
+	}
+}
+
+// subquery validates a semi-join subquery which selects from its own object
+func (v *validator) subquery(query Builder) {
+	data := builder.GetStruct(query).(selectData)
+	v.query(&data, nil)
+}
+
+// groupBy validates a single GROUP BY clause
+func (v *validator) groupBy(object *metadata.Describe, groupBy SQLizer) {
+	switch g := unwrap(groupBy).(type) {
+	case string:
+		v.rawTerm("GROUP BY", object, strings.TrimSpace(g), groupable)
+	case groupingExpr:
+		v.fields("GROUP BY", object, g.fields, groupable)
+	case DateFunction:
+		v.dateFunction("GROUP BY", object, g, groupable)
+	}
+}
+
+// orderBy validates a single ORDER BY clause such as "Name DESC NULLS LAST"
+func (v *validator) orderBy(object *metadata.Describe, orderBy SQLizer) {
+	switch o := unwrap(orderBy).(type) {
+	case string:
+		for _, term := range strings.Split(o, ",") {
+			if fields := strings.Fields(term); len(fields) > 0 {
+				v.rawTerm("ORDER BY", object, fields[0], sortable)
+			}
+		}
+	case OrderExpr:
+		v.orderBy(object, o.field)
+	case Aggregate:
+		v.aggregate("ORDER BY", object, o)
+	case DateFunction:
+		v.dateFunction("ORDER BY", object, o, sortable)
+	}
+}
+
+// aggregate validates the field of an aggregate function
+func (v *validator) aggregate(clause string, object *metadata.Describe, a Aggregate) {
+	if nested, ok := unwrap(a.field).(Aggregate); ok {
+		v.aggregate(clause, object, nested)
+		return
+	}
+
+	path, ok := rawString(a.field)
+	if !ok || path == "" {
+		return
+	}
+
+	var check fieldCheck
+	switch a.name {
+	case "COUNT", "COUNT_DISTINCT", "SUM", "AVG", "MIN", "MAX":
+		check = aggregatable
+	case "GROUPING":
+		check = groupable
+	}
+
+	v.fields(clause, object, []string{path}, check)
+}
+
+// dateFunction validates the field of a date function, which may itself be convertTimezone
+func (v *validator) dateFunction(clause string, object *metadata.Describe, f DateFunction, check fieldCheck) {
+	if nested, ok := unwrap(f.field).(DateFunction); ok {
+		v.dateFunction(clause, object, nested, check)
+		return
+	}
+
+	if path, ok := rawString(f.field); ok {
+		v.fields(clause, object, []string{path}, check, dateType)
+	}
+}
+
+// typeOf validates the polymorphic relationship of a TYPEOF expression and the fields selected
+// for each object type. The fields of its ELSE clause can't be attributed to an object and
+// aren't inspected.
+func (v *validator) typeOf(object *metadata.Describe, t TypeOfExpr) {
+	var relationship *metadata.Field
+	for _, field := range object.Fields {
+		if strings.EqualFold(field.RelationshipName, t.field) {
+			relationship = field
+			break
+		}
+	}
+
+	if relationship == nil {
+		v.add("SELECT", object.Name, t.field, ErrUnknownRelationship)
+		return
+	}
+
+	for _, when := range t.whens {
+		if !containsFold(relationship.ReferenceTo, when.objectType) {
+			v.add("SELECT", object.Name, t.field+" WHEN "+when.objectType, ErrUnknownObject)
+			continue
+		}
+
+		describe, err := v.schema.Describe(when.objectType)
+		if err != nil {
+			v.add("SELECT", when.objectType, "", err)
+			continue
+		}
+
+		v.fields("SELECT", describe, when.fields, nil)
+	}
+}
+
+// identifierPath matches a field path such as Name or Owner.Profile.Name
+var identifierPath = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)*$`)
+
+// functionCall matches a function call with an optional alias such as COUNT(Id) cnt
+var functionCall = regexp.MustCompile(`^([A-Za-z_]+)\(\s*([A-Za-z0-9_.]*)\s*\)(\s+[A-Za-z][A-Za-z0-9_]*)?$`)
+
+// rawTerm validates a term given as raw SOQL when it is a field path or simple function call
+func (v *validator) rawTerm(clause string, object *metadata.Describe, term string, check fieldCheck) {
+	if identifierPath.MatchString(term) {
+		v.fields(clause, object, []string{term}, check)
+		return
+	}
+
+	match := functionCall.FindStringSubmatch(term)
+	if match == nil || match[2] == "" {
+		return
+	}
+
+	name := strings.ToUpper(match[1])
+	switch name {
+	case "COUNT", "COUNT_DISTINCT", "SUM", "AVG", "MIN", "MAX", "GROUPING", "FORMAT", "CONVERTCURRENCY":
+		v.aggregate(clause, object, newAggregate(name, match[2]))
+	case "CALENDAR_MONTH", "CALENDAR_QUARTER", "CALENDAR_YEAR", "DAY_IN_MONTH", "DAY_IN_WEEK",
+		"DAY_IN_YEAR", "DAY_ONLY", "FISCAL_MONTH", "FISCAL_QUARTER", "FISCAL_YEAR", "HOUR_IN_DAY",
+		"WEEK_IN_MONTH", "WEEK_IN_YEAR", "CONVERTTIMEZONE":
+		v.dateFunction(clause, object, newDateFunction(name, match[2]), check)
+	default:
+		// functions such as toLabel() accept any field
+		v.fields(clause, object, []string{match[2]}, nil)
+	}
+}
+
+// fields resolves each path against object and applies the given checks to the resolved field
+func (v *validator) fields(clause string, object *metadata.Describe, paths []string, checks ...fieldCheck) {
+	for _, path := range paths {
+		field, owner, err := v.resolve(object, path)
+		if err != nil {
+			v.add(clause, object.Name, path, err)
+			continue
+		}
+
+		for _, check := range checks {
+			if check == nil {
+				continue
+			}
+
+			if err := check(field); err != nil {
+				v.add(clause, owner.Name, field.Name, err)
+			}
+		}
+	}
+}
+
+// resolve follows the parent relationships of path, such as Owner.Profile.Name, and returns the
+// final field along with the object it belongs to. A polymorphic relationship resolves against
+// the first of its referenced objects containing the remainder of the path.
+func (v *validator) resolve(object *metadata.Describe, path string) (*metadata.Field, *metadata.Describe, error) {
+	parts := strings.Split(path, ".")
+	if len(parts)-1 > MaxRelationshipDepth {
+		return nil, nil, ErrRelationshipDepth
+	}
+
+	if len(parts) == 1 {
+		for _, field := range object.Fields {
+			if strings.EqualFold(field.Name, path) {
+				return field, object, nil
+			}
+		}
+
+		return nil, nil, ErrUnknownField
+	}
+
+	var relationship *metadata.Field
+	for _, field := range object.Fields {
+		if field.RelationshipName != "" && strings.EqualFold(field.RelationshipName, parts[0]) {
+			relationship = field
+			break
+		}
+	}
+
+	if relationship == nil || len(relationship.ReferenceTo) == 0 {
+		return nil, nil, fmt.Errorf("%s: %w", parts[0], ErrUnknownRelationship)
+	}
+
+	rest := strings.Join(parts[1:], ".")
+	var firstErr error
+	for _, objectName := range relationship.ReferenceTo {
+		parent, err := v.schema.Describe(objectName)
+		if err == nil {
+			var field *metadata.Field
+			field, parent, err = v.resolve(parent, rest)
+			if err == nil {
+				return field, parent, nil
+			}
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return nil, nil, firstErr
+}
+
+// fieldCheck reports whether a resolved field may be used in a particular position
+type fieldCheck func(field *metadata.Field) error
+
+func filterable(field *metadata.Field) error {
+	if !field.Filterable {
+		return ErrNotFilterable
+	}
+	return nil
+}
+
+func sortable(field *metadata.Field) error {
+	if !field.Sortable {
+		return ErrNotSortable
+	}
+	return nil
+}
+
+func groupable(field *metadata.Field) error {
+	if !field.Groupable {
+		return ErrNotGroupable
+	}
+	return nil
+}
+
+func aggregatable(field *metadata.Field) error {
+	if !field.Aggregatable {
+		return ErrNotAggregatable
+	}
+	return nil
+}
+
+// likeable reports whether LIKE may be used with field
+func likeable(field *metadata.Field) error {
+	switch field.Type {
+	case "string", "textarea", "picklist", "email", "phone", "url", "combobox", "encryptedstring":
+		return nil
+	}
+	return fmt.Errorf("LIKE on %s: %w", field.Type, ErrInvalidOperator)
+}
+
+// multiPicklist reports whether INCLUDES and EXCLUDES may be used with field
+func multiPicklist(field *metadata.Field) error {
+	if field.Type != "multipicklist" {
+		return fmt.Errorf("INCLUDES/EXCLUDES on %s: %w", field.Type, ErrInvalidOperator)
+	}
+	return nil
+}
+
+// dateType reports whether a date function may be used with field
+func dateType(field *metadata.Field) error {
+	if field.Type != "date" && field.Type != "datetime" {
+		return fmt.Errorf("date function on %s: %w", field.Type, ErrInvalidOperator)
+	}
+	return nil
+}
+
+// unwrap returns the predicate of the SQLizers which wrap the arguments of builder methods
+func unwrap(expr interface{}) interface{} {
+	switch e := expr.(type) {
+	case baseSQLizer:
+		return unwrap(e.predicate)
+	case *whereSQLizer:
+		return unwrap(e.predicate)
+	}
+
+	return expr
+}
+
+// rawString returns the string given to a builder method
+func rawString(expr interface{}) (string, bool) {
+	str, ok := unwrap(expr).(string)
+	return str, ok
+}
+
+func containsFold(list []string, str string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, str) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package soql_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/metadata"
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/stretchr/testify/require"
+)
+
+func field(name string, fieldType string) *metadata.Field {
+	return &metadata.Field{Name: name, Type: fieldType, Filterable: true, Sortable: true, Groupable: true, Aggregatable: true}
+}
+
+func reference(name string, relationshipName string, referenceTo ...string) *metadata.Field {
+	f := field(name, "reference")
+	f.RelationshipName = relationshipName
+	f.ReferenceTo = referenceTo
+	return f
+}
+
+var description = field("Description", "textarea")
+
+func init() {
+	description.Filterable = false
+	description.Sortable = false
+	description.Groupable = false
+}
+
+var testSchema = soql.DescribeMap{
+	"Account": {
+		Name: "Account",
+		Fields: []*metadata.Field{
+			field("Id", "id"),
+			field("Name", "string"),
+			field("AnnualRevenue", "currency"),
+			field("CreatedDate", "datetime"),
+			field("Colors__c", "multipicklist"),
+			description,
+			reference("OwnerId", "Owner", "User"),
+		},
+		ChildRelationships: []*metadata.ChildRelationship{
+			{ChildSObject: "Contact", Field: "AccountId", RelationshipName: "Contacts"},
+		},
+	},
+	"Contact": {
+		Name: "Contact",
+		Fields: []*metadata.Field{
+			field("Id", "id"),
+			field("LastName", "string"),
+			reference("AccountId", "Account", "Account"),
+		},
+	},
+	"Event": {
+		Name: "Event",
+		Fields: []*metadata.Field{
+			field("Id", "id"),
+			reference("WhatId", "What", "Account", "Contact"),
+		},
+	},
+	"User": {
+		Name: "User",
+		Fields: []*metadata.Field{
+			field("Id", "id"),
+			field("Name", "string"),
+			field("IsActive", "boolean"),
+		},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for description, query := range map[string]soql.Builder{
+		"fields and relationships": soql.Select("Id", "name", "Owner.Name").From("Account"),
+		"child relationship": soql.Select("Id").
+			ChildQuery(soql.Select("LastName", "Account.Owner.Name").From("Contacts")).
+			From("Account"),
+		"where": soql.Select("Id").From("Account").Where(soql.And{
+			soql.Like{"Name": "Acme%"},
+			soql.Gt{"AnnualRevenue": 100},
+			soql.Includes{"Colors__c": []string{"Red"}},
+			soql.CalendarYear("CreatedDate").Eq(2020),
+			soql.Eq{"OwnerId": soql.Select("Id").From("User").Where(soql.Eq{"IsActive": true})},
+		}).Where("Name != null"),
+		"aggregates": soql.Select("Owner.Name").
+			Column(soql.Sum("AnnualRevenue").As("total")).
+			Column("COUNT(Id) cnt").
+			From("Account").
+			GroupBy("Owner.Name").
+			Having(soql.Sum("AnnualRevenue").Gt(10)).
+			OrderByClause(soql.Desc("Owner.Name")),
+		"typeof": soql.Select("Id").
+			Column(soql.TypeOf("What").When("Account", "Name").When("Contact", "LastName").Else("Id")).
+			From("Event"),
+	} {
+		t.Run(description, func(t *testing.T) {
+			require.Nil(t, soql.Validate(query, testSchema))
+		})
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	for description, tc := range map[string]struct {
+		query    soql.Builder
+		expected error
+	}{
+		"unknown object":             {soql.Select("Id").From("Acount"), soql.ErrUnknownObject},
+		"unknown field":              {soql.Select("Id", "Nme").From("Account"), soql.ErrUnknownField},
+		"unknown relationship":       {soql.Select("Creator.Name").From("Account"), soql.ErrUnknownRelationship},
+		"unknown relationship field": {soql.Select("Owner.Nme").From("Account"), soql.ErrUnknownField},
+		"unknown child relationship": {soql.Select("Id").ChildQuery(soql.Select("Id").From("Contact")).From("Account"), soql.ErrUnknownChildRelationship},
+		"child field":                {soql.Select("Id").ChildQuery(soql.Select("FirstName").From("Contacts")).From("Account"), soql.ErrUnknownField},
+		"not filterable":             {soql.Select("Id").From("Account").Where(soql.Eq{"Description": "a"}), soql.ErrNotFilterable},
+		"not sortable":               {soql.Select("Id").From("Account").OrderBy("Description DESC"), soql.ErrNotSortable},
+		"not groupable":              {soql.Select("Id").From("Account").GroupBy("Description"), soql.ErrNotGroupable},
+		"like on number":             {soql.Select("Id").From("Account").Where(soql.Like{"AnnualRevenue": "1%"}), soql.ErrInvalidOperator},
+		"includes on string":         {soql.Select("Id").From("Account").Where(soql.Includes{"Name": "a"}), soql.ErrInvalidOperator},
+		"date function on string":    {soql.Select("Id").From("Account").Where(soql.CalendarYear("Name").Eq(2020)), soql.ErrInvalidOperator},
+		"semi-join field":            {soql.Select("Id").From("Account").Where(soql.Eq{"OwnerId": soql.Select("Ids").From("User")}), soql.ErrUnknownField},
+		"aggregate field":            {soql.Select().Column(soql.Sum("Revenue")).From("Account"), soql.ErrUnknownField},
+		"typeof object":              {soql.Select().Column(soql.TypeOf("What").When("User", "Name")).From("Event"), soql.ErrUnknownObject},
+	} {
+		t.Run(description, func(t *testing.T) {
+			err := soql.Validate(tc.query, testSchema)
+			require.NotNil(t, err)
+
+			var errs soql.ValidationErrors
+			require.True(t, errors.As(err, &errs))
+			require.Len(t, errs, 1)
+			require.True(t, errors.Is(errs[0], tc.expected), errs[0].Error())
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	err := soql.Validate(
+		soql.Select("Id", "Nme").From("Account").Where(soql.Like{"AnnualRevenue": "1%"}).OrderBy("Description"),
+		testSchema,
+	)
+
+	require.Equal(t, "SELECT Account.Nme: unknown field; "+
+		"WHERE Account.AnnualRevenue: LIKE on currency: operator is not supported by the field type; "+
+		"ORDER BY Account.Description: field is not sortable", err.Error())
+}
+
+func TestValidationErrorOmitsEmptyParts(t *testing.T) {
+	err := soql.Validate(soql.Select("Id"), testSchema)
+	require.Equal(t, "FROM: query must select FROM an object name to be validated", err.Error())
+
+	require.Equal(t, "SELECT Name: unknown field", (&soql.ValidationError{Clause: "SELECT", Field: "Name", Err: soql.ErrUnknownField}).Error())
+	require.Equal(t, "unknown field", (&soql.ValidationError{Err: soql.ErrUnknownField}).Error())
+}
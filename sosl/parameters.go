@@ -0,0 +1,42 @@
+package sosl
+
+// Parameters is the body of a request to the parameterizedSearch endpoint, an alternative to a
+// SOSL query string. Reserved characters within Q should be escaped with EscapeTerm.
+//
+// https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/resources_search_parameterized.htm
+type Parameters struct {
+	// Q is the search string
+	Q string `json:"q"`
+	// In is the scope of fields to search: ALL, NAME, EMAIL, PHONE, or SIDEBAR
+	In string `json:"in,omitempty"`
+	// Fields are returned for every object which doesn't specify its own
+	Fields []string `json:"fields,omitempty"`
+	// SObjects are the objects to return along with their fields and filters
+	SObjects []*SObjectParameters `json:"sobjects,omitempty"`
+	// OverallLimit is the maximum number of results across all objects
+	OverallLimit int `json:"overallLimit,omitempty"`
+	// DefaultLimit is the maximum number of results per object
+	DefaultLimit int `json:"defaultLimit,omitempty"`
+	// NetworkIDs limits results to the given Experience Cloud sites
+	NetworkIDs []string `json:"netWorkIds,omitempty"`
+	// PricebookID limits Product2 results to those within the given price book
+	PricebookID string `json:"pricebookId,omitempty"`
+	// Snippet returns snippets of matching articles
+	Snippet *SnippetParameters `json:"snippet,omitempty"`
+	// SpellCorrection defaults to true
+	SpellCorrection *bool `json:"spellCorrection,omitempty"`
+}
+
+// SObjectParameters are the fields and filters of a single object of a parameterized search
+type SObjectParameters struct {
+	Name    string   `json:"name"`
+	Fields  []string `json:"fields,omitempty"`
+	Where   string   `json:"where,omitempty"`
+	OrderBy string   `json:"orderBy,omitempty"`
+	Limit   int      `json:"limit,omitempty"`
+}
+
+// SnippetParameters ...
+type SnippetParameters struct {
+	TargetLength int `json:"targetLength,omitempty"`
+}
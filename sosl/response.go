@@ -0,0 +1,110 @@
+package sosl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/types"
+)
+
+// Response is the result of a request to the search or parameterizedSearch endpoints. The
+// records of every object are returned within a single list which Decode splits into
+// per-object slices:
+//
+//	var accounts []*accounts.Account
+//	var contacts []*contacts.Contact
+//	err := response.DecodeAll(map[string]interface{}{"Account": &accounts, "Contact": &contacts})
+type Response struct {
+	SearchRecords []json.RawMessage `json:"searchRecords"`
+}
+
+// record is used to read the object type of a search record
+type record struct {
+	Attributes types.Attributes `json:"attributes"`
+}
+
+// Types returns the object types within the response in order of first appearance
+func (r *Response) Types() ([]string, error) {
+	var objectTypes []string
+	seen := map[string]bool{}
+	for i, raw := range r.SearchRecords {
+		objectType, err := recordType(raw)
+		if err != nil {
+			return nil, fmt.Errorf("searchRecords[%d]: %w", i, err)
+		}
+
+		if !seen[objectType] {
+			seen[objectType] = true
+			objectTypes = append(objectTypes, objectType)
+		}
+	}
+
+	return objectTypes, nil
+}
+
+// Records returns the raw records of objectType, matched case insensitively
+func (r *Response) Records(objectType string) ([]json.RawMessage, error) {
+	var records []json.RawMessage
+	for i, raw := range r.SearchRecords {
+		recordType, err := recordType(raw)
+		if err != nil {
+			return nil, fmt.Errorf("searchRecords[%d]: %w", i, err)
+		}
+
+		if strings.EqualFold(recordType, objectType) {
+			records = append(records, raw)
+		}
+	}
+
+	return records, nil
+}
+
+// Decode unmarshals the records of objectType into dst, a pointer to a slice
+func (r *Response) Decode(objectType string, dst interface{}) error {
+	records, err := r.Records(objectType)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	// an object without results decodes into an empty rather than nil slice
+	if records == nil {
+		contents = []byte("[]")
+	}
+
+	if err := json.Unmarshal(contents, dst); err != nil {
+		return fmt.Errorf("%s: %w", objectType, err)
+	}
+
+	return nil
+}
+
+// DecodeAll calls Decode for each object type and destination pair of dsts
+func (r *Response) DecodeAll(dsts map[string]interface{}) error {
+	for objectType, dst := range dsts {
+		if err := r.Decode(objectType, dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordType reads the attributes.type of a search record
+func recordType(raw json.RawMessage) (string, error) {
+	var r record
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	if r.Attributes.Type == "" {
+		return "", fmt.Errorf("search record has no attributes.type")
+	}
+
+	return r.Attributes.Type, nil
+}
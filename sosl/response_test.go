@@ -0,0 +1,61 @@
+package sosl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/sosl"
+	"github.com/stretchr/testify/require"
+)
+
+const searchResponse = `{
+	"searchRecords": [
+		{"attributes": {"type": "Account", "url": "/services/data/v50.0/sobjects/Account/001"}, "Id": "001", "Name": "Acme"},
+		{"attributes": {"type": "Contact", "url": "/services/data/v50.0/sobjects/Contact/003"}, "Id": "003", "LastName": "Smith"},
+		{"attributes": {"type": "Account", "url": "/services/data/v50.0/sobjects/Account/002"}, "Id": "002", "Name": "Acme Corp"}
+	]
+}`
+
+type searchAccount struct {
+	ID   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+type searchContact struct {
+	ID       string `json:"Id"`
+	LastName string `json:"LastName"`
+}
+
+func TestResponse(t *testing.T) {
+	var response sosl.Response
+	require.Nil(t, json.Unmarshal([]byte(searchResponse), &response))
+
+	objectTypes, err := response.Types()
+	require.Nil(t, err)
+	require.Equal(t, []string{"Account", "Contact"}, objectTypes)
+
+	var accounts []*searchAccount
+	var contacts []searchContact
+	var leads []*searchAccount
+	require.Nil(t, response.DecodeAll(map[string]interface{}{
+		"Account": &accounts,
+		"contact": &contacts,
+		"Lead":    &leads,
+	}))
+
+	require.Len(t, accounts, 2)
+	require.Equal(t, "Acme Corp", accounts[1].Name)
+	require.Equal(t, []searchContact{{"003", "Smith"}}, contacts)
+	require.NotNil(t, leads)
+	require.Len(t, leads, 0)
+}
+
+func TestResponseWithoutType(t *testing.T) {
+	response := sosl.Response{SearchRecords: []json.RawMessage{json.RawMessage(`{"Id": "001"}`)}}
+
+	_, err := response.Types()
+	require.NotNil(t, err)
+
+	var accounts []*searchAccount
+	require.NotNil(t, response.Decode("Account", &accounts))
+}
@@ -0,0 +1,136 @@
+package sosl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+)
+
+// ObjectSpec is a single object of a RETURNING clause along with the fields returned and the
+// filters applied to it. Filters use the expressions of the soql package:
+//
+//	sosl.Object("Account").Fields("Id", "Name").Where(soql.Eq{"Type": "Customer"}).Limit(5)
+//	=> Account(Id, Name WHERE Type = 'Customer' LIMIT 5)
+type ObjectSpec struct {
+	name     string
+	fields   []string
+	where    []soql.SQLizer
+	orderBys []string
+	limit    int
+	offset   int
+}
+
+// Object returns an ObjectSpec for the given object name i.e. Account
+func Object(name string) ObjectSpec {
+	return ObjectSpec{name: name}
+}
+
+// Fields sets the fields returned for the object, only Id is returned by default
+func (o ObjectSpec) Fields(fields ...string) ObjectSpec {
+	o.fields = append(append([]string{}, o.fields...), fields...)
+	return o
+}
+
+// Where filters the records returned for the object. The predicate may be a string, a
+// map[string]interface{}, or a soql.SQLizer such as soql.Eq. Multiple predicates are joined
+// by AND.
+func (o ObjectSpec) Where(predicate interface{}) ObjectSpec {
+	var part soql.SQLizer
+	switch p := predicate.(type) {
+	case soql.SQLizer:
+		part = p
+	case map[string]interface{}:
+		part = soql.Eq(p)
+	case string:
+		part = soql.Expr(p)
+	default:
+		part = invalidPredicate{predicate}
+	}
+
+	o.where = append(append([]soql.SQLizer{}, o.where...), part)
+	return o
+}
+
+// OrderBy orders the records returned for the object i.e. "Name DESC NULLS LAST"
+func (o ObjectSpec) OrderBy(orderBys ...string) ObjectSpec {
+	o.orderBys = append(append([]string{}, o.orderBys...), orderBys...)
+	return o
+}
+
+// Limit sets the maximum number of records returned for the object
+func (o ObjectSpec) Limit(limit int) ObjectSpec {
+	o.limit = limit
+	return o
+}
+
+// Offset skips the first offset records of the object
+func (o ObjectSpec) Offset(offset int) ObjectSpec {
+	o.offset = offset
+	return o
+}
+
+// ToSQL ...
+func (o ObjectSpec) ToSQL() (string, error) {
+	if o.name == "" {
+		return "", errors.New("RETURNING requires an object name")
+	}
+
+	var clauses []string
+	if len(o.where) > 0 {
+		where := o.where[0]
+		if len(o.where) > 1 {
+			where = soql.And(o.where)
+		}
+
+		sql, err := where.ToSQL()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", o.name, err)
+		}
+
+		clauses = append(clauses, "WHERE "+sql)
+	}
+
+	if len(o.orderBys) > 0 {
+		clauses = append(clauses, "ORDER BY "+strings.Join(o.orderBys, ", "))
+	}
+
+	if o.limit > 0 {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", o.limit))
+	}
+
+	if o.offset > 0 {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", o.offset))
+	}
+
+	if len(clauses) > 0 && len(o.fields) == 0 {
+		return "", fmt.Errorf("%s: RETURNING clauses such as WHERE and LIMIT require at least one field", o.name)
+	}
+
+	if len(o.fields) == 0 {
+		return o.name, nil
+	}
+
+	spec := strings.Join(o.fields, ", ")
+	if len(clauses) > 0 {
+		spec += " " + strings.Join(clauses, " ")
+	}
+
+	return fmt.Sprintf("%s(%s)", o.name, spec), nil
+}
+
+// invalidPredicate reports an unsupported predicate type once the query is rendered
+type invalidPredicate struct {
+	predicate interface{}
+}
+
+// ToSQL ...
+func (p invalidPredicate) ToSQL() (string, error) {
+	return "", fmt.Errorf("expected string-keyed map, SQLizer, or string, not %T", p.predicate)
+}
+
+// quote escapes and single quotes the given string
+func quote(str string) string {
+	return "'" + soql.EscapeString(str) + "'"
+}
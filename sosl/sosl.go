@@ -0,0 +1,202 @@
+// Package sosl composes SOSL (Salesforce Object Search Language) queries for the search endpoint
+package sosl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// https://developer.salesforce.com/docs/atlas.en-us.soql_sosl.meta/soql_sosl/sforce_api_calls_sosl_syntax.htm
+
+// Builder is used to compose SOSL queries
+type Builder builder.Builder
+
+// SearchGroup is the scope of fields searched by the IN clause
+type SearchGroup string
+
+const (
+	// AllFields searches all searchable fields, the default
+	AllFields SearchGroup = "ALL FIELDS"
+	// NameFields searches name fields
+	NameFields SearchGroup = "NAME FIELDS"
+	// EmailFields searches email fields
+	EmailFields SearchGroup = "EMAIL FIELDS"
+	// PhoneFields searches phone fields
+	PhoneFields SearchGroup = "PHONE FIELDS"
+	// SidebarFields searches the fields used by the sidebar search in the Salesforce UI
+	SidebarFields SearchGroup = "SIDEBAR FIELDS"
+)
+
+// searchData is a data structure holding the individual components of a SOSL query
+type searchData struct {
+	Find        string
+	In          SearchGroup
+	Returning   []ObjectSpec
+	Snippet     bool
+	SnippetSize int
+	Networks    []string
+	PricebookID string
+	Limit       string
+}
+
+// init is necessary for preparing data structures used by builder.Builder
+func init() {
+	builder.Register(Builder{}, searchData{})
+}
+
+// reservedEscaper escapes the reserved characters of a SOSL search term
+var reservedEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`?`, `\?`,
+	`&`, `\&`,
+	`|`, `\|`,
+	`!`, `\!`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`^`, `\^`,
+	`~`, `\~`,
+	`*`, `\*`,
+	`:`, `\:`,
+	`"`, `\"`,
+	`'`, `\'`,
+	`+`, `\+`,
+	`-`, `\-`,
+)
+
+// EscapeTerm escapes the reserved characters of a search term so that it is matched literally.
+// Use it to include user input within an expression given to FindExpr.
+func EscapeTerm(term string) string {
+	return reservedEscaper.Replace(term)
+}
+
+// Find returns a Builder searching for term. Reserved characters within term are escaped, use
+// FindExpr for wildcards, phrases, and logical operators.
+func Find(term string) Builder {
+	return FindExpr(EscapeTerm(term))
+}
+
+// FindExpr returns a Builder searching for a search expression such as `acme* AND "san francisco"`.
+// The expression is used as-is so any user input within it must be escaped with EscapeTerm.
+func FindExpr(expression string) Builder {
+	return builder.Set(Builder(builder.EmptyBuilder), "Find", expression).(Builder)
+}
+
+// In sets the fields to search i.e. sosl.NameFields
+func (b Builder) In(group SearchGroup) Builder {
+	return builder.Set(b, "In", group).(Builder)
+}
+
+// Returning adds objects, and optionally the fields and filters of each, to the results
+func (b Builder) Returning(objects ...ObjectSpec) Builder {
+	for _, object := range objects {
+		b = builder.Append(b, "Returning", object).(Builder)
+	}
+
+	return b
+}
+
+// WithSnippet returns a snippet of each matching article highlighting the search term. A
+// targetLength of 0 uses the default snippet length.
+func (b Builder) WithSnippet(targetLength int) Builder {
+	return builder.Set(builder.Set(b, "Snippet", true), "SnippetSize", targetLength).(Builder)
+}
+
+// WithNetwork limits results to the given Experience Cloud sites. The internal org has the
+// network id 000000000000000.
+func (b Builder) WithNetwork(networkIDs ...string) Builder {
+	return builder.Set(b, "Networks", networkIDs).(Builder)
+}
+
+// WithPricebookID limits Product2 results to those within the given price book
+func (b Builder) WithPricebookID(pricebookID string) Builder {
+	return builder.Set(b, "PricebookID", pricebookID).(Builder)
+}
+
+// Limit sets the maximum number of rows returned across all objects
+func (b Builder) Limit(limit int) Builder {
+	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(Builder)
+}
+
+// ToSQL marshalls the searchData builder into a SOSL string. The method is named for
+// compatibility with requests.Builder.SQLizer.
+func (b Builder) ToSQL() (string, error) {
+	data := builder.GetStruct(b).(searchData)
+	return data.toSQL()
+}
+
+// MustSQL calls ToSQL and panics instead of returning an error
+func (b Builder) MustSQL() string {
+	sql, err := b.ToSQL()
+	if err != nil {
+		panic(err)
+	}
+
+	return sql
+}
+
+// toSQL composes the properties of searchData into a SOSL query string
+func (d *searchData) toSQL() (string, error) {
+	if strings.TrimSpace(d.Find) == "" {
+		return "", errors.New("search statements must have a search term")
+	}
+
+	sql := &bytes.Buffer{}
+	sql.WriteString("FIND {")
+	sql.WriteString(d.Find)
+	sql.WriteString("}")
+
+	if d.In != "" {
+		sql.WriteString(" IN ")
+		sql.WriteString(string(d.In))
+	}
+
+	if len(d.Returning) > 0 {
+		parts := make([]string, 0, len(d.Returning))
+		for _, object := range d.Returning {
+			part, err := object.ToSQL()
+			if err != nil {
+				return "", fmt.Errorf("%w", err)
+			}
+			parts = append(parts, part)
+		}
+
+		sql.WriteString(" RETURNING ")
+		sql.WriteString(strings.Join(parts, ", "))
+	}
+
+	if d.Snippet {
+		sql.WriteString(" WITH SNIPPET")
+		if d.SnippetSize > 0 {
+			sql.WriteString(fmt.Sprintf("(target_length=%d)", d.SnippetSize))
+		}
+	}
+
+	if len(d.Networks) == 1 {
+		sql.WriteString(fmt.Sprintf(" WITH NETWORK = %s", quote(d.Networks[0])))
+	} else if len(d.Networks) > 1 {
+		networks := make([]string, 0, len(d.Networks))
+		for _, network := range d.Networks {
+			networks = append(networks, quote(network))
+		}
+		sql.WriteString(fmt.Sprintf(" WITH NETWORK IN (%s)", strings.Join(networks, ", ")))
+	}
+
+	if d.PricebookID != "" {
+		sql.WriteString(fmt.Sprintf(" WITH PRICEBOOKID = %s", quote(d.PricebookID)))
+	}
+
+	if len(d.Limit) > 0 {
+		sql.WriteString(" LIMIT ")
+		sql.WriteString(d.Limit)
+	}
+
+	return sql.String(), nil
+}
@@ -0,0 +1,85 @@
+package sosl_test
+
+import (
+	"testing"
+
+	"github.com/beeekind/go-salesforce-sdk/soql"
+	"github.com/beeekind/go-salesforce-sdk/sosl"
+	"github.com/stretchr/testify/require"
+)
+
+var searchTests = map[string]struct {
+	builder  sosl.Builder
+	expected string
+}{
+	"find": {
+		sosl.Find("acme"),
+		"FIND {acme}",
+	},
+	"find escapes reserved characters": {
+		sosl.Find(`a&b} RETURNING User(Password) {"c"*`),
+		`FIND {a\&b\} RETURNING User\(Password\) \{\"c\"\*}`,
+	},
+	"find expression": {
+		sosl.FindExpr(`acme* AND "` + sosl.EscapeTerm("san-francisco") + `"`),
+		`FIND {acme* AND "san\-francisco"}`,
+	},
+	"returning": {
+		sosl.Find("acme").
+			In(sosl.AllFields).
+			Returning(
+				sosl.Object("Account").
+					Fields("Id", "Name").
+					Where(soql.Like{"Name": "Acme%"}).
+					Where(soql.Eq{"Type": "Customer"}).
+					OrderBy("Name DESC").
+					Limit(5).
+					Offset(10),
+				sosl.Object("Contact").Fields("Id").Where(soql.Eq{"IsDeleted": false}),
+				sosl.Object("Lead"),
+			).
+			Limit(20),
+		"FIND {acme} IN ALL FIELDS RETURNING Account(Id, Name WHERE (Name LIKE 'Acme%' AND Type = 'Customer') ORDER BY Name DESC LIMIT 5 OFFSET 10), Contact(Id WHERE IsDeleted = false), Lead LIMIT 20",
+	},
+	"with clauses": {
+		sosl.Find("phone").
+			In(sosl.NameFields).
+			Returning(sosl.Object("KnowledgeArticleVersion").Fields("Id", "Title")).
+			WithSnippet(120).
+			WithNetwork("0DB000000000001", "0DB000000000002").
+			WithPricebookID("01s000000000001"),
+		"FIND {phone} IN NAME FIELDS RETURNING KnowledgeArticleVersion(Id, Title) WITH SNIPPET(target_length=120) WITH NETWORK IN ('0DB000000000001', '0DB000000000002') WITH PRICEBOOKID = '01s000000000001'",
+	},
+	"search group from a string": {
+		sosl.Find("555").In(sosl.SearchGroup("PHONE FIELDS")),
+		"FIND {555} IN PHONE FIELDS",
+	},
+	"single network and default snippet": {
+		sosl.Find("phone").WithSnippet(0).WithNetwork("0DB000000000001"),
+		"FIND {phone} WITH SNIPPET WITH NETWORK = '0DB000000000001'",
+	},
+}
+
+func TestSearch(t *testing.T) {
+	for description, tc := range searchTests {
+		t.Run(description, func(t *testing.T) {
+			sql, err := tc.builder.ToSQL()
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, sql)
+		})
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	for description, b := range map[string]sosl.Builder{
+		"empty term":            sosl.Find(""),
+		"object without name":   sosl.Find("a").Returning(sosl.Object("")),
+		"where without fields":  sosl.Find("a").Returning(sosl.Object("Account").Limit(1)),
+		"unsupported predicate": sosl.Find("a").Returning(sosl.Object("Account").Fields("Id").Where(1)),
+	} {
+		t.Run(description, func(t *testing.T) {
+			_, err := b.ToSQL()
+			require.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// multiPicklistSeparator separates the selected values of a multi-select picklist
+const multiPicklistSeparator = ";"
+
+// MultiPicklist represents the selected values of a multi-select picklist field. Salesforce
+// serializes these values as a single semicolon delimited string i.e. "Red;Green"
+type MultiPicklist []string
+
+// ParseMultiPicklist splits a semicolon delimited string into a MultiPicklist
+func ParseMultiPicklist(str string) MultiPicklist {
+	if str == "" {
+		return MultiPicklist{}
+	}
+
+	return MultiPicklist(strings.Split(str, multiPicklistSeparator))
+}
+
+// MarshalJSON ...
+func (m MultiPicklist) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON ...
+func (m *MultiPicklist) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, nullBytes) {
+		*m = nil
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("UnmarshalJSON for MultiPicklist: %w", err)
+	}
+
+	*m = ParseMultiPicklist(str)
+	return nil
+}
+
+// MarshalText ...
+func (m MultiPicklist) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText ...
+func (m *MultiPicklist) UnmarshalText(data []byte) error {
+	*m = ParseMultiPicklist(string(data))
+	return nil
+}
+
+// String returns the semicolon delimited form of the selected values
+func (m MultiPicklist) String() string {
+	return strings.Join(m, multiPicklistSeparator)
+}
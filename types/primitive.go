@@ -16,6 +16,15 @@ type NullableBool struct {
 	Value      bool
 }
 
+// NewBool returns a new instance of NullableBool
+func NewBool(val bool) NullableBool {
+	return NullableBool{
+		IsNull:     false,
+		IsHydrated: true,
+		Value:      val,
+	}
+}
+
 // MarshalJSON ...
 func (b *NullableBool) MarshalJSON() ([]byte, error) {
 	if b.IsNull {
@@ -83,8 +92,9 @@ type NullableString struct {
 // NewString returns a new instance of NullableString
 func NewString(str string) NullableString {
 	return NullableString{
-		IsNull: false,
-		Value:  str,
+		IsNull:     false,
+		IsHydrated: true,
+		Value:      str,
 	}
 }
 
@@ -154,8 +164,9 @@ type NullableInt struct {
 // NewInt ...
 func NewInt(val int) NullableInt {
 	return NullableInt{
-		IsNull: false,
-		Value:  val,
+		IsNull:     false,
+		IsHydrated: true,
+		Value:      val,
 	}
 }
 
@@ -228,8 +239,9 @@ type NullableInt64 struct {
 // NewInt64 ...
 func NewInt64(val int64) NullableInt64 {
 	return NullableInt64{
-		IsNull: false,
-		Value:  val,
+		IsNull:     false,
+		IsHydrated: true,
+		Value:      val,
 	}
 }
 
@@ -302,8 +314,9 @@ type NullableFloat64 struct {
 // NewFloat64 ...
 func NewFloat64(val float64) NullableFloat64 {
 	return NullableFloat64{
-		IsNull: false,
-		Value:  val,
+		IsNull:     false,
+		IsHydrated: true,
+		Value:      val,
 	}
 }
 